@@ -0,0 +1,132 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/hamba/avro"
+	"go.uber.org/zap"
+)
+
+// insertRecordsEndpoint - the endpoint of the binary record inserts
+const insertRecordsEndpoint = "/insert/records"
+
+// avroWriters - the writers the insert requests and their rows are encoded with, pooled
+// so a request reuses the buffer of an earlier one
+var avroWriters = sync.Pool{
+	New: func() any {
+		return avro.NewWriter(nil, 64*1024)
+	},
+}
+
+// getAvroWriter - an empty pooled writer
+//
+//	@return *avro.Writer
+func getAvroWriter() *avro.Writer {
+	w := avroWriters.Get().(*avro.Writer)
+	w.Error = nil
+	w.Reset(nil)
+	return w
+}
+
+// avroInsertOptions - the options of the insert_records request, the ones gpudb-api-go
+// sends for the insert options
+//
+//	@receiver kiwriter
+//	@param tableName
+//	@return map[string]string
+func (kiwriter *KiWriter) avroInsertOptions(tableName string) map[string]string {
+	options := kiwriter.insertOptions(tableName)
+	return map[string]string{
+		"update_on_existing_pk":    strconv.FormatBool(options.UpdateOnExistingPk),
+		"ignore_existing_pk":       strconv.FormatBool(options.IgnoreExistingPk),
+		"return_record_ids":        strconv.FormatBool(options.ReturnRecordIDs),
+		"truncate_strings":         strconv.FormatBool(options.TruncateStrings),
+		"return_individual_errors": strconv.FormatBool(options.ReturnIndividualErrors),
+		"allow_partial_batch":      strconv.FormatBool(options.AllowPartialBatch),
+	}
+}
+
+// encodeInsertRecords appends an insert_records request of the rows [from, to) to request.
+// Every row is encoded into the row writer with the type schema of the table and added to
+// the request as bytes, the layout InsertRecordsRaw sends.
+//
+//	@param request
+//	@param row
+//	@param finalTable
+//	@param schema
+//	@param rows
+//	@param from
+//	@param to
+//	@param options
+//	@return error
+func encodeInsertRecords(request *avro.Writer, row *avro.Writer, finalTable string, schema avro.Schema, rows rowBuffer, from int, to int, options map[string]string) error {
+	request.WriteString(finalTable)
+
+	if to > from {
+		request.WriteBlockHeader(int64(to-from), 0)
+		for i := from; i < to; i++ {
+			row.Reset(nil)
+			rows.encode(row, schema, i)
+			if row.Error != nil {
+				return fmt.Errorf("cannot encode a row of %s: %w", finalTable, row.Error)
+			}
+			request.WriteBytes(row.Buffer())
+		}
+	}
+	request.WriteBlockHeader(0, 0)
+
+	// list_str, only used with the JSON list encoding
+	request.WriteBlockHeader(0, 0)
+	request.WriteString("binary")
+
+	if len(options) > 0 {
+		request.WriteBlockHeader(int64(len(options)), 0)
+		for key, value := range options {
+			request.WriteString(key)
+			request.WriteString(value)
+		}
+	}
+	request.WriteBlockHeader(0, 0)
+	return request.Error
+}
+
+// insertRecordsAvro - inserts the rows [from, to) through /insert/records, encoding them
+// with the cached type schema of the table. The schema is dropped from the cache when
+// Kinetica rejects the insert, the table may have been recreated with another type.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param rows
+//	@param from
+//	@param to
+//	@return int64
+//	@return error
+func (kiwriter *KiWriter) insertRecordsAvro(ctx context.Context, rows rowBuffer, from int, to int) (int64, error) {
+	finalTable := kiwriter.finalTableName(rows.tableName())
+	recordSchema, err := kiwriter.recordSchema(ctx, finalTable)
+	if err != nil {
+		return 0, err
+	}
+
+	request := getAvroWriter()
+	row := getAvroWriter()
+	defer avroWriters.Put(request)
+	defer avroWriters.Put(row)
+
+	if err := encodeInsertRecords(request, row, finalTable, recordSchema, rows, from, to, kiwriter.avroInsertOptions(rows.tableName())); err != nil {
+		return 0, err
+	}
+
+	body := request.Buffer()
+	if err := kiwriter.rest.postAvro(ctx, insertRecordsEndpoint, body); err != nil {
+		if !isRetryableInsertError(err) {
+			kiwriter.recordSchemas.Delete(finalTable)
+			kiwriter.logger.Debug("Dropped the cached type schema", zap.String("Table", finalTable), zap.Error(err))
+		}
+		return 0, err
+	}
+	return int64(len(body)), nil
+}
@@ -0,0 +1,620 @@
+package kineticaotelexporter
+
+import (
+	"reflect"
+
+	"github.com/hamba/avro"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// rowBuffer - the rows of one table converted from a push. The rows are Avro encoded
+// one by one for the /insert/records endpoint, or boxed as records for the insert paths
+// working on records.
+type rowBuffer interface {
+	// tableName - the table the rows are written to, without schema and prefix
+	tableName() string
+	// count - the number of rows
+	count() int
+	// size - the estimated encoded size of row i, bytes the one of all the rows
+	size(i int) int
+	bytes() int
+	// encode appends the Avro encoding of row i to w
+	encode(w *avro.Writer, schema avro.Schema, i int)
+	// records - the rows [from, to) as records
+	records(from int, to int) []any
+	// mark remembers the number of rows, rollback drops the rows added since
+	mark()
+	rollback()
+	// discard drops the first n rows, the ones sent while the push is converted
+	discard(n int)
+	// reset drops every row, keeping the memory for the next push
+	reset()
+}
+
+// tableRows - the typed rows of a table. The backing array is kept when the rows are
+// reset, so a pooled batch converts the next push without allocating rows.
+type tableRows[T any] struct {
+	table  string
+	rows   []T
+	sizes  []int
+	total  int
+	marked int
+	// record - the record inserted for a row when it is not the row itself
+	record func(row *T) any
+}
+
+// add appends a row, the rows of the exporter's tables compute their size from their
+// fields and only boxed records are sized by reflection
+//
+//	@receiver t
+//	@param row
+func (t *tableRows[T]) add(row T) {
+	t.rows = append(t.rows, row)
+
+	var size int
+	if sizer, ok := any(&t.rows[len(t.rows)-1]).(encodedSizer); ok {
+		size = sizer.encodedSize()
+	} else {
+		v := reflect.ValueOf(&t.rows[len(t.rows)-1]).Elem()
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		size = estimateValueSize(v)
+	}
+	t.sizes = append(t.sizes, size)
+	t.total += size
+}
+
+// tableName
+//
+//	@receiver t
+//	@return string
+func (t *tableRows[T]) tableName() string {
+	return t.table
+}
+
+// count
+//
+//	@receiver t
+//	@return int
+func (t *tableRows[T]) count() int {
+	return len(t.rows)
+}
+
+// size
+//
+//	@receiver t
+//	@param i
+//	@return int
+func (t *tableRows[T]) size(i int) int {
+	return t.sizes[i]
+}
+
+// bytes
+//
+//	@receiver t
+//	@return int
+func (t *tableRows[T]) bytes() int {
+	return t.total
+}
+
+// encode appends the Avro encoding of row i to w, the row is passed by pointer so it is
+// not copied to an interface
+//
+//	@receiver t
+//	@param w
+//	@param schema
+//	@param i
+func (t *tableRows[T]) encode(w *avro.Writer, schema avro.Schema, i int) {
+	if t.record != nil {
+		w.WriteVal(schema, t.record(&t.rows[i]))
+		return
+	}
+	w.WriteVal(schema, &t.rows[i])
+}
+
+// records - the rows [from, to) boxed as records, copies which stay valid when the rows are reset
+//
+//	@receiver t
+//	@param from
+//	@param to
+//	@return []any
+func (t *tableRows[T]) records(from int, to int) []any {
+	if to <= from {
+		return nil
+	}
+	records := make([]any, 0, to-from)
+	for i := from; i < to; i++ {
+		if t.record != nil {
+			records = append(records, t.record(&t.rows[i]))
+		} else {
+			records = append(records, t.rows[i])
+		}
+	}
+	return records
+}
+
+// mark
+//
+//	@receiver t
+func (t *tableRows[T]) mark() {
+	t.marked = len(t.rows)
+}
+
+// rollback
+//
+//	@receiver t
+func (t *tableRows[T]) rollback() {
+	t.truncate(t.marked)
+}
+
+// reset
+//
+//	@receiver t
+func (t *tableRows[T]) reset() {
+	t.truncate(0)
+	t.marked = 0
+}
+
+// discard moves the rows from n on to the front
+//
+//	@receiver t
+//	@param n
+func (t *tableRows[T]) discard(n int) {
+	for i := 0; i < n; i++ {
+		t.total -= t.sizes[i]
+	}
+	kept := copy(t.rows, t.rows[n:])
+	copy(t.sizes, t.sizes[n:])
+
+	var zero T
+	for i := kept; i < len(t.rows); i++ {
+		t.rows[i] = zero
+	}
+	t.rows = t.rows[:kept]
+	t.sizes = t.sizes[:kept]
+	t.marked = 0
+}
+
+// truncate drops the rows from n on, zeroing them so the pooled rows do not keep the
+// strings and byte slices of an earlier push alive
+//
+//	@receiver t
+//	@param n
+func (t *tableRows[T]) truncate(n int) {
+	var zero T
+	for i := n; i < len(t.rows); i++ {
+		t.rows[i] = zero
+		t.total -= t.sizes[i]
+	}
+	t.rows = t.rows[:n]
+	t.sizes = t.sizes[:n]
+}
+
+// boxedRecord - the record of a row which already is a record
+//
+//	@param row
+//	@return any
+func boxedRecord(row *any) any {
+	return *row
+}
+
+// markRows remembers the number of rows of every table
+//
+//	@param tables
+func markRows(tables []rowBuffer) {
+	for _, rows := range tables {
+		rows.mark()
+	}
+}
+
+// rollbackRows drops the rows added to every table since markRows
+//
+//	@param tables
+func rollbackRows(tables []rowBuffer) {
+	for _, rows := range tables {
+		rows.rollback()
+	}
+}
+
+// resetRows drops the rows of every table
+//
+//	@param tables
+func resetRows(tables []rowBuffer) {
+	for _, rows := range tables {
+		rows.reset()
+	}
+}
+
+// rowCount - the number of rows of all the tables
+//
+//	@param tables
+//	@return int
+func rowCount(tables []rowBuffer) int {
+	count := 0
+	for _, rows := range tables {
+		count += rows.count()
+	}
+	return count
+}
+
+// convertedAttribute - an attribute converted to the attribute value columns
+type convertedAttribute struct {
+	key   string
+	value AttributeValue
+}
+
+// attributeConverter - converts attribute maps to the attribute value columns, counting
+// the attributes dropped for an empty key or a value that cannot be converted
+type attributeConverter struct {
+	logger    *zap.Logger
+	telemetry *exporterTelemetry
+}
+
+// convert appends the attributes that can be stored to dst. The number of dropped
+// attributes is returned with the conversion errors.
+//
+//	@receiver c
+//	@param dst
+//	@param attributes
+//	@param kind
+//	@param skip
+//	@return []convertedAttribute
+//	@return int
+//	@return error
+func (c attributeConverter) convert(dst []convertedAttribute, attributes pcommon.Map, kind string, skip func(key string) bool) ([]convertedAttribute, int, error) {
+	var errs error
+	dropped := 0
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		if k == "" {
+			dropped++
+			c.telemetry.recordDroppedAttribute(DropReasonEmptyKey)
+			c.logger.Debug("attribute key is empty", zap.String("Kind", kind))
+		} else if skip != nil && skip(k) {
+			c.logger.Debug("Dropping attribute", zap.String("Kind", kind), zap.String("Key", k))
+		} else if value, ok, err := attributeValue(v); err != nil {
+			dropped++
+			c.telemetry.recordDroppedAttribute(DropReasonConversionError)
+			c.logger.Debug("invalid attribute value", zap.String("Kind", kind), zap.String("Error", err.Error()))
+			errs = multierr.Append(errs, err)
+		} else if ok {
+			dst = append(dst, convertedAttribute{k, value})
+		} else {
+			c.logger.Debug("attribute value is empty", zap.String("Kind", kind), zap.String("Key", k))
+		}
+		return true
+	})
+	return dst, dropped, errs
+}
+
+// sharedAttributes - the resource and scope attributes of the records of a scope,
+// converted once for all of them
+type sharedAttributes struct {
+	resource        []convertedAttribute
+	resourceDropped int
+	resourceErr     error
+
+	scope        []convertedAttribute
+	scopeDropped int
+	scopeErr     error
+}
+
+// setResource converts the attributes of a resource
+//
+//	@receiver s
+//	@param c
+//	@param resource
+func (s *sharedAttributes) setResource(c attributeConverter, resource pcommon.Resource) {
+	s.resource, s.resourceDropped, s.resourceErr = c.convert(s.resource[:0], resource.Attributes(), "resource", nil)
+	s.resourceDropped += int(resource.DroppedAttributesCount())
+}
+
+// setScope converts the attributes of a scope
+//
+//	@receiver s
+//	@param c
+//	@param scope
+func (s *sharedAttributes) setScope(c attributeConverter, scope pcommon.InstrumentationScope) {
+	s.scope, s.scopeDropped, s.scopeErr = c.convert(s.scope[:0], scope.Attributes(), "scope", nil)
+	s.scopeDropped += int(scope.DroppedAttributesCount())
+}
+
+// err - the conversion errors of the resource and scope attributes
+//
+//	@receiver s
+//	@return error
+func (s *sharedAttributes) err() error {
+	return multierr.Append(s.resourceErr, s.scopeErr)
+}
+
+// reset drops the converted attributes, keeping the memory
+//
+//	@receiver s
+func (s *sharedAttributes) reset() {
+	s.resource = clearAttributes(s.resource)
+	s.scope = clearAttributes(s.scope)
+	s.resourceErr, s.scopeErr = nil, nil
+}
+
+// clearAttributes - the attributes emptied, zeroed so they do not keep pdata values alive
+//
+//	@param attributes
+//	@return []convertedAttribute
+func clearAttributes(attributes []convertedAttribute) []convertedAttribute {
+	for i := range attributes {
+		attributes[i] = convertedAttribute{}
+	}
+	return attributes[:0]
+}
+
+// logBatch - the rows of the log tables converted from one push
+type logBatch struct {
+	logs               tableRows[Log]
+	logAttributes      tableRows[LogAttribute]
+	resourceAttributes tableRows[ResourceAttribute]
+	scopeAttributes    tableRows[ScopeAttribute]
+	tables             []rowBuffer
+
+	converter  attributeConverter
+	shared     sharedAttributes
+	attributes []convertedAttribute
+}
+
+// newLogBatch
+//
+//	@param logger
+//	@param telemetry
+//	@return *logBatch
+func newLogBatch(logger *zap.Logger, telemetry *exporterTelemetry) *logBatch {
+	b := &logBatch{
+		logs:               tableRows[Log]{table: LogTable},
+		logAttributes:      tableRows[LogAttribute]{table: LogAttributeTable},
+		resourceAttributes: tableRows[ResourceAttribute]{table: LogResourceAttributeTable},
+		scopeAttributes:    tableRows[ScopeAttribute]{table: LogScopeAttributeTable},
+		converter:          attributeConverter{logger, telemetry},
+	}
+	b.tables = []rowBuffer{&b.logs, &b.logAttributes, &b.resourceAttributes, &b.scopeAttributes}
+	return b
+}
+
+// reset
+//
+//	@receiver b
+func (b *logBatch) reset() {
+	resetRows(b.tables)
+	b.shared.reset()
+	b.attributes = clearAttributes(b.attributes)
+}
+
+// traceBatch - the rows of the trace tables converted from one push
+type traceBatch struct {
+	spans              tableRows[Span]
+	spanAttributes     tableRows[SpanAttribute]
+	resourceAttributes tableRows[TraceResourceAttribute]
+	scopeAttributes    tableRows[TraceScopeAttribute]
+	eventAttributes    tableRows[EventAttribute]
+	linkAttributes     tableRows[LinkAttribute]
+	tables             []rowBuffer
+
+	converter  attributeConverter
+	shared     sharedAttributes
+	attributes []convertedAttribute
+}
+
+// newTraceBatch
+//
+//	@param logger
+//	@param telemetry
+//	@return *traceBatch
+func newTraceBatch(logger *zap.Logger, telemetry *exporterTelemetry) *traceBatch {
+	b := &traceBatch{
+		spans:              tableRows[Span]{table: TraceSpanTable},
+		spanAttributes:     tableRows[SpanAttribute]{table: TraceSpanAttributeTable},
+		resourceAttributes: tableRows[TraceResourceAttribute]{table: TraceResourceAttributeTable},
+		scopeAttributes:    tableRows[TraceScopeAttribute]{table: TraceScopeAttributeTable},
+		eventAttributes:    tableRows[EventAttribute]{table: TraceEventAttributeTable},
+		linkAttributes:     tableRows[LinkAttribute]{table: TraceLinkAttributeTable},
+		converter:          attributeConverter{logger, telemetry},
+	}
+	b.tables = []rowBuffer{&b.spans, &b.spanAttributes, &b.resourceAttributes, &b.scopeAttributes, &b.eventAttributes, &b.linkAttributes}
+	return b
+}
+
+// reset
+//
+//	@receiver b
+func (b *traceBatch) reset() {
+	resetRows(b.tables)
+	b.shared.reset()
+	b.attributes = clearAttributes(b.attributes)
+}
+
+// gaugeBatch - the rows of the gauge tables
+type gaugeBatch struct {
+	gauges              tableRows[Gauge]
+	datapoints          tableRows[GaugeDatapoint]
+	datapointAttributes tableRows[GaugeDatapointAttribute]
+	resourceAttributes  tableRows[GaugeResourceAttribute]
+	scopeAttributes     tableRows[GaugeScopeAttribute]
+	exemplars           tableRows[GaugeDatapointExemplar]
+	exemplarAttributes  tableRows[GaugeDataPointExemplarAttribute]
+	tables              []rowBuffer
+}
+
+// init names the tables of the batch
+//
+//	@receiver b
+func (b *gaugeBatch) init() {
+	b.gauges.table = GaugeTable
+	b.datapoints.table = GaugeDatapointTable
+	b.datapointAttributes.table = GaugeDatapointAttributeTable
+	b.resourceAttributes.table = GaugeResourceAttributeTable
+	b.scopeAttributes.table = GaugeScopeAttributeTable
+	b.exemplars.table = GaugeDatapointExemplarTable
+	b.exemplarAttributes.table = GaugeDatapointExemplarAttributeTable
+	b.tables = []rowBuffer{&b.gauges, &b.datapoints, &b.datapointAttributes, &b.resourceAttributes, &b.scopeAttributes, &b.exemplars, &b.exemplarAttributes}
+}
+
+// sumBatch - the rows of the sum tables
+type sumBatch struct {
+	sums                tableRows[Sum]
+	datapoints          tableRows[SumDatapoint]
+	datapointAttributes tableRows[SumDataPointAttribute]
+	resourceAttributes  tableRows[SumResourceAttribute]
+	scopeAttributes     tableRows[SumScopeAttribute]
+	exemplars           tableRows[SumDatapointExemplar]
+	exemplarAttributes  tableRows[SumDataPointExemplarAttribute]
+	tables              []rowBuffer
+}
+
+// init names the tables of the batch
+//
+//	@receiver b
+func (b *sumBatch) init() {
+	b.sums.table = SumTable
+	b.datapoints.table = SumDatapointTable
+	b.datapointAttributes.table = SumDatapointAttributeTable
+	b.resourceAttributes.table = SumResourceAttributeTable
+	b.scopeAttributes.table = SumScopeAttributeTable
+	b.exemplars.table = SumDatapointExemplarTable
+	b.exemplarAttributes.table = SumDataPointExemplarAttributeTable
+	b.tables = []rowBuffer{&b.sums, &b.datapoints, &b.datapointAttributes, &b.resourceAttributes, &b.scopeAttributes, &b.exemplars, &b.exemplarAttributes}
+}
+
+// histogramBatch - the rows of the histogram tables
+type histogramBatch struct {
+	histograms          tableRows[Histogram]
+	datapoints          tableRows[HistogramDatapoint]
+	datapointAttributes tableRows[HistogramDataPointAttribute]
+	bucketCounts        tableRows[HistogramDatapointBucketCount]
+	explicitBounds      tableRows[HistogramDatapointExplicitBound]
+	resourceAttributes  tableRows[HistogramResourceAttribute]
+	scopeAttributes     tableRows[HistogramScopeAttribute]
+	exemplars           tableRows[HistogramDatapointExemplar]
+	exemplarAttributes  tableRows[HistogramDataPointExemplarAttribute]
+	tables              []rowBuffer
+}
+
+// init names the tables of the batch
+//
+//	@receiver b
+func (b *histogramBatch) init() {
+	b.histograms.table = HistogramTable
+	b.datapoints.table = HistogramDatapointTable
+	b.datapointAttributes.table = HistogramDatapointAttributeTable
+	b.bucketCounts.table = HistogramBucketCountsTable
+	b.explicitBounds.table = HistogramExplicitBoundsTable
+	b.resourceAttributes.table = HistogramResourceAttributeTable
+	b.scopeAttributes.table = HistogramScopeAttributeTable
+	b.exemplars.table = HistogramDatapointExemplarTable
+	b.exemplarAttributes.table = HistogramDataPointExemplarAttributeTable
+	b.tables = []rowBuffer{&b.histograms, &b.datapoints, &b.datapointAttributes, &b.bucketCounts, &b.explicitBounds, &b.resourceAttributes, &b.scopeAttributes, &b.exemplars, &b.exemplarAttributes}
+}
+
+// exponentialHistogramBatch - the rows of the exponential histogram tables
+type exponentialHistogramBatch struct {
+	histograms           tableRows[ExponentialHistogram]
+	datapoints           tableRows[ExponentialHistogramDatapoint]
+	datapointAttributes  tableRows[ExponentialHistogramDataPointAttribute]
+	positiveBucketCounts tableRows[ExponentialHistogramBucketPositiveCount]
+	negativeBucketCounts tableRows[ExponentialHistogramBucketNegativeCount]
+	resourceAttributes   tableRows[ExponentialHistogramResourceAttribute]
+	scopeAttributes      tableRows[ExponentialHistogramScopeAttribute]
+	exemplars            tableRows[ExponentialHistogramDatapointExemplar]
+	exemplarAttributes   tableRows[ExponentialHistogramDataPointExemplarAttribute]
+	tables               []rowBuffer
+}
+
+// init names the tables of the batch
+//
+//	@receiver b
+func (b *exponentialHistogramBatch) init() {
+	b.histograms.table = ExpHistogramTable
+	b.datapoints.table = ExpHistogramDatapointTable
+	b.datapointAttributes.table = ExpHistogramDatapointAttributeTable
+	b.positiveBucketCounts.table = ExpHistogramPositiveBucketCountsTable
+	b.negativeBucketCounts.table = ExpHistogramNegativeBucketCountsTable
+	b.resourceAttributes.table = ExpHistogramResourceAttributeTable
+	b.scopeAttributes.table = ExpHistogramScopeAttributeTable
+	b.exemplars.table = ExpHistogramDatapointExemplarTable
+	b.exemplarAttributes.table = ExpHistogramDataPointExemplarAttributeTable
+	b.tables = []rowBuffer{&b.histograms, &b.datapoints, &b.datapointAttributes, &b.positiveBucketCounts, &b.negativeBucketCounts, &b.resourceAttributes, &b.scopeAttributes, &b.exemplars, &b.exemplarAttributes}
+}
+
+// summaryBatch - the rows of the summary tables
+type summaryBatch struct {
+	summaries           tableRows[Summary]
+	datapoints          tableRows[SummaryDatapoint]
+	datapointAttributes tableRows[SummaryDataPointAttribute]
+	quantileValues      tableRows[SummaryDatapointQuantileValues]
+	resourceAttributes  tableRows[SummaryResourceAttribute]
+	scopeAttributes     tableRows[SummaryScopeAttribute]
+	tables              []rowBuffer
+}
+
+// init names the tables of the batch, the datapoints are inserted with a column per
+// quantile when quantile columns are configured
+//
+//	@receiver b
+//	@param quantileColumns
+func (b *summaryBatch) init(quantileColumns []string) {
+	b.summaries.table = SummaryTable
+	b.datapoints.table = SummaryDatapointTable
+	b.datapointAttributes.table = SummaryDatapointAttributeTable
+	b.quantileValues.table = SummaryDatapointQuantileValueTable
+	b.resourceAttributes.table = SummaryResourceAttributeTable
+	b.scopeAttributes.table = SummaryScopeAttributeTable
+	if len(quantileColumns) > 0 {
+		b.datapoints.record = func(datapoint *SummaryDatapoint) any {
+			return datapoint.quantileRecord(quantileColumns)
+		}
+	}
+	b.tables = []rowBuffer{&b.summaries, &b.datapoints, &b.datapointAttributes, &b.quantileValues, &b.resourceAttributes, &b.scopeAttributes}
+}
+
+// metricBatch - the rows of the metric tables converted from one push, per metric type
+type metricBatch struct {
+	gauge                gaugeBatch
+	sum                  sumBatch
+	histogram            histogramBatch
+	exponentialHistogram exponentialHistogramBatch
+	summary              summaryBatch
+
+	converter  attributeConverter
+	shared     sharedAttributes
+	attributes []convertedAttribute
+	// rollups - the gauge and sum datapoints recorded in the rollups once the batch is written
+	rollups []rollupDatapoint
+}
+
+// newMetricBatch
+//
+//	@param logger
+//	@param telemetry
+//	@param quantileColumns
+//	@return *metricBatch
+func newMetricBatch(logger *zap.Logger, telemetry *exporterTelemetry, quantileColumns []string) *metricBatch {
+	b := &metricBatch{converter: attributeConverter{logger, telemetry}}
+	b.gauge.init()
+	b.sum.init()
+	b.histogram.init()
+	b.exponentialHistogram.init()
+	b.summary.init(quantileColumns)
+	return b
+}
+
+// reset
+//
+//	@receiver b
+func (b *metricBatch) reset() {
+	resetRows(b.gauge.tables)
+	resetRows(b.sum.tables)
+	resetRows(b.histogram.tables)
+	resetRows(b.exponentialHistogram.tables)
+	resetRows(b.summary.tables)
+	b.shared.reset()
+	b.attributes = clearAttributes(b.attributes)
+	b.rollups = b.rollups[:0]
+}
@@ -0,0 +1,149 @@
+package kineticaotelexporter
+
+import (
+	"testing"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"github.com/hamba/avro"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// benchmarkEncoder - encodes the rows of a batch as the /insert/records requests of the
+// tables, in place of the writer, so the benchmarks measure the conversion and the
+// encoding without a Kinetica to send the requests to
+type benchmarkEncoder struct {
+	schemas map[string]avro.Schema
+	request *avro.Writer
+	row     *avro.Writer
+}
+
+// newBenchmarkEncoder - an encoder with the type schemas of the exporter's tables
+//
+//	@param b
+//	@return *benchmarkEncoder
+func newBenchmarkEncoder(b *testing.B) *benchmarkEncoder {
+	schemas := make(map[string]avro.Schema, len(tableRecords))
+	for table, record := range tableRecords {
+		schema, err := avro.Parse(kineticatest.TypeSchemaOf(record))
+		if err != nil {
+			b.Fatal(err)
+		}
+		schemas[table] = schema
+	}
+	return &benchmarkEncoder{schemas: schemas, request: avro.NewWriter(nil, 64*1024), row: avro.NewWriter(nil, 64*1024)}
+}
+
+// encode encodes the rows of every table into one request per table
+//
+//	@receiver enc
+//	@param b
+//	@param tables
+func (enc *benchmarkEncoder) encode(b *testing.B, tables []rowBuffer) {
+	for _, rows := range tables {
+		if rows.count() == 0 {
+			continue
+		}
+		enc.request.Reset(nil)
+		if err := encodeInsertRecords(enc.request, enc.row, "otel."+rows.tableName(), enc.schemas[rows.tableName()], rows, 0, rows.count(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkConvert converts and encodes one batch of benchmarkBatchSize records per
+// iteration
+//
+//	@param b
+//	@param convert
+func benchmarkConvert(b *testing.B, convert func()) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		convert()
+	}
+}
+
+func BenchmarkConvertLogs(b *testing.B) {
+	exporter, err := newLogsExporter(zap.NewNop(), nil, CreateDefaultConfig().(*Config))
+	if err != nil {
+		b.Fatal(err)
+	}
+	encoder := newBenchmarkEncoder(b)
+	batch := newLogBatch(zap.NewNop(), nil)
+
+	logs := newBenchmarkLogs()
+	resourceLogs := logs.ResourceLogs().At(0)
+	scopeLogs := resourceLogs.ScopeLogs().At(0)
+
+	benchmarkConvert(b, func() {
+		batch.shared.setResource(batch.converter, resourceLogs.Resource())
+		batch.shared.setScope(batch.converter, scopeLogs.Scope())
+		for i := 0; i < scopeLogs.LogRecords().Len(); i++ {
+			if err := exporter.appendLogRecord(batch, resourceLogs.Resource(), "", scopeLogs.Scope(), "", scopeLogs.LogRecords().At(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+		encoder.encode(b, batch.tables)
+		batch.reset()
+	})
+}
+
+func BenchmarkConvertTraces(b *testing.B) {
+	exporter, err := newTracesExporter(zap.NewNop(), nil, CreateDefaultConfig().(*Config))
+	if err != nil {
+		b.Fatal(err)
+	}
+	encoder := newBenchmarkEncoder(b)
+	batch := newTraceBatch(zap.NewNop(), nil)
+
+	traces := newBenchmarkTraces()
+	resourceSpans := traces.ResourceSpans().At(0)
+	scopeSpans := resourceSpans.ScopeSpans().At(0)
+
+	benchmarkConvert(b, func() {
+		batch.shared.setResource(batch.converter, resourceSpans.Resource())
+		batch.shared.setScope(batch.converter, scopeSpans.Scope())
+		for i := 0; i < scopeSpans.Spans().Len(); i++ {
+			if err := exporter.appendSpan(batch, "", scopeSpans.Scope(), "", scopeSpans.Spans().At(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+		encoder.encode(b, batch.tables)
+		batch.reset()
+	})
+}
+
+func BenchmarkConvertMetrics(b *testing.B) {
+	exporter, err := newMetricsExporter(zap.NewNop(), nil, CreateDefaultConfig().(*Config))
+	if err != nil {
+		b.Fatal(err)
+	}
+	encoder := newBenchmarkEncoder(b)
+	batch := newMetricBatch(zap.NewNop(), nil, nil)
+
+	metrics := newBenchmarkMetrics()
+	resourceMetrics := metrics.ResourceMetrics().At(0)
+	scopeMetrics := resourceMetrics.ScopeMetrics().At(0)
+
+	resource, scope := resourceMetrics.Resource(), scopeMetrics.Scope()
+	benchmarkConvert(b, func() {
+		batch.shared.setResource(batch.converter, resource)
+		batch.shared.setScope(batch.converter, scope)
+		for i := 0; i < scopeMetrics.Metrics().Len(); i++ {
+			metric := scopeMetrics.Metrics().At(i)
+			var err error
+			if metric.Type() == pmetric.MetricTypeGauge {
+				err = exporter.appendGauge(batch, resource, "", scope, "", metric.Gauge(), metric.Name(), metric.Description(), metric.Unit())
+			} else {
+				err = exporter.appendSum(batch, resource, "", scope, "", metric.Sum(), metric.Name(), metric.Description(), metric.Unit())
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		encoder.encode(b, batch.gauge.tables)
+		encoder.encode(b, batch.sum.tables)
+		batch.reset()
+	})
+}
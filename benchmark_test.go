@@ -0,0 +1,197 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// The Push benchmarks write a push of benchmarkBatchSize records through the exporter to
+// the in-process fake Kinetica, whose allocations are included. They also build on the
+// exporter from before the record structs were replaced by row buffers, and
+// testdata/benchmarks holds their results before (push-before.txt) and after
+// (push-after.txt) that change, both run against the fake of this tree. Compare a change
+// to them with
+//
+//	go test -run xxx -bench Push -benchmem -count 5 . > new.txt
+//	benchstat testdata/benchmarks/push-after.txt new.txt
+
+// number of logs, spans and datapoints of a benchmark push
+const benchmarkBatchSize = 1000
+
+// putBenchmarkResource adds the resource and scope attributes of every benchmark batch
+//
+//	@param resource
+//	@param scope
+func putBenchmarkResource(resource pcommon.Resource, scope pcommon.InstrumentationScope) {
+	resource.Attributes().PutStr("service.name", "checkout")
+	resource.Attributes().PutStr("host.name", "checkout-7d9f8")
+	resource.Attributes().PutInt("process.pid", 4242)
+	scope.SetName("io.opentelemetry.checkout")
+	scope.SetVersion("1.2.3")
+}
+
+// putBenchmarkAttributes adds one attribute of every scalar type
+//
+//	@param attributes
+//	@param i
+func putBenchmarkAttributes(attributes pcommon.Map, i int) {
+	attributes.PutStr("http.route", fmt.Sprintf("/pay/%d", i%10))
+	attributes.PutInt("http.status_code", 200)
+	attributes.PutDouble("payment.amount", float64(i)/4)
+	attributes.PutBool("payment.retried", i%2 == 0)
+}
+
+// newBenchmarkLogs - benchmarkBatchSize logs of one resource and scope
+//
+//	@return plog.Logs
+func newBenchmarkLogs() plog.Logs {
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	putBenchmarkResource(resourceLogs.Resource(), scopeLogs.Scope())
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < benchmarkBatchSize; i++ {
+		logRecord := scopeLogs.LogRecords().AppendEmpty()
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Duration(i) * time.Millisecond)))
+		logRecord.SetSeverityNumber(plog.SeverityNumberInfo)
+		logRecord.SetSeverityText("INFO")
+		logRecord.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, byte(i)})
+		logRecord.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, byte(i)})
+		logRecord.Body().SetStr("payment accepted")
+		putBenchmarkAttributes(logRecord.Attributes(), i)
+	}
+	return logs
+}
+
+// newBenchmarkTraces - benchmarkBatchSize spans of one resource and scope with an event each
+//
+//	@return ptrace.Traces
+func newBenchmarkTraces() ptrace.Traces {
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	scopeSpans := resourceSpans.ScopeSpans().AppendEmpty()
+	putBenchmarkResource(resourceSpans.Resource(), scopeSpans.Scope())
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < benchmarkBatchSize; i++ {
+		span := scopeSpans.Spans().AppendEmpty()
+		span.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, byte(i >> 8), byte(i)})
+		span.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, byte(i >> 8), byte(i)})
+		span.SetName("POST /pay")
+		span.SetKind(ptrace.SpanKindServer)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Duration(i) * time.Millisecond)))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Duration(i+20) * time.Millisecond)))
+		putBenchmarkAttributes(span.Attributes(), i)
+		event := span.Events().AppendEmpty()
+		event.SetName("payment.authorized")
+		event.Attributes().PutStr("payment.provider", "visa")
+	}
+	return traces
+}
+
+// newBenchmarkMetrics - benchmarkBatchSize datapoints of one resource and scope, in gauges
+// and sums of 10 datapoints
+//
+//	@return pmetric.Metrics
+func newBenchmarkMetrics() pmetric.Metrics {
+	const datapointsPerMetric = 10
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+	putBenchmarkResource(resourceMetrics.Resource(), scopeMetrics.Scope())
+	timestamp := pcommon.NewTimestampFromTime(time.Unix(1700000000, 0))
+	for i := 0; i < benchmarkBatchSize/datapointsPerMetric; i++ {
+		metric := scopeMetrics.Metrics().AppendEmpty()
+		metric.SetName(fmt.Sprintf("payments.metric_%d", i))
+		var datapoints pmetric.NumberDataPointSlice
+		if i%2 == 0 {
+			datapoints = metric.SetEmptyGauge().DataPoints()
+		} else {
+			metric.SetEmptySum().SetIsMonotonic(true)
+			metric.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			datapoints = metric.Sum().DataPoints()
+		}
+		for j := 0; j < datapointsPerMetric; j++ {
+			datapoint := datapoints.AppendEmpty()
+			datapoint.SetTimestamp(timestamp)
+			datapoint.SetDoubleValue(float64(j))
+			putBenchmarkAttributes(datapoint.Attributes(), j)
+		}
+	}
+	return metrics
+}
+
+// newBenchmarkConfig - the default config writing to a fake Kinetica holding every table
+//
+//	@param b
+//	@return *Config
+func newBenchmarkConfig(b *testing.B) *Config {
+	server := kineticatest.NewServer(b)
+	for table, record := range tableRecords {
+		if err := server.CreateTable("otel."+table, kineticatest.TypeSchemaOf(record), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Host = server.URL
+	return cfg
+}
+
+// benchmarkPush runs one push per iteration
+//
+//	@param b
+//	@param push
+func benchmarkPush(b *testing.B, push func() error) {
+	// the first push looks up the table types
+	if err := push(); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := push(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPushLogs(b *testing.B) {
+	exporter, err := newLogsExporter(zap.NewNop(), nil, newBenchmarkConfig(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	logs := newBenchmarkLogs()
+	benchmarkPush(b, func() error {
+		return exporter.pushLogsData(context.Background(), logs)
+	})
+}
+
+func BenchmarkPushTraces(b *testing.B) {
+	exporter, err := newTracesExporter(zap.NewNop(), nil, newBenchmarkConfig(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	traces := newBenchmarkTraces()
+	benchmarkPush(b, func() error {
+		return exporter.pushTraceData(context.Background(), traces)
+	})
+}
+
+func BenchmarkPushMetrics(b *testing.B) {
+	exporter, err := newMetricsExporter(zap.NewNop(), nil, newBenchmarkConfig(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	metrics := newBenchmarkMetrics()
+	benchmarkPush(b, func() error {
+		return exporter.pushMetricsData(context.Background(), metrics)
+	})
+}
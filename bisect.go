@@ -0,0 +1,125 @@
+package kineticaotelexporter
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// failedRows - the rows [from, to) of a chunk that could not be inserted
+type failedRows struct {
+	from int
+	to   int
+	err  error
+}
+
+// bisectsRejectedChunks - whether a rejected chunk is split to isolate the rows Kinetica
+// rejects. Only a chunk sent in one /insert/records request or one INSERT statement is
+// rejected as a whole, the files of a bulk load may be partly written.
+//
+//	@receiver kiwriter
+//	@return bool
+func (kiwriter *KiWriter) bisectsRejectedChunks() bool {
+	return kiwriter.bulk == nil
+}
+
+// maxBisectRequests - the most inserts a rejected chunk is bisected with, the halves
+// still failing when they are spent fail as they are
+const maxBisectRequests = 64
+
+// bisectFailedRows - the rows of a chunk whose insert failed with err. A chunk Kinetica
+// rejects because of its rows, e.g. an over-long string, is split in halves which are
+// inserted again, down to single rows or maxBisectRequests inserts, so only the rejected
+// rows fail and every other row is inserted. A chunk whose halves both fail, or failing
+// for another reason, e.g. a missing table, an unreachable Kinetica or an error Kinetica
+// does not blame the rows for, fails as a whole.
+//
+//	@receiver kiwriter
+//	@param tableName
+//	@param from
+//	@param to
+//	@param err
+//	@param insert
+//	@return []failedRows
+func (kiwriter *KiWriter) bisectFailedRows(tableName string, from int, to int, err error, insert func(from int, to int) error) []failedRows {
+	if err == nil {
+		return nil
+	}
+	if to-from <= 1 || !kiwriter.bisectsRejectedChunks() || !isRecordInsertError(err) {
+		return []failedRows{{from, to, err}}
+	}
+
+	failed := bisectRows(from, to, err, insert)
+	rejected := 0
+	for _, rows := range failed {
+		rejected += rows.to - rows.from
+	}
+	kiwriter.logger.Warn("Isolated the rows Kinetica rejects in a chunk", zap.String("Table", tableName), zap.Int("Record count", to-from), zap.Int("Rejected count", rejected), zap.Error(err))
+	return failed
+}
+
+// bisectRows - inserts both halves of the rows [from, to) rejected with err and bisects
+// the halves that fail again. The rows fail as a whole when both halves fail, rows
+// Kinetica rejects all through the chunk are not isolated by bisecting.
+//
+//	@param from
+//	@param to
+//	@param err
+//	@param insert
+//	@return []failedRows
+func bisectRows(from int, to int, err error, insert func(from int, to int) error) []failedRows {
+	mid := from + (to-from)/2
+	errs := [2]error{insert(from, mid), insert(mid, to)}
+	if errs[0] != nil && errs[1] != nil {
+		return []failedRows{{from, to, err}}
+	}
+
+	requests := len(errs)
+	var failed []failedRows
+	for i, half := range [2][2]int{{from, mid}, {mid, to}} {
+		failed = append(failed, bisectHalf(half[0], half[1], errs[i], insert, &requests)...)
+	}
+	return failed
+}
+
+// bisectHalf - bisects the rows [from, to) whose insert failed with err while requests
+// stays within maxBisectRequests
+//
+//	@param from
+//	@param to
+//	@param err
+//	@param insert
+//	@param requests
+//	@return []failedRows
+func bisectHalf(from int, to int, err error, insert func(from int, to int) error, requests *int) []failedRows {
+	if err == nil {
+		return nil
+	}
+	if to-from <= 1 || !isRecordInsertError(err) || *requests+2 > maxBisectRequests {
+		return []failedRows{{from, to, err}}
+	}
+
+	*requests += 2
+	mid := from + (to-from)/2
+	var failed []failedRows
+	for _, half := range [2][2]int{{from, mid}, {mid, to}} {
+		failed = append(failed, bisectHalf(half[0], half[1], insert(half[0], half[1]), insert, requests)...)
+	}
+	return failed
+}
+
+// failedRowsOutcome - the outcome of rows that could not be inserted. A single rejected
+// row is logged when there is no dead letter table to store it in.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param failed
+//	@param data
+//	@return chunkResult
+func (kiwriter *KiWriter) failedRowsOutcome(ctx context.Context, tableName string, failed failedRows, data []any) chunkResult {
+	if len(data) == 1 && kiwriter.deadLetter == nil && isRecordInsertError(failed.err) {
+		kiwriter.logger.Error("Kinetica rejected a record", zap.String("Table", tableName), zap.Any("Record", data[0]), zap.Error(failed.err))
+	}
+	return kiwriter.chunkOutcome(ctx, tableName, data, failed.err)
+}
@@ -0,0 +1,114 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestUnavailableKineticaIsNotBisected(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newTestConfig(server)
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	severities := make([]string, 64)
+	for i := range severities {
+		severities[i] = "info"
+	}
+	logs := testLogs(severities...)
+	var inserts int
+	// the first push also looks up the table types
+	for i := 0; i < 2; i++ {
+		requests := server.Requests()
+		if err := exporter.ConsumeLogs(context.Background(), logs); err != nil {
+			t.Fatal(err)
+		}
+		inserts = server.Requests() - requests
+	}
+
+	server.Unavailable(http.StatusServiceUnavailable)
+	requests := server.Requests()
+	if err := exporter.ConsumeLogs(context.Background(), logs); err == nil {
+		t.Error("ConsumeLogs() to an unavailable Kinetica succeeded")
+	}
+
+	// the chunks are not split
+	if got := server.Requests() - requests; got != inserts {
+		t.Errorf("%d requests to an unavailable Kinetica, want the %d inserts of the push", got, inserts)
+	}
+}
+
+func TestBisectRows(t *testing.T) {
+	rejected := errors.New("invalid value")
+	tests := []struct {
+		name     string
+		rejects  func(row int) bool
+		want     []failedRows
+		requests int
+	}{
+		{"rejected rows are isolated", func(row int) bool { return row == 1 || row == 2 },
+			[]failedRows{{1, 2, rejected}, {2, 3, rejected}}, 8},
+		{"both halves failing fail the chunk", func(row int) bool { return row == 1 || row == 6 },
+			[]failedRows{{0, 8, rejected}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			got := bisectRows(0, 8, rejected, rejectingInsert(&requests, tt.rejects, rejected))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bisectRows() = %v, want %v", got, tt.want)
+			}
+			if requests != tt.requests {
+				t.Errorf("bisectRows() sent %d inserts, want %d", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestBisectRowsStopsAtMaxBisectRequests(t *testing.T) {
+	rejected := errors.New("invalid value")
+	rejects := func(row int) bool { return row%2 == 0 && row < 512 }
+	requests := 0
+	failed := bisectRows(0, 1024, rejected, rejectingInsert(&requests, rejects, rejected))
+
+	if requests != maxBisectRequests {
+		t.Errorf("bisectRows() sent %d inserts, want %d", requests, maxBisectRequests)
+	}
+	// the halves left when the inserts are spent fail with their rejected rows
+	for row := 0; row < 1024; row++ {
+		inFailed := false
+		for _, rows := range failed {
+			inFailed = inFailed || (rows.from <= row && row < rows.to)
+		}
+		if rejects(row) && !inFailed {
+			t.Errorf("rejected row %d is not among the failed rows %v", row, failed)
+		}
+	}
+}
+
+// rejectingInsert - an insert of rows failing with err when one of its rows is rejected,
+// counting the inserts in requests
+//
+//	@param requests
+//	@param rejects
+//	@param err
+//	@return func(from int, to int) error
+func rejectingInsert(requests *int, rejects func(row int) bool, err error) func(from int, to int) error {
+	return func(from int, to int) error {
+		*requests++
+		for row := from; row < to; row++ {
+			if rejects(row) {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,229 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SQL statements to create the tables of a signal
+const (
+	CreateSchema string = `CREATE SCHEMA IF NOT EXISTS "%s"`
+	CreateTable  string = `CREATE TABLE IF NOT EXISTS %s
+(
+%s
+)`
+	PartitionByRange string = `
+PARTITION BY RANGE ("%s")`
+)
+
+// tableRecords - the record type stored in every table, the columns of a table are
+// the avro tags of its record in field order
+var tableRecords = map[string]any{
+	LogTable:                  Log{},
+	LogAttributeTable:         LogAttribute{},
+	LogResourceAttributeTable: ResourceAttribute{},
+	LogScopeAttributeTable:    ScopeAttribute{},
+
+	TraceSpanTable:              Span{},
+	TraceSpanAttributeTable:     SpanAttribute{},
+	TraceResourceAttributeTable: TraceResourceAttribute{},
+	TraceScopeAttributeTable:    TraceScopeAttribute{},
+	TraceEventAttributeTable:    EventAttribute{},
+	TraceLinkAttributeTable:     LinkAttribute{},
+
+	GaugeTable:                           Gauge{},
+	GaugeDatapointTable:                  GaugeDatapoint{},
+	GaugeDatapointAttributeTable:         GaugeDatapointAttribute{},
+	GaugeDatapointExemplarTable:          GaugeDatapointExemplar{},
+	GaugeDatapointExemplarAttributeTable: GaugeDataPointExemplarAttribute{},
+	GaugeResourceAttributeTable:          GaugeResourceAttribute{},
+	GaugeScopeAttributeTable:             GaugeScopeAttribute{},
+
+	SumTable:                           Sum{},
+	SumDatapointTable:                  SumDatapoint{},
+	SumDatapointAttributeTable:         SumDataPointAttribute{},
+	SumDatapointExemplarTable:          SumDatapointExemplar{},
+	SumDataPointExemplarAttributeTable: SumDataPointExemplarAttribute{},
+	SumResourceAttributeTable:          SumResourceAttribute{},
+	SumScopeAttributeTable:             SumScopeAttribute{},
+
+	HistogramTable:                           Histogram{},
+	HistogramDatapointTable:                  HistogramDatapoint{},
+	HistogramDatapointAttributeTable:         HistogramDataPointAttribute{},
+	HistogramBucketCountsTable:               HistogramDatapointBucketCount{},
+	HistogramExplicitBoundsTable:             HistogramDatapointExplicitBound{},
+	HistogramDatapointExemplarTable:          HistogramDatapointExemplar{},
+	HistogramDataPointExemplarAttributeTable: HistogramDataPointExemplarAttribute{},
+	HistogramResourceAttributeTable:          HistogramResourceAttribute{},
+	HistogramScopeAttributeTable:             HistogramScopeAttribute{},
+
+	ExpHistogramTable:                           ExponentialHistogram{},
+	ExpHistogramDatapointTable:                  ExponentialHistogramDatapoint{},
+	ExpHistogramDatapointAttributeTable:         ExponentialHistogramDataPointAttribute{},
+	ExpHistogramNegativeBucketCountsTable:       ExponentialHistogramBucketNegativeCount{},
+	ExpHistogramPositiveBucketCountsTable:       ExponentialHistogramBucketPositiveCount{},
+	ExpHistogramDatapointExemplarTable:          ExponentialHistogramDatapointExemplar{},
+	ExpHistogramDataPointExemplarAttributeTable: ExponentialHistogramDataPointExemplarAttribute{},
+	ExpHistogramResourceAttributeTable:          ExponentialHistogramResourceAttribute{},
+	ExpHistogramScopeAttributeTable:             ExponentialHistogramScopeAttribute{},
+
+	SummaryTable:                       Summary{},
+	SummaryDatapointTable:              SummaryDatapoint{},
+	SummaryDatapointAttributeTable:     SummaryDataPointAttribute{},
+	SummaryDatapointQuantileValueTable: SummaryDatapointQuantileValues{},
+	SummaryResourceAttributeTable:      SummaryResourceAttribute{},
+	SummaryScopeAttributeTable:         SummaryScopeAttribute{},
+}
+
+// hexIDColumnTypes - the string columns holding hex trace and span ids, the other
+// string columns named id or *_id hold the UUID record ids
+var hexIDColumnTypes = map[string]string{
+	"trace_id":       "VARCHAR(32)",
+	"span_id":        "VARCHAR(16)",
+	"parent_span_id": "VARCHAR(16)",
+	"link_span_id":   "VARCHAR(16)",
+}
+
+// tableColumn - a column of a table created by the exporter
+type tableColumn struct {
+	name     string
+	sqlType  string
+	nullable bool
+}
+
+// tableColumns - the columns of a table from the avro tags of its record. Embedded
+// structs contribute their fields, pointer fields are nullable, fields without an avro
+// tag are not stored.
+//
+//	@param record
+//	@return []tableColumn
+func tableColumns(record any) []tableColumn {
+	var columns []tableColumn
+	appendTableColumns(reflect.TypeOf(record), &columns)
+	return columns
+}
+
+// appendTableColumns
+//
+//	@param recordType
+//	@param columns
+func appendTableColumns(recordType reflect.Type, columns *[]tableColumn) {
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		name := field.Tag.Get("avro")
+		if field.Anonymous && name == "" {
+			appendTableColumns(field.Type, columns)
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		nullable := fieldType.Kind() == reflect.Pointer
+		if nullable {
+			fieldType = fieldType.Elem()
+		}
+		*columns = append(*columns, tableColumn{name: name, sqlType: columnType(name, fieldType), nullable: nullable})
+	}
+}
+
+// columnType - the Kinetica type of the column storing a field
+//
+//	@param name
+//	@param fieldType
+//	@return string
+func columnType(name string, fieldType reflect.Type) string {
+	switch fieldType.Kind() {
+	case reflect.String:
+		if sqlType, ok := hexIDColumnTypes[name]; ok {
+			return sqlType
+		}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			return "UUID"
+		}
+		if name == "key" {
+			return "VARCHAR(256)"
+		}
+		return "VARCHAR"
+	case reflect.Int8:
+		return "TINYINT"
+	case reflect.Int16:
+		return "SMALLINT"
+	case reflect.Int, reflect.Int32:
+		return "INTEGER"
+	case reflect.Int64:
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		return "DOUBLE"
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return "BLOB"
+		}
+	}
+	panic(fmt.Sprintf("no column type for field %s of kind %s", name, fieldType.Kind()))
+}
+
+// createTableSQL - the statement creating a table with the columns of its record and
+// the primary key the exporter expects. A table range partitioned on partitionColumn
+// has the column in its primary key, the record ids determine the time of the record
+// so the key stays as unique as without it.
+//
+//	@param qualifiedTable
+//	@param logicalTable
+//	@param partitionColumn
+//	@return string
+func createTableSQL(qualifiedTable string, logicalTable string, partitionColumn string) string {
+	var definitions []string
+	for _, column := range tableColumns(tableRecords[logicalTable]) {
+		definition := fmt.Sprintf(`	"%s" %s`, column.name, column.sqlType)
+		if !column.nullable {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+	primaryKey := primaryKeyColumns[logicalTable]
+	if partitionColumn != "" {
+		primaryKey = append(append([]string(nil), primaryKey...), partitionColumn)
+	}
+	if len(primaryKey) > 0 {
+		definitions = append(definitions, fmt.Sprintf(`	PRIMARY KEY ("%s")`, strings.Join(primaryKey, `", "`)))
+	}
+	statement := fmt.Sprintf(CreateTable, qualifiedTable, strings.Join(definitions, ",\n"))
+	if partitionColumn != "" {
+		statement += fmt.Sprintf(PartitionByRange, partitionColumn)
+	}
+	return statement
+}
+
+// createTables - creates the schema and the missing tables of a signal with the
+// primary keys the exporter expects, existing tables are left as they are
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param signal
+//	@return error
+func (kiwriter *KiWriter) createTables(ctx context.Context, signal string) error {
+	if len(kiwriter.cfg.Schema) != 0 {
+		if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, fmt.Sprintf(CreateSchema, kiwriter.cfg.Schema), 0, 0, "", nil); err != nil {
+			return err
+		}
+	}
+
+	var errs error
+	for _, tableName := range signalTables[signal] {
+		kiwriter.logger.Debug("Creating table", zap.String("Table", kiwriter.finalTableName(tableName)))
+		statement := createTableSQL(kiwriter.qualifiedTableName(tableName), tableName, kiwriter.cfg.Retention.partitionColumn(tableName))
+		if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("cannot create table %s: %w", kiwriter.finalTableName(tableName), err))
+		}
+	}
+	return errs
+}
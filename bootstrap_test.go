@@ -0,0 +1,139 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// ddlFile holds the statements creating the tables of every signal in the default schema
+const ddlFile = "ddl.sql"
+
+func TestCreateTables(t *testing.T) {
+	factory := NewFactory()
+	tests := []struct {
+		signal string
+		create func(cfg *Config) (component.Component, error)
+	}{
+		{SignalLogs, func(cfg *Config) (component.Component, error) {
+			return factory.CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+		}},
+		{SignalTraces, func(cfg *Config) (component.Component, error) {
+			return factory.CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+		}},
+		{SignalMetrics, func(cfg *Config) (component.Component, error) {
+			return factory.CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.signal, func(t *testing.T) {
+			server := kineticatest.NewServer(t)
+			cfg := newTestConfig(server)
+			cfg.CreateTables = true
+			cfg.UpdateOnExistingPk = true
+
+			// the second start finds the tables created by the first
+			for i := 0; i < 2; i++ {
+				exporter, err := tt.create(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := exporter.Start(context.Background(), nil); err != nil {
+					t.Fatalf("Start() = %v, want the tables created with their primary keys", err)
+				}
+				if err := exporter.Shutdown(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tables := make(map[string]bool)
+			for _, table := range server.Tables() {
+				tables[table] = true
+			}
+			for _, table := range signalTables[tt.signal] {
+				if !tables["otel."+table] {
+					t.Errorf("table otel.%s was not created", table)
+				}
+			}
+		})
+	}
+}
+
+func TestSpanEventsAndLinksWithTheSameAttributeKey(t *testing.T) {
+	server := kineticatest.NewServer(t)
+	cfg := newTestConfig(server)
+	cfg.CreateTables = true
+	cfg.UpdateOnExistingPk = true
+	exporter, err := NewFactory().CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	traces := ptrace.NewTraces()
+	span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetTraceID(pcommon.TraceID([16]byte{1}))
+	span.SetSpanID(pcommon.SpanID([8]byte{1}))
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000001, 0)))
+	for i := 0; i < 2; i++ {
+		event := span.Events().AppendEmpty()
+		event.SetName("retry")
+		event.Attributes().PutInt("attempt", int64(i))
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID([16]byte{2}))
+		link.SetSpanID(pcommon.SpanID([8]byte{2}))
+		link.Attributes().PutInt("attempt", int64(i))
+	}
+	if err := exporter.ConsumeTraces(context.Background(), traces); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRows(t, server, TraceEventAttributeTable, []string{"span_id", "span_record_id", "event_name", "key", "dropped_attributes_count",
+		"string_value", "bool_value", "double_value", "bytes_value"}, []map[string]any{
+		{"event_index": int(0), "int_value": int64(0)},
+		{"event_index": int(1), "int_value": int64(1)},
+	})
+	assertRows(t, server, TraceLinkAttributeTable, []string{"link_span_id", "span_record_id", "trace_id", "span_id", "key", "dropped_attributes_count",
+		"string_value", "bool_value", "double_value", "bytes_value"}, []map[string]any{
+		{"link_index": int(0), "int_value": int64(0)},
+		{"link_index": int(1), "int_value": int64(1)},
+	})
+}
+
+// TestDDL compares the statements creating the tables of every signal to ddl.sql
+func TestDDL(t *testing.T) {
+	var statements []string
+	for _, signal := range []string{SignalLogs, SignalTraces, SignalMetrics} {
+		for _, table := range signalTables[signal] {
+			statements = append(statements, createTableSQL(qualifiedName("otel", table), table, "")+";\n")
+		}
+	}
+	got := []byte(strings.Join(append([]string{"-- Generated by TestDDL, run the tests with -update to regenerate\n" +
+		"-- The tables of every signal in the default otel schema, as created by create_tables\n" +
+		"-- without retention, with retention the time tables are range partitioned on their time column\n" +
+		"\n" + fmt.Sprintf(CreateSchema, "otel") + ";\n"}, statements...), "\n"))
+
+	if *update {
+		if err := os.WriteFile(ddlFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(ddlFile)
+	if err != nil {
+		t.Fatalf("%v, run the tests with -update to create it", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("the statements differ from %s, run the tests with -update if the change is intended\ngot:\n%s", ddlFile, got)
+	}
+}
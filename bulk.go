@@ -0,0 +1,583 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro"
+	"github.com/xitongsys/parquet-go/writer"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	bulkFileExtension    = ".parquet"
+	bulkPartialExtension = ".partial"
+	// bulkLoadedExtension - a file handed to Kinetica for loading, it may have been loaded
+	bulkLoadedExtension = ".loaded"
+	// bulkFailedExtension - a file that failed to load max_load_attempts times
+	bulkFailedExtension = ".failed"
+
+	createDirectoryEndpoint = "/create/directory"
+	uploadFilesEndpoint     = "/upload/files"
+	deleteFilesEndpoint     = "/delete/files"
+	insertFromFilesEndpoint = "/insert/records/fromfiles"
+)
+
+// bulkFile - the Parquet file the records of one table are buffered in
+type bulkFile struct {
+	finalTable string
+	path       string
+	file       *os.File
+	writer     *writer.JSONWriter
+	size       int64
+	created    time.Time
+}
+
+// bulkLoader buffers the records of every table of a signal in Parquet files on local
+// disk. A file is closed once it reaches the configured size or flush interval, then
+// uploaded to KiFS and loaded into its table. Closed files that fail to load are kept
+// and retried on the next flush, also after a restart, up to max_load_attempts times.
+type bulkLoader struct {
+	cfg    BulkConfig
+	dir    string
+	writer *KiWriter
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	open     map[string]*bulkFile
+	closed   []string
+	attempts map[string]int
+	seq      uint64
+
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newBulkLoader - the bulk loader of one signal, closed files left by a previous run are
+// picked up for loading
+//
+//	@param cfg
+//	@param signal
+//	@param writer
+//	@param logger
+//	@return *bulkLoader
+//	@return error
+func newBulkLoader(cfg BulkConfig, signal string, writer *KiWriter, logger *zap.Logger) (*bulkLoader, error) {
+	dir := filepath.Join(cfg.Directory, signal)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create bulk directory %s: %w", dir, err)
+	}
+
+	b := &bulkLoader{
+		cfg:      cfg,
+		dir:      dir,
+		writer:   writer,
+		logger:   logger,
+		open:     make(map[string]*bulkFile),
+		attempts: make(map[string]int),
+		flushCh:  make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read bulk directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(entry.Name(), bulkFileExtension):
+			b.closed = append(b.closed, entry.Name())
+		case strings.HasSuffix(entry.Name(), bulkPartialExtension):
+			// the file was being written when the previous run stopped, it has no footer
+			logger.Warn("Removing incomplete bulk file", zap.String("File", entry.Name()))
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(entry.Name(), bulkLoadedExtension):
+			// the file was being loaded when the previous run stopped
+			name := strings.TrimSuffix(entry.Name(), bulkLoadedExtension)
+			if !b.idempotentLoads() {
+				logger.Warn("Removing bulk file that may have been loaded, loading it again would duplicate its rows", zap.String("File", name))
+				if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := os.Rename(filepath.Join(dir, entry.Name()), filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+			b.closed = append(b.closed, name)
+		}
+	}
+	sort.Strings(b.closed)
+
+	if len(b.closed) > 0 {
+		logger.Info("Found bulk files to load", zap.String("Directory", dir), zap.Int("File count", len(b.closed)))
+	}
+	return b, nil
+}
+
+// start creates the KiFS directory and the periodic flush of the bulk files
+//
+//	@receiver b
+//	@param ctx
+//	@return error
+func (b *bulkLoader) start(ctx context.Context) error {
+	if err := b.writer.rest.createDirectory(ctx, b.cfg.KifsDirectory); err != nil {
+		return fmt.Errorf("cannot create KiFS directory %s: %w", b.cfg.KifsDirectory, err)
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		ticker := time.NewTicker(b.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.stopCh:
+				return
+			case now := <-ticker.C:
+				b.closeFiles(func(file *bulkFile) bool { return now.Sub(file.created) >= b.cfg.FlushInterval })
+				b.load(context.Background())
+			case <-b.flushCh:
+				b.load(context.Background())
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown stops the periodic flush, then closes and loads every open file
+//
+//	@receiver b
+//	@param ctx
+//	@return error
+func (b *bulkLoader) shutdown(ctx context.Context) error {
+	close(b.stopCh)
+	b.wg.Wait()
+
+	b.closeFiles(func(*bulkFile) bool { return true })
+	if remaining := b.load(ctx); remaining > 0 {
+		return fmt.Errorf("%d bulk files could not be loaded and are kept in %s", remaining, b.dir)
+	}
+	return nil
+}
+
+// add appends records to the file of their table, closing the file once it reaches
+// the configured size
+//
+//	@receiver b
+//	@param ctx
+//	@param kiwriter
+//	@param tableName
+//	@param data
+//	@return int64
+//	@return error
+func (b *bulkLoader) add(ctx context.Context, kiwriter *KiWriter, tableName string, data []any) (int64, error) {
+	finalTable := kiwriter.finalTableName(tableName)
+
+	// the type schema is cached once fetched, it is fetched before taking the lock so a
+	// lookup in Kinetica does not hold up the records of the other tables
+	recordSchema, err := kiwriter.recordSchema(ctx, finalTable)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	file, ok := b.open[finalTable]
+	if !ok {
+		file, err = b.create(finalTable, recordSchema)
+		if err != nil {
+			return 0, err
+		}
+		b.open[finalTable] = file
+	}
+
+	var size int64
+	for _, record := range data {
+		row, err := bulkRow(record)
+		if err != nil {
+			return size, err
+		}
+		if err := file.writer.Write(row); err != nil {
+			return size, err
+		}
+		size += int64(len(row))
+	}
+	file.size += size
+
+	if file.size >= b.cfg.MaxFileSizeMiB*1024*1024 {
+		if err := b.closeFile(file); err != nil {
+			return size, err
+		}
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return size, nil
+}
+
+// create a partial file for the records of a table, the Parquet schema is derived
+// from the type schema of the table
+//
+//	@receiver b
+//	@param finalTable
+//	@param recordSchema
+//	@return *bulkFile
+//	@return error
+func (b *bulkLoader) create(finalTable string, recordSchema avro.Schema) (*bulkFile, error) {
+	parquetSchema, err := parquetSchemaJSON(recordSchema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive the Parquet schema of %s: %w", finalTable, err)
+	}
+
+	b.seq++
+	name := fmt.Sprintf("%s.%d.%06d%s", finalTable, time.Now().UnixNano(), b.seq, bulkFileExtension)
+	filePath := filepath.Join(b.dir, name+bulkPartialExtension)
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := writer.NewJSONWriterFromWriter(parquetSchema, f, 1)
+	if err != nil {
+		return nil, multierr.Append(err, multierr.Append(f.Close(), os.Remove(filePath)))
+	}
+	return &bulkFile{finalTable, filePath, f, pw, 0, time.Now()}, nil
+}
+
+// closeFiles closes the open files matching a condition
+//
+//	@receiver b
+//	@param match
+func (b *bulkLoader) closeFiles(match func(*bulkFile) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, file := range b.open {
+		if !match(file) {
+			continue
+		}
+		if err := b.closeFile(file); err != nil {
+			b.logger.Error("Cannot close bulk file", zap.String("File", file.path), zap.Error(err))
+		}
+	}
+}
+
+// closeFile writes the footer of a file and makes it available for loading, the
+// caller holds the lock
+//
+//	@receiver b
+//	@param file
+//	@return error
+func (b *bulkLoader) closeFile(file *bulkFile) error {
+	delete(b.open, file.finalTable)
+
+	err := multierr.Append(file.writer.WriteStop(), file.file.Close())
+	if err == nil {
+		err = os.Rename(file.path, strings.TrimSuffix(file.path, bulkPartialExtension))
+	}
+	if err != nil {
+		return multierr.Append(err, os.Remove(file.path))
+	}
+	b.closed = append(b.closed, filepath.Base(strings.TrimSuffix(file.path, bulkPartialExtension)))
+	return nil
+}
+
+// load uploads the closed files to KiFS and loads them into their table, oldest
+// first. Files that fail are kept for the next flush until they failed
+// max_load_attempts times.
+//
+//	@receiver b
+//	@param ctx
+//	@return int the number of files left to load
+func (b *bulkLoader) load(ctx context.Context) int {
+	b.mu.Lock()
+	names := b.closed
+	b.closed = nil
+	b.mu.Unlock()
+
+	var failed []string
+	for _, name := range names {
+		if err := b.loadFile(ctx, name); err != nil {
+			b.mu.Lock()
+			b.attempts[name]++
+			attempts := b.attempts[name]
+			b.mu.Unlock()
+			if attempts < b.cfg.MaxLoadAttempts {
+				b.logger.Warn("Cannot load bulk file, keeping it for the next flush", zap.String("File", name), zap.Int("Attempts", attempts), zap.Error(err))
+				failed = append(failed, name)
+				continue
+			}
+			b.logger.Error("Cannot load bulk file, giving up", zap.String("File", name+bulkFailedExtension), zap.Int("Attempts", attempts), zap.Error(err))
+			if err := os.Rename(filepath.Join(b.dir, name), filepath.Join(b.dir, name+bulkFailedExtension)); err != nil {
+				b.logger.Error("Cannot rename failed bulk file", zap.String("File", name), zap.Error(err))
+			}
+		} else {
+			b.logger.Debug("Loaded bulk file", zap.String("File", name))
+		}
+		b.mu.Lock()
+		delete(b.attempts, name)
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = append(failed, b.closed...)
+	return len(b.closed)
+}
+
+// loadFile uploads one closed file to KiFS, loads it into its table and removes it. The
+// file is renamed to .loaded while Kinetica loads it, so a stop during the load is not
+// followed by a second load that duplicates its rows. A load Kinetica fails is retried.
+//
+//	@receiver b
+//	@param ctx
+//	@param name
+//	@return error
+func (b *bulkLoader) loadFile(ctx context.Context, name string) error {
+	localPath := filepath.Join(b.dir, name)
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	kifsPath := path.Join(b.cfg.KifsDirectory, name)
+	if err := b.writer.rest.uploadFile(ctx, kifsPath, data); err != nil {
+		return err
+	}
+	loadedPath := localPath + bulkLoadedExtension
+	if err := os.Rename(localPath, loadedPath); err != nil {
+		return err
+	}
+	if err := b.writer.rest.insertFromFile(ctx, bulkFileTable(name), kifsPath, b.loadOptions()); err != nil {
+		return multierr.Append(err, os.Rename(loadedPath, localPath))
+	}
+	if err := b.writer.rest.deleteFile(ctx, kifsPath); err != nil {
+		b.logger.Warn("Cannot delete loaded bulk file from KiFS", zap.String("File", kifsPath), zap.Error(err))
+	}
+	return os.Remove(loadedPath)
+}
+
+// idempotentLoads - whether loading a file twice leaves its rows once, the primary key
+// of the rows then updates or skips the rows of the first load
+//
+//	@receiver b
+//	@return bool
+func (b *bulkLoader) idempotentLoads() bool {
+	return b.writer.cfg.UpdateOnExistingPk || b.writer.cfg.IgnoreExistingPk
+}
+
+// loadOptions - the options of a load, the file type and the configured insert options
+//
+//	@receiver b
+//	@return map[string]string
+func (b *bulkLoader) loadOptions() map[string]string {
+	options := map[string]string{"file_type": "parquet"}
+	if b.writer.cfg.UpdateOnExistingPk {
+		options["update_on_existing_pk"] = "true"
+	}
+	if b.writer.cfg.IgnoreExistingPk {
+		options["ignore_existing_pk"] = "true"
+	}
+	return options
+}
+
+// bulkFileTable - the table of a bulk file, named <schema>.<table>.<nanos>.<seq>.parquet
+//
+//	@param name
+//	@return string
+func bulkFileTable(name string) string {
+	name = strings.TrimSuffix(name, bulkFileExtension)
+	for i := 0; i < 2; i++ {
+		if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+			name = name[:dot]
+		}
+	}
+	return name
+}
+
+// bulkRow - a record as the JSON object the Parquet writer expects, byte values are
+// written as they are rather than base64 encoded
+//
+//	@param record
+//	@return string
+//	@return error
+func bulkRow(record any) (string, error) {
+	row, err := recordColumns(record)
+	if err != nil {
+		return "", err
+	}
+	for column, value := range row {
+		if bytesValue, ok := value.([]byte); ok {
+			row[column] = string(bytesValue)
+		}
+	}
+	buf, err := json.Marshal(row)
+	return string(buf), err
+}
+
+// parquetSchemaJSON - the Parquet schema, in the JSON format of parquet-go, of an
+// Avro record schema. Nullable unions are optional columns.
+//
+//	@param recordSchema
+//	@return string
+//	@return error
+func parquetSchemaJSON(recordSchema avro.Schema) (string, error) {
+	record, ok := recordSchema.(*avro.RecordSchema)
+	if !ok {
+		return "", fmt.Errorf("type schema is a %s, not a record", recordSchema.Type())
+	}
+
+	type parquetField struct {
+		Tag string `json:"Tag"`
+	}
+	fields := make([]parquetField, 0, len(record.Fields()))
+	for _, field := range record.Fields() {
+		fieldType := field.Type()
+		repetition := "REQUIRED"
+		if union, ok := fieldType.(*avro.UnionSchema); ok {
+			for _, t := range union.Types() {
+				if t.Type() == avro.Null {
+					repetition = "OPTIONAL"
+				} else {
+					fieldType = t
+				}
+			}
+		}
+
+		var physical string
+		switch fieldType.Type() {
+		case avro.String:
+			physical = "type=BYTE_ARRAY, convertedtype=UTF8"
+		case avro.Bytes:
+			physical = "type=BYTE_ARRAY"
+		case avro.Int:
+			physical = "type=INT32"
+		case avro.Long:
+			physical = "type=INT64"
+		case avro.Float:
+			physical = "type=FLOAT"
+		case avro.Double:
+			physical = "type=DOUBLE"
+		case avro.Boolean:
+			physical = "type=BOOLEAN"
+		default:
+			return "", fmt.Errorf("column %s has unsupported type %s", field.Name(), fieldType.Type())
+		}
+		fields = append(fields, parquetField{fmt.Sprintf("name=%s, %s, repetitiontype=%s", field.Name(), physical, repetition)})
+	}
+
+	buf, err := json.Marshal(struct {
+		Tag    string         `json:"Tag"`
+		Fields []parquetField `json:"Fields"`
+	}{"name=parquet_go_root, repetitiontype=REQUIRED", fields})
+	return string(buf), err
+}
+
+// createDirectory creates a KiFS directory unless it exists
+//
+//	@receiver c
+//	@param ctx
+//	@param directory
+//	@return error
+func (c *restClient) createDirectory(ctx context.Context, directory string) error {
+	body, err := json.Marshal(map[string]any{
+		"directory_name": directory,
+		"options":        map[string]string{"no_error_if_exists": "true"},
+	})
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, createDirectoryEndpoint, nil, body)
+}
+
+// uploadFile uploads a file to a KiFS path
+//
+//	@receiver c
+//	@param ctx
+//	@param kifsPath
+//	@param data
+//	@return error
+func (c *restClient) uploadFile(ctx context.Context, kifsPath string, data []byte) error {
+	body, err := json.Marshal(map[string]any{
+		"file_names": []string{kifsPath},
+		"file_data":  [][]byte{data},
+		"options":    map[string]string{},
+	})
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, uploadFilesEndpoint, nil, body)
+}
+
+// deleteFile deletes a file from KiFS
+//
+//	@receiver c
+//	@param ctx
+//	@param kifsPath
+//	@return error
+func (c *restClient) deleteFile(ctx context.Context, kifsPath string) error {
+	body, err := json.Marshal(map[string]any{
+		"file_names": []string{kifsPath},
+		"options":    map[string]string{},
+	})
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, deleteFilesEndpoint, nil, body)
+}
+
+// insertFromFile loads a Parquet file in KiFS into a table
+//
+//	@receiver c
+//	@param ctx
+//	@param finalTable
+//	@param kifsPath
+//	@param options
+//	@return error
+func (c *restClient) insertFromFile(ctx context.Context, finalTable string, kifsPath string, options map[string]string) error {
+	body, err := json.Marshal(map[string]any{
+		"table_name":           finalTable,
+		"filepaths":            []string{"kifs://" + kifsPath},
+		"modify_columns":       map[string]map[string]string{},
+		"create_table_options": map[string]string{},
+		"options":              options,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, insertFromFilesEndpoint, nil, body)
+}
+
+// enableBulk - creates the bulk loader of a signal when bulk loading is configured
+//
+//	@receiver kiwriter
+//	@param signal
+//	@return error
+func (kiwriter *KiWriter) enableBulk(signal string) error {
+	if !kiwriter.cfg.Bulk.Enabled {
+		return nil
+	}
+	bulk, err := newBulkLoader(kiwriter.cfg.Bulk, signal, kiwriter, kiwriter.logger)
+	if err != nil {
+		return err
+	}
+	kiwriter.bulk = bulk
+	return nil
+}
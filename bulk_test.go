@@ -0,0 +1,173 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.uber.org/zap"
+)
+
+// newBulkConfig - a config bulk loading the records into a fake Kinetica through a
+// temporary directory
+//
+//	@param t
+//	@param server
+//	@return *Config
+func newBulkConfig(t *testing.T, server *kineticatest.Server) *Config {
+	cfg := newTestConfig(server)
+	cfg.Bulk.Enabled = true
+	cfg.Bulk.Directory = t.TempDir()
+	cfg.Bulk.FlushInterval = time.Hour
+	return cfg
+}
+
+// startBulkExporter - a started logs exporter of a bulk config, the test shuts it down
+//
+//	@param t
+//	@param cfg
+//	@return *kineticaLogsExporter
+func startBulkExporter(t *testing.T, cfg *Config) *kineticaLogsExporter {
+	t.Helper()
+	exporter, err := newLogsExporter(zap.NewNop(), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.start(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return exporter
+}
+
+// bulkFiles - the names of the files in the bulk directory of the logs
+//
+//	@param t
+//	@param cfg
+//	@return []string
+func bulkFiles(t *testing.T, cfg *Config) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(cfg.Bulk.Directory, SignalLogs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestBulkLoadsEveryFileOnce(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newBulkConfig(t, server)
+	exporter := startBulkExporter(t, cfg)
+
+	if err := exporter.pushLogsData(context.Background(), testLogs("info")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := make(map[string]bool)
+	for _, load := range server.Loads() {
+		if loaded[load.Path] {
+			t.Errorf("%s was loaded twice", load.Path)
+		}
+		loaded[load.Path] = true
+		if load.Table != bulkFileTable(filepath.Base(load.Path)) {
+			t.Errorf("%s was loaded into %s", load.Path, load.Table)
+		}
+	}
+	if len(loaded) == 0 {
+		t.Error("no bulk file was loaded")
+	}
+	if files := server.Files(); len(files) != 0 {
+		t.Errorf("KiFS holds the loaded files %v", files)
+	}
+	if names := bulkFiles(t, cfg); len(names) != 0 {
+		t.Errorf("the bulk directory holds the loaded files %v", names)
+	}
+}
+
+func TestBulkGivesUpAfterMaxLoadAttempts(t *testing.T) {
+	server := newTestServer(t)
+	var mu sync.Mutex
+	var attempts int
+	server.RejectLoads(func(table string) bool {
+		if table != "otel."+LogTable {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return true
+	})
+	cfg := newBulkConfig(t, server)
+	cfg.Bulk.MaxLoadAttempts = 2
+	exporter := startBulkExporter(t, cfg)
+
+	if err := exporter.pushLogsData(context.Background(), testLogs("info")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.shutdown(context.Background()); err == nil {
+		t.Fatal("shutdown() with a file that failed to load succeeded")
+	}
+	for i := 0; i < 2; i++ {
+		exporter.writer.bulk.load(context.Background())
+	}
+
+	if attempts != cfg.Bulk.MaxLoadAttempts {
+		t.Errorf("the file of %s was loaded %d times, want %d", LogTable, attempts, cfg.Bulk.MaxLoadAttempts)
+	}
+	names := bulkFiles(t, cfg)
+	if len(names) != 1 || !strings.HasPrefix(names[0], "otel."+LogTable+".") || !strings.HasSuffix(names[0], bulkFileExtension+bulkFailedExtension) {
+		t.Errorf("the bulk directory holds %v, want the file of %s marked failed", names, LogTable)
+	}
+}
+
+func TestBulkInterruptedLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		updatePk bool
+		loaded   bool
+	}{
+		{"removed without a primary key option", false, false},
+		{"loaded again with update_on_existing_pk", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			cfg := newBulkConfig(t, server)
+			cfg.UpdateOnExistingPk = tt.updatePk
+			name := "otel." + LogTable + ".1700000000000000000.000001" + bulkFileExtension
+			dir := filepath.Join(cfg.Bulk.Directory, SignalLogs)
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name+bulkLoadedExtension), []byte("PAR1"), 0o640); err != nil {
+				t.Fatal(err)
+			}
+
+			exporter := startBulkExporter(t, cfg)
+			if err := exporter.shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			loads := server.Loads()
+			if loaded := len(loads) == 1 && loads[0].Path == cfg.Bulk.KifsDirectory+"/"+name; loaded != tt.loaded {
+				t.Errorf("loads = %v, want the interrupted file loaded %v", loads, tt.loaded)
+			} else if loaded && loads[0].Options["update_on_existing_pk"] != "true" {
+				t.Errorf("load options = %v, want update_on_existing_pk", loads[0].Options)
+			}
+			if names := bulkFiles(t, cfg); len(names) != 0 {
+				t.Errorf("the bulk directory holds %v, want the interrupted file gone", names)
+			}
+		})
+	}
+}
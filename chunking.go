@@ -0,0 +1,148 @@
+package kineticaotelexporter
+
+import (
+	"reflect"
+	"sync"
+)
+
+// chunkRange - the rows [from, to) of a table sent in one insert request and their
+// estimated encoded size
+type chunkRange struct {
+	from  int
+	to    int
+	bytes int
+}
+
+// chunkRanges - splits count rows into consecutive chunks of at most maxRecords rows and
+// maxBytes of estimated encoded size. A row larger than maxBytes is a chunk of its own.
+//
+//	@param count
+//	@param size
+//	@param maxRecords
+//	@param maxBytes
+//	@return []chunkRange
+func chunkRanges(count int, size func(i int) int, maxRecords int, maxBytes int) []chunkRange {
+	if count == 0 {
+		return nil
+	}
+
+	var chunks []chunkRange
+	current := chunkRange{}
+	for i := 0; i < count; i++ {
+		rowSize := size(i)
+		if current.to > current.from && (current.to-current.from >= maxRecords || current.bytes+rowSize > maxBytes) {
+			chunks = append(chunks, current)
+			current = chunkRange{from: i, to: i}
+		}
+		current.to++
+		current.bytes += rowSize
+	}
+	return append(chunks, current)
+}
+
+// avroOmittedFields - the indexes of the struct fields tagged avro:"-" per struct type,
+// which are not part of the encoded record
+var avroOmittedFields sync.Map
+
+// omittedFields
+//
+//	@param t
+//	@return map[int]bool
+func omittedFields(t reflect.Type) map[int]bool {
+	if omitted, ok := avroOmittedFields.Load(t); ok {
+		return omitted.(map[int]bool)
+	}
+	omitted := make(map[int]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("avro") == "-" || !field.IsExported() {
+			omitted[i] = true
+		}
+	}
+	avroOmittedFields.Store(t, omitted)
+	return omitted
+}
+
+// estimateEncodedSize - the approximate size of the Avro binary encoding of a record,
+// a struct of the exporter or a record map
+//
+//	@param record
+//	@return int
+func estimateEncodedSize(record any) int {
+	return estimateValueSize(reflect.ValueOf(record))
+}
+
+// estimateValueSize - the approximate Avro binary size of a value, nullable columns are
+// pointers encoded as a union
+//
+//	@param v
+//	@return int
+func estimateValueSize(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.String:
+		return varintSize(int64(v.Len())) + v.Len()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return varintSize(int64(v.Len())) + v.Len()
+		}
+		size := 1
+		if v.Len() > 0 {
+			size += varintSize(int64(v.Len()))
+		}
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i))
+		}
+		return size
+	case reflect.Map:
+		size := 1
+		if v.Len() > 0 {
+			size += varintSize(int64(v.Len()))
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateValueSize(iter.Key()) + estimateValueSize(iter.Value())
+		}
+		return size
+	case reflect.Struct:
+		size := 0
+		omitted := omittedFields(v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			if !omitted[i] {
+				size += estimateValueSize(v.Field(i))
+			}
+		}
+		return size
+	case reflect.Pointer, reflect.Interface:
+		// the union index of a nullable value
+		if v.IsNil() {
+			return 1
+		}
+		return 1 + estimateValueSize(v.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return varintSize(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return varintSize(int64(v.Uint()))
+	case reflect.Float32:
+		return 4
+	case reflect.Float64:
+		return 8
+	case reflect.Bool:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// varintSize - the size of the zig-zag varint encoding of an Avro int or long
+//
+//	@param n
+//	@return int
+func varintSize(n int64) int {
+	u := uint64((n << 1) ^ (n >> 63))
+	size := 1
+	for u >= 0x80 {
+		u >>= 7
+		size++
+	}
+	return size
+}
@@ -0,0 +1,115 @@
+package kineticaotelexporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"github.com/hamba/avro"
+)
+
+func TestChunkRanges(t *testing.T) {
+	sizes := []int{10, 10, 10, 50, 200, 10, 10, 10, 10, 10}
+	size := func(i int) int { return sizes[i] }
+
+	tests := []struct {
+		name       string
+		maxRecords int
+		maxBytes   int
+		want       []chunkRange
+	}{
+		{"records", 4, 1000, []chunkRange{{0, 4, 80}, {4, 8, 230}, {8, 10, 20}}},
+		{"bytes", 100, 100, []chunkRange{{0, 4, 80}, {4, 5, 200}, {5, 10, 50}}},
+		{"both", 3, 60, []chunkRange{{0, 3, 30}, {3, 4, 50}, {4, 5, 200}, {5, 8, 30}, {8, 10, 20}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkRanges(len(sizes), size, tt.maxRecords, tt.maxBytes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := chunkRanges(0, size, 4, 100); got != nil {
+		t.Errorf("chunkRanges() of no rows = %v", got)
+	}
+}
+
+func TestEstimateEncodedSize(t *testing.T) {
+	traceID := "5b8efff798038103d269b633813fc60c"
+	records := []any{
+		*NewLog("log-1", &traceID, nil, 1700000000, 1700000001, 9, "Info", nil, strings.Repeat("body ", 100), 1, 0),
+		*NewSpan(traceID, "eee19b7ec3c1b174", nil, "", "checkout", 2, 1700000000000000000, 1700000000500000000, 0, 0, 0, "", 1),
+		LogAttribute{"log-1", "http.status_code", *NewAttributeValue(-402, "", 0, 0, nil)},
+		SummaryDatapoint{SummaryID: "summary-1", ID: "datapoint-1", Count: 3, Sum: 1.5, Quantiles: map[string]float64{"p50": 0.5}},
+	}
+
+	for _, record := range records {
+		schema, err := avro.Parse(kineticatest.TypeSchemaOf(record))
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := avro.Marshal(schema, record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := estimateEncodedSize(record); got != len(encoded) {
+			t.Errorf("estimateEncodedSize(%T) = %d, want %d", record, got, len(encoded))
+		}
+	}
+}
+
+// fillRecord sets every column of a record to a value that is not zero
+//
+//	@param v
+func fillRecord(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString("value of " + v.Type().Field(i).Name)
+		case reflect.Pointer:
+			value := "nullable value"
+			field.Set(reflect.ValueOf(&value))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.SetInt(-100)
+		case reflect.Float64:
+			field.SetFloat(1.5)
+		case reflect.Slice:
+			field.SetBytes([]byte("bytes"))
+		case reflect.Struct:
+			fillRecord(field)
+		}
+	}
+}
+
+func TestEncodedSize(t *testing.T) {
+	batches := [][]rowBuffer{newLogBatch(nil, nil).tables, newTraceBatch(nil, nil).tables}
+	metrics := newMetricBatch(nil, nil, nil)
+	batches = append(batches, metrics.gauge.tables, metrics.sum.tables, metrics.histogram.tables, metrics.exponentialHistogram.tables, metrics.summary.tables)
+
+	for _, tables := range batches {
+		for _, rows := range tables {
+			record := reflect.New(reflect.TypeOf(tableRecords[rows.tableName()]))
+			fillRecord(record.Elem())
+			sizer, ok := record.Interface().(encodedSizer)
+			if !ok {
+				t.Errorf("the rows of %s do not compute their encoded size", rows.tableName())
+				continue
+			}
+
+			schema, err := avro.Parse(kineticatest.TypeSchemaOf(record.Elem().Interface()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := avro.Marshal(schema, record.Interface())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sizer.encodedSize(); got != len(encoded) {
+				t.Errorf("encodedSize() of %T = %d, want %d", record.Interface(), got, len(encoded))
+			}
+		}
+	}
+}
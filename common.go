@@ -1,6 +1,7 @@
 package kineticaotelexporter
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,7 +9,7 @@ import (
 	"strconv"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
-	semconv "go.opentelemetry.io/collector/semconv/v1.16.0"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
 const (
@@ -100,7 +101,14 @@ const (
 	SummaryDatapointAttributeTable     = "metric_summary_datapoint_attribute"
 	SummaryDatapointQuantileValueTable = "metric_summary_datapoint_quantile_values"
 
+	ExemplarTraceSpanView = "metric_exemplar_trace_span"
+
+	DeadLetterTable = "dead_letter"
+
+	// ChunkSize - the default maximum number of rows of one insert request
 	ChunkSize = 10000
+	// DefaultMaxRequestBytes - the default maximum estimated size of one insert request
+	DefaultMaxRequestBytes = 16 << 20
 )
 
 // AggregationTemporality - Metrics
@@ -170,6 +178,8 @@ func AttributeValueToKineticaFieldValue(value pcommon.Value) (ValueTypePair, err
 		return ValueTypePair{value.Double(), pcommon.ValueTypeDouble}, nil
 	case pcommon.ValueTypeBool:
 		return ValueTypePair{value.Bool(), pcommon.ValueTypeBool}, nil
+	case pcommon.ValueTypeBytes:
+		return ValueTypePair{value.Bytes().AsRaw(), pcommon.ValueTypeBytes}, nil
 	case pcommon.ValueTypeMap:
 		if jsonBytes, err := json.Marshal(otlpKeyValueListToMap(value.Map())); err != nil {
 			return ValueTypePair{nil, pcommon.ValueTypeEmpty}, err
@@ -189,6 +199,88 @@ func AttributeValueToKineticaFieldValue(value pcommon.Value) (ValueTypePair, err
 	}
 }
 
+// attributeValue - the attribute value columns of a value, maps and slices are stored as
+// JSON strings. An empty value has no columns and is reported as not ok.
+//
+//	@param value
+//	@return AttributeValue
+//	@return bool
+//	@return error
+func attributeValue(value pcommon.Value) (AttributeValue, bool, error) {
+	var av AttributeValue
+	switch value.Type() {
+	case pcommon.ValueTypeStr:
+		av.StringValue = value.Str()
+	case pcommon.ValueTypeInt:
+		av.IntValue = int(value.Int())
+	case pcommon.ValueTypeDouble:
+		av.DoubleValue = value.Double()
+	case pcommon.ValueTypeBool:
+		if value.Bool() {
+			av.BoolValue = 1
+		}
+	case pcommon.ValueTypeBytes:
+		av.BytesValue = value.Bytes().AsRaw()
+	case pcommon.ValueTypeMap, pcommon.ValueTypeSlice:
+		jsonValue, err := AttributeValueToKineticaTagValue(value)
+		if err != nil {
+			return av, false, err
+		}
+		av.StringValue = jsonValue
+	case pcommon.ValueTypeEmpty:
+		return av, false, nil
+	default:
+		return av, false, fmt.Errorf("unknown value type %v", value.Type())
+	}
+	return av, true, nil
+}
+
+// traceIDToHex - the lower case hex encoding used for trace IDs in every table, nil for an empty ID
+//
+//	@param traceID
+//	@return *string
+func traceIDToHex(traceID pcommon.TraceID) *string {
+	if traceID.IsEmpty() {
+		return nil
+	}
+	id := hex.EncodeToString(traceID[:])
+	return &id
+}
+
+// spanIDToHex - the lower case hex encoding used for span IDs in every table, nil for an empty ID
+//
+//	@param spanID
+//	@return *string
+func spanIDToHex(spanID pcommon.SpanID) *string {
+	if spanID.IsEmpty() {
+		return nil
+	}
+	id := hex.EncodeToString(spanID[:])
+	return &id
+}
+
+// numberDatapointValue - value of a gauge or sum datapoint as a float64, whatever its value type
+//
+//	@param datapoint
+//	@return float64
+func numberDatapointValue(datapoint pmetric.NumberDataPoint) float64 {
+	if datapoint.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(datapoint.IntValue())
+	}
+	return datapoint.DoubleValue()
+}
+
+// exemplarValue - value of an exemplar as a float64, whatever its value type
+//
+//	@param exemplar
+//	@return float64
+func exemplarValue(exemplar pmetric.Exemplar) float64 {
+	if exemplar.ValueType() == pmetric.ExemplarValueTypeInt {
+		return float64(exemplar.IntValue())
+	}
+	return exemplar.DoubleValue()
+}
+
 // otlpKeyValueListToMap
 //
 //	@param kvList
@@ -245,95 +337,6 @@ func otlpArrayToSlice(arr pcommon.Slice) []interface{} {
 	return s
 }
 
-func convertResourceTags(resource pcommon.Resource) map[string]string {
-	tags := make(map[string]string, resource.Attributes().Len())
-	resource.Attributes().Range(func(k string, v pcommon.Value) bool {
-		tags[k] = v.AsString()
-		return true
-	})
-	// TODO dropped attributes counts
-	return tags
-}
-
-func convertResourceFields(resource pcommon.Resource) map[string]interface{} {
-	fields := make(map[string]interface{}, resource.Attributes().Len())
-	resource.Attributes().Range(func(k string, v pcommon.Value) bool {
-		fields[k] = v.AsRaw()
-		return true
-	})
-	// TODO dropped attributes counts
-	return fields
-}
-
-func convertScopeFields(is pcommon.InstrumentationScope) map[string]interface{} {
-	fields := make(map[string]interface{}, is.Attributes().Len()+2)
-	is.Attributes().Range(func(k string, v pcommon.Value) bool {
-		fields[k] = v.AsRaw()
-		return true
-	})
-	if name := is.Name(); name != "" {
-		fields[semconv.AttributeTelemetrySDKName] = name
-	}
-	if version := is.Version(); version != "" {
-		fields[semconv.AttributeTelemetrySDKVersion] = version
-	}
-	// TODO dropped attributes counts
-	return fields
-}
-
-// getAttributeValue
-//
-//	@param vtPair
-//	@return *AttributeValue
-//	@return error
-func getAttributeValue(vtPair ValueTypePair) (*AttributeValue, error) {
-	var av *AttributeValue
-	var err error
-	switch vtPair.valueType {
-	case pcommon.ValueTypeStr:
-		value := vtPair.value.(string)
-		av = new(AttributeValue)
-		av.SetStringValue(value)
-	case pcommon.ValueTypeInt:
-		value := vtPair.value.(int)
-		av = new(AttributeValue)
-		av.SetIntValue(value)
-	case pcommon.ValueTypeDouble:
-		value := vtPair.value.(float64)
-		av = new(AttributeValue)
-		av.SetDoubleValue(value)
-	case pcommon.ValueTypeBool:
-		value := vtPair.value.(int8)
-		av = new(AttributeValue)
-		av.SetBoolValue(value)
-	case pcommon.ValueTypeBytes:
-		value := vtPair.value.([]byte)
-		av = new(AttributeValue)
-		copy(av.BytesValue, value)
-	case pcommon.ValueTypeMap:
-		// value := vtPair.value
-		// av = new(AttributeValue)
-		// av.SetStringValue(value)
-		err = fmt.Errorf("unhandled value type %v", vtPair.valueType)
-
-	case pcommon.ValueTypeSlice:
-		// value := vtPair.value.(string)
-		// av = new(AttributeValue)
-		// av.SetStringValue(value)
-		err = fmt.Errorf("unhandled value type %v", vtPair.valueType)
-
-	default:
-		err = fmt.Errorf("unknown value type %v", vtPair.valueType)
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	return av, nil
-
-}
-
 // ValidateStruct
 //
 //	@param s
@@ -4,15 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"os"
-	"strconv"
-	"strings"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -20,14 +14,6 @@ const (
 	typeStr = "kinetica"
 	// The stability level of the exporter.
 	stability = component.StabilityLevelAlpha
-
-	maxSize    = 10
-	maxBackups = 5
-	maxAge     = 10
-)
-
-var (
-	kineticaLogger *zap.Logger
 )
 
 // Config defines configuration for the Kinetica exporter.
@@ -37,6 +23,216 @@ type Config struct {
 	Username           string `mapstructure:"username"`
 	Password           string `mapstructure:"password"`
 	BypassSslCertCheck bool   `mapstructure:"bypasssslcertcheck"`
+
+	Rollup RollupConfig `mapstructure:"rollup"`
+
+	// NormalizePrometheus maps metric names to Prometheus-compatible names, stores the
+	// job/instance labels of each metric and drops the otel_scope_* datapoint labels.
+	NormalizePrometheus bool `mapstructure:"normalize_prometheus"`
+
+	// SummaryQuantiles are stored as p<NN> columns of metric_summary_datapoint
+	// (0.5 -> p50, 0.99 -> p99) instead of rows of the quantile values table.
+	SummaryQuantiles []float64 `mapstructure:"summary_quantiles"`
+
+	// ExemplarTraceView creates a view joining the metric exemplars to trace_span on start
+	ExemplarTraceView bool `mapstructure:"exemplar_trace_view"`
+
+	// SeverityTextMapping maps the severity text of log records sent without a severity
+	// number to one of TRACE, DEBUG, INFO, WARN, ERROR or FATAL, extending the built-in mapping
+	SeverityTextMapping map[string]string `mapstructure:"severity_text_mapping"`
+
+	// LogSampling samples the exporter's own log entries written through the collector logger
+	LogSampling LogSamplingConfig `mapstructure:"log_sampling"`
+
+	// Spill writes the chunks that fail because Kinetica is unreachable to a local
+	// directory and replays them once it is reachable again
+	Spill SpillConfig `mapstructure:"spill"`
+
+	// DeadLetter stores the records that cannot be converted and the rows Kinetica
+	// rejects instead of failing the whole batch
+	DeadLetter DeadLetterConfig `mapstructure:"dead_letter"`
+
+	// WriteOrdering controls the order the tables of a signal are written in
+	WriteOrdering WriteOrderingConfig `mapstructure:"write_ordering"`
+
+	// CreateTables creates the schema and the missing tables of the signal on start,
+	// with the primary keys the exporter expects. ddl.sql holds the same statements for
+	// the default schema and table names. The columns missing from existing tables,
+	// e.g. tables created by an earlier version, are added on start in any case, a
+	// changed primary key has to be migrated by recreating the table.
+	CreateTables bool `mapstructure:"create_tables"`
+
+	// UpdateOnExistingPk replaces and IgnoreExistingPk skips the rows whose primary key
+	// already exists, so a retried batch does not duplicate the rows written before.
+	// The record ids are derived from the content of the records, the tables must be
+	// created with the primary keys the exporter expects, by create_tables or ddl.sql,
+	// the exporter does not start when a table lacks them.
+	UpdateOnExistingPk bool `mapstructure:"update_on_existing_pk"`
+	IgnoreExistingPk   bool `mapstructure:"ignore_existing_pk"`
+
+	// Retention deletes the logs, spans and metric datapoints older than their retention
+	Retention RetentionConfig `mapstructure:"retention"`
+
+	// Routing writes the records of every resource to a destination chosen by its attributes
+	Routing RoutingConfig `mapstructure:"routing"`
+
+	// Schemas overrides Schema for the tables of a signal
+	Schemas SignalSchemasConfig `mapstructure:"schemas"`
+
+	// TableNames maps the logical table names to the physical tables in Kinetica
+	TableNames TableNamesConfig `mapstructure:"table_names"`
+
+	// InsertMode is records to insert through /insert/records or sql to insert with
+	// parameterized INSERT statements through /execute/sql. A chunk of the sql mode is
+	// one statement of at most SQLRowsPerStatement rows, bytes values are sent as
+	// strings, bytes that are not valid UTF-8 as hex text decoded by UNHEX in the statement.
+	InsertMode          string `mapstructure:"insert_mode"`
+	SQLRowsPerStatement int    `mapstructure:"sql_rows_per_statement"`
+
+	// Encoding of the records insert mode, avro or json. json inserts through the JSON
+	// endpoint, which reports type mismatches per column and accepts map records.
+	Encoding string `mapstructure:"encoding"`
+
+	// Bulk buffers the records in Parquet files that are loaded through KiFS instead
+	// of inserting them, for high-volume backfills
+	Bulk BulkConfig `mapstructure:"bulk"`
+
+	// Chunking limits the size of the insert requests the rows of a table are split into
+	Chunking ChunkingConfig `mapstructure:"chunking"`
+}
+
+// ChunkingConfig - an insert request holds at most MaxRecords rows and at most
+// MaxRequestBytes of estimated encoded size, a single larger row is sent on its own.
+// Tables of small rows, like the attribute tables, fill a request by MaxRecords and
+// tables of large rows, like logs with long bodies, by MaxRequestBytes.
+type ChunkingConfig struct {
+	MaxRecords      int `mapstructure:"max_records"`
+	MaxRequestBytes int `mapstructure:"max_request_bytes"`
+}
+
+// BulkConfig - the records of every table are written to a Parquet file in Directory,
+// which is uploaded to KifsDirectory and loaded once it holds MaxFileSizeMiB of
+// uncompressed rows or is FlushInterval old. A file that fails to load MaxLoadAttempts
+// times is renamed to .failed and no longer loaded. A file whose load was interrupted
+// by a stop is loaded again only when update_on_existing_pk or ignore_existing_pk makes
+// loading it twice harmless, otherwise it is removed.
+type BulkConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Directory       string        `mapstructure:"directory"`
+	KifsDirectory   string        `mapstructure:"kifs_directory"`
+	MaxFileSizeMiB  int64         `mapstructure:"max_file_size_mib"`
+	FlushInterval   time.Duration `mapstructure:"flush_interval"`
+	MaxLoadAttempts int           `mapstructure:"max_load_attempts"`
+}
+
+// SignalSchemasConfig - the schema of the tables of every signal, Schema when empty
+type SignalSchemasConfig struct {
+	Logs    string `mapstructure:"logs"`
+	Traces  string `mapstructure:"traces"`
+	Metrics string `mapstructure:"metrics"`
+}
+
+// TableNamesConfig - the physical name of a table is its override when set, otherwise
+// the logical name, e.g. log or trace_span, between Prefix and Suffix. The mapping
+// applies to the rollup tables of the configured levels, e.g.
+// metric_gauge_datapoint_rollup_1m, the dead letter table and the generated views as well.
+type TableNamesConfig struct {
+	Prefix    string            `mapstructure:"prefix"`
+	Suffix    string            `mapstructure:"suffix"`
+	Overrides map[string]string `mapstructure:"overrides"`
+}
+
+// RoutingConfig - the destination of a resource is the value of the first of Attributes
+// it has, e.g. tenant.id or k8s.namespace.name, otherwise Default, and the configured
+// schema when both are empty. Target is schema or table_prefix. A destination is named
+// Prefix followed by the value in lower case letters, digits and underscores, with a
+// hash of the value appended when it had to be changed. When Allowed is set, values
+// that are not in it are skipped, as are the values whose schema would be one of the
+// configured schemas. The schema target requires Prefix or Allowed, so records are not
+// routed into schemas the exporter does not own. The tables of a new destination are
+// created like the tables of the configured schema, at most MaxDestinations
+// destinations are remembered as bootstrapped. Only the records of a destination whose
+// tables cannot be created fail. Retention reads the destinations from the Kinetica
+// catalog, the schemas or table prefixes named like a destination, and covers every
+// destination bootstrapped before as well.
+type RoutingConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	Attributes      []string `mapstructure:"attributes"`
+	Target          string   `mapstructure:"target"`
+	Default         string   `mapstructure:"default"`
+	Prefix          string   `mapstructure:"prefix"`
+	Allowed         []string `mapstructure:"allowed"`
+	MaxDestinations int      `mapstructure:"max_destinations"`
+}
+
+// RetentionConfig - the retention of every signal, Tables overrides it for the log,
+// trace_span and metric tables, e.g. trace_span: 168h. Expired rows are deleted every
+// Interval, a zero retention keeps rows forever.
+//
+// The log, trace_span and metric datapoint tables created by create_tables are range
+// partitioned on their time column into partitions of PartitionInterval, the partitions
+// of the current and the next interval are added on start and every Interval and the
+// expired partitions are dropped instead of deleting their rows. Child rows and rows
+// outside the partitions are deleted with DELETE statements. SetTTL sets the Kinetica
+// TTL of every table with a retention to the retention in minutes, Kinetica drops a
+// table that is not accessed for its TTL.
+type RetentionConfig struct {
+	Enabled           bool                     `mapstructure:"enabled"`
+	Interval          time.Duration            `mapstructure:"interval"`
+	Logs              time.Duration            `mapstructure:"logs"`
+	Traces            time.Duration            `mapstructure:"traces"`
+	Metrics           time.Duration            `mapstructure:"metrics"`
+	Tables            map[string]time.Duration `mapstructure:"tables"`
+	PartitionInterval time.Duration            `mapstructure:"partition_interval"`
+	SetTTL            bool                     `mapstructure:"set_ttl"`
+}
+
+// WriteOrderingConfig - Mode is concurrent or parent_first, CompensatingDelete deletes
+// the rows already written for records whose child rows failed, parent_first only
+type WriteOrderingConfig struct {
+	Mode               string `mapstructure:"mode"`
+	CompensatingDelete bool   `mapstructure:"compensating_delete"`
+}
+
+// DeadLetterConfig defines where dead letters are stored, File when it is set and
+// otherwise Table in the configured schema, which is created on start
+type DeadLetterConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Table   string `mapstructure:"table"`
+	File    string `mapstructure:"file"`
+}
+
+// LogSamplingConfig - per Tick, the first Initial entries with the same level and message
+// are logged, then every Thereafter-th one
+type LogSamplingConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Tick       time.Duration `mapstructure:"tick"`
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+}
+
+// SpillConfig defines the on-disk buffer for chunks that could not be inserted. Every
+// signal uses its own sub-directory, exporter instances must not share a directory.
+// A zero MaxAge keeps spilled batches until they are replayed.
+type SpillConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Directory      string        `mapstructure:"directory"`
+	MaxSizeMiB     int64         `mapstructure:"max_size_mib"`
+	MaxAge         time.Duration `mapstructure:"max_age"`
+	ReplayInterval time.Duration `mapstructure:"replay_interval"`
+}
+
+// RollupConfig defines the pre-aggregated rollups written for gauge and sum datapoints.
+type RollupConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	Levels        []RollupLevel `mapstructure:"levels"`
+}
+
+// RollupLevel - one rollup resolution and how long its rows are kept, a zero Retention keeps rows forever
+type RollupLevel struct {
+	Resolution time.Duration `mapstructure:"resolution"`
+	Retention  time.Duration `mapstructure:"retention"`
 }
 
 // Validate the config
@@ -52,135 +248,290 @@ func (cfg *Config) Validate() error {
 		return errors.New("Protocol must be either `http` or `https`")
 	}
 
-	return nil
-}
-
-func parseNumber(s string, fallback int) int {
-	v, err := strconv.Atoi(s)
-	if err == nil {
-		return v
+	if _, err := summaryQuantileColumns(cfg.SummaryQuantiles); err != nil {
+		return err
 	}
-	return fallback
-}
 
-func (cfg *Config) createLogger() *zap.Logger {
+	if _, err := newSeverityMapper(cfg.SeverityTextMapping); err != nil {
+		return err
+	}
 
-	if kineticaLogger != nil {
-		return kineticaLogger
+	if err := cfg.LogSampling.Validate(); err != nil {
+		return err
 	}
 
-	var logConfig zap.Config
+	if err := cfg.Spill.Validate(); err != nil {
+		return err
+	}
 
-	yamlFile, _ := os.ReadFile("./config_log_zap.yaml")
-	if err := yaml.Unmarshal(yamlFile, &logConfig); err != nil {
-		fmt.Println("Error in loading log config file ...")
-		fmt.Println(err)
-		kineticaLogger = cfg.createDefaultLogger()
-		return kineticaLogger
+	if err := cfg.DeadLetter.Validate(); err != nil {
+		return err
 	}
-	fmt.Println("Log Config from Yaml file : ", logConfig)
 
-	var (
-		stdout      zapcore.WriteSyncer
-		file        zapcore.WriteSyncer
-		logFilePath *url.URL
-	)
+	if err := cfg.WriteOrdering.Validate(); err != nil {
+		return err
+	}
 
-	for _, path := range logConfig.OutputPaths {
+	if cfg.UpdateOnExistingPk && cfg.IgnoreExistingPk {
+		return errors.New("update_on_existing_pk and ignore_existing_pk cannot both be set")
+	}
 
-		if path == "stdout" || path == "stderror" {
-			stdout = zapcore.AddSync(os.Stdout)
-			fmt.Println("Created stdout syncer ...")
+	if err := cfg.Retention.Validate(); err != nil {
+		return err
+	}
 
-		} else if strings.HasPrefix(path, "lumberjack://") {
-			var err error
-			logFilePath, err = url.Parse(path)
-			fmt.Println("LogFilePath : ", logFilePath)
+	if err := cfg.Routing.Validate(); err != nil {
+		return err
+	}
 
-			if err == nil {
-				filename := strings.TrimLeft(logFilePath.Path, "/")
-				fmt.Println("LogFileName : ", filename)
+	if err := cfg.TableNames.Validate(cfg.logicalTables()); err != nil {
+		return err
+	}
 
-				if filename != "" {
-					q := logFilePath.Query()
-					l := &lumberjack.Logger{
-						Filename:   filename,
-						MaxSize:    parseNumber(q.Get("maxSize"), maxSize),
-						MaxAge:     parseNumber(q.Get("maxAge"), maxAge),
-						MaxBackups: parseNumber(q.Get("maxBackups"), maxBackups),
-						LocalTime:  false,
-						Compress:   false,
-					}
+	switch cfg.InsertMode {
+	case "", InsertModeRecords:
+	case InsertModeSQL:
+		if cfg.SQLRowsPerStatement <= 0 {
+			return errors.New("sql_rows_per_statement must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid insert_mode %q, must be %s or %s", cfg.InsertMode, InsertModeRecords, InsertModeSQL)
+	}
 
-					file = zapcore.AddSync(l)
-					fmt.Println("Created file syncer ...")
-				}
-			}
-		} else {
-			// Unknown output format
-			fmt.Println("Invalid output path specified in config ...")
+	switch cfg.Encoding {
+	case "", EncodingAvro:
+	case EncodingJSON:
+		if cfg.InsertMode == InsertModeSQL {
+			return fmt.Errorf("encoding %s requires insert_mode %s", EncodingJSON, InsertModeRecords)
 		}
+	default:
+		return fmt.Errorf("invalid encoding %q, must be %s or %s", cfg.Encoding, EncodingAvro, EncodingJSON)
+	}
+
+	if err := cfg.Bulk.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Chunking.Validate(); err != nil {
+		return err
+	}
+
+	return cfg.Rollup.Validate()
+}
+
+// Validate the chunking config
+//
+//	@receiver cc
+//	@return error
+func (cc *ChunkingConfig) Validate() error {
+	if cc.MaxRecords <= 0 {
+		return errors.New("chunking max_records must be positive")
 	}
+	if cc.MaxRequestBytes <= 0 {
+		return errors.New("chunking max_request_bytes must be positive")
+	}
+	return nil
+}
 
-	if stdout == nil && file == nil {
-		fmt.Println("Both stdout and file not available; creating default logger ...")
-		return cfg.createDefaultLogger()
+// Validate the log sampling config
+//
+//	@receiver lc
+//	@return error
+func (lc *LogSamplingConfig) Validate() error {
+	if !lc.Enabled {
+		return nil
+	}
+	if lc.Tick <= 0 {
+		return errors.New("log_sampling tick must be positive")
 	}
+	if lc.Initial < 0 || lc.Thereafter < 0 {
+		return errors.New("log_sampling initial and thereafter must not be negative")
+	}
+	return nil
+}
 
-	level := zap.NewAtomicLevelAt(logConfig.Level.Level())
+// Validate the spill config
+//
+//	@receiver sc
+//	@return error
+func (sc *SpillConfig) Validate() error {
+	if !sc.Enabled {
+		return nil
+	}
+	if sc.Directory == "" {
+		return errors.New("spill directory must be set")
+	}
+	if sc.MaxSizeMiB <= 0 {
+		return errors.New("spill max_size_mib must be positive")
+	}
+	if sc.MaxAge < 0 {
+		return errors.New("spill max_age must not be negative")
+	}
+	if sc.ReplayInterval <= 0 {
+		return errors.New("spill replay_interval must be positive")
+	}
+	return nil
+}
 
-	consoleEncoder := zapcore.NewConsoleEncoder(logConfig.EncoderConfig)
-	fileEncoder := zapcore.NewJSONEncoder(logConfig.EncoderConfig)
+// Validate the bulk config
+//
+//	@receiver bc
+//	@return error
+func (bc *BulkConfig) Validate() error {
+	if !bc.Enabled {
+		return nil
+	}
+	if bc.Directory == "" {
+		return errors.New("bulk directory must be set")
+	}
+	if bc.KifsDirectory == "" {
+		return errors.New("bulk kifs_directory must be set")
+	}
+	if bc.MaxFileSizeMiB <= 0 {
+		return errors.New("bulk max_file_size_mib must be positive")
+	}
+	if bc.FlushInterval <= 0 {
+		return errors.New("bulk flush_interval must be positive")
+	}
+	if bc.MaxLoadAttempts <= 0 {
+		return errors.New("bulk max_load_attempts must be positive")
+	}
+	return nil
+}
 
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, stdout, level),
-		zapcore.NewCore(fileEncoder, file, level),
-	)
+// Validate the dead letter config
+//
+//	@receiver dc
+//	@return error
+func (dc *DeadLetterConfig) Validate() error {
+	if dc.Enabled && dc.Table == "" && dc.File == "" {
+		return errors.New("dead_letter requires a table or a file")
+	}
+	return nil
+}
 
-	kineticaLogger = zap.New(core)
-	return kineticaLogger
+// Validate the write ordering config
+//
+//	@receiver wc
+//	@return error
+func (wc *WriteOrderingConfig) Validate() error {
+	switch wc.Mode {
+	case "", WriteOrderingConcurrent:
+		if wc.CompensatingDelete {
+			return fmt.Errorf("write_ordering compensating_delete requires mode %s", WriteOrderingParentFirst)
+		}
+	case WriteOrderingParentFirst:
+	default:
+		return fmt.Errorf("invalid write_ordering mode %q, must be %s or %s", wc.Mode, WriteOrderingConcurrent, WriteOrderingParentFirst)
+	}
+	return nil
 }
 
-// createDefaultLogger
+// Validate the retention config
 //
-//	@receiver cfg
-//	@return *zap.Logger
-func (cfg *Config) createDefaultLogger() *zap.Logger {
-	stdout := zapcore.AddSync(os.Stdout)
-
-	file := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   "./logs/kinetica-exporter.log",
-		MaxSize:    maxSize, // megabytes
-		MaxBackups: maxBackups,
-		MaxAge:     maxAge, // days
-	})
-
-	level := zap.NewAtomicLevelAt(zap.InfoLevel)
-
-	productionCfg := zap.NewProductionEncoderConfig()
-	productionCfg.NameKey = "logger"
-	productionCfg.TimeKey = "ts"
-	productionCfg.MessageKey = "msg"
-	productionCfg.StacktraceKey = "stacktrace"
-	productionCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-
-	developmentCfg := zap.NewDevelopmentEncoderConfig()
-	developmentCfg.NameKey = "logger"
-	developmentCfg.TimeKey = "ts"
-	developmentCfg.MessageKey = "msg"
-	developmentCfg.StacktraceKey = "stacktrace"
-	developmentCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	developmentCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
-
-	consoleEncoder := zapcore.NewConsoleEncoder(developmentCfg)
-	fileEncoder := zapcore.NewJSONEncoder(productionCfg)
-
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, stdout, level),
-		zapcore.NewCore(fileEncoder, file, level),
-	)
-
-	return zap.New(core)
+//	@receiver rc
+//	@return error
+func (rc *RetentionConfig) Validate() error {
+	if !rc.Enabled {
+		return nil
+	}
+	if rc.Interval <= 0 {
+		return errors.New("retention interval must be positive")
+	}
+	if rc.Logs < 0 || rc.Traces < 0 || rc.Metrics < 0 {
+		return errors.New("retention must not be negative")
+	}
+	if rc.PartitionInterval < 0 || rc.PartitionInterval%time.Second != 0 {
+		return fmt.Errorf("invalid retention partition_interval %v, must be a whole number of seconds", rc.PartitionInterval)
+	}
+	for table, retention := range rc.Tables {
+		if _, ok := retentionTables[table]; !ok {
+			return fmt.Errorf("invalid retention table %q", table)
+		}
+		if retention < 0 {
+			return fmt.Errorf("invalid retention %v for table %s", retention, table)
+		}
+	}
+	return nil
+}
+
+// Validate the routing config
+//
+//	@receiver rc
+//	@return error
+func (rc *RoutingConfig) Validate() error {
+	if !rc.Enabled {
+		return nil
+	}
+	if len(rc.Attributes) == 0 {
+		return errors.New("routing requires at least one attribute")
+	}
+	if rc.Target != RoutingTargetSchema && rc.Target != RoutingTargetTablePrefix {
+		return fmt.Errorf("invalid routing target %q, must be %s or %s", rc.Target, RoutingTargetSchema, RoutingTargetTablePrefix)
+	}
+	if rc.MaxDestinations <= 0 {
+		return errors.New("routing max_destinations must be positive")
+	}
+	if !validDestination(rc.Prefix) {
+		return fmt.Errorf("invalid routing prefix %q, must be lower case letters, digits and underscores", rc.Prefix)
+	}
+	if rc.Target == RoutingTargetSchema && rc.Prefix == "" && len(rc.Allowed) == 0 {
+		return errors.New("routing to schemas requires a prefix or allowed destinations")
+	}
+	for _, name := range rc.Allowed {
+		if sanitizeDestination(name) != name {
+			return fmt.Errorf("invalid allowed routing destination %q, must be lower case letters, digits and underscores", name)
+		}
+	}
+	return nil
+}
+
+// Validate the table names config, logicalTables are the tables an override may map
+//
+//	@receiver tc
+//	@param logicalTables
+//	@return error
+func (tc *TableNamesConfig) Validate(logicalTables map[string]bool) error {
+	for logical, physical := range tc.Overrides {
+		if !logicalTables[logical] {
+			return fmt.Errorf("invalid table_names override %q, not a table written by the exporter", logical)
+		}
+		if physical == "" {
+			return fmt.Errorf("table_names override of %s must not be empty", logical)
+		}
+	}
+	return nil
+}
+
+// Validate the rollup config
+//
+//	@receiver rc
+//	@return error
+func (rc *RollupConfig) Validate() error {
+	if !rc.Enabled {
+		return nil
+	}
+	if rc.FlushInterval <= 0 {
+		return errors.New("rollup flush_interval must be positive")
+	}
+	if len(rc.Levels) == 0 {
+		return errors.New("rollup requires at least one level")
+	}
+	seen := make(map[string]bool, len(rc.Levels))
+	for _, level := range rc.Levels {
+		if level.Resolution <= 0 || level.Resolution%time.Second != 0 {
+			return fmt.Errorf("invalid rollup resolution %v, must be a positive whole number of seconds", level.Resolution)
+		}
+		if level.Retention < 0 {
+			return fmt.Errorf("invalid rollup retention %v for resolution %v", level.Retention, level.Resolution)
+		}
+		suffix := rollupSuffix(level.Resolution)
+		if seen[suffix] {
+			return fmt.Errorf("duplicate rollup resolution %v", level.Resolution)
+		}
+		seen[suffix] = true
+	}
+	return nil
 }
 
 var _ component.Config = (*Config)(nil)
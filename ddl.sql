@@ -0,0 +1,659 @@
+-- Generated by TestDDL, run the tests with -update to regenerate
+-- The tables of every signal in the default otel schema, as created by create_tables
+-- without retention, with retention the time tables are range partitioned on their time column
+
+CREATE SCHEMA IF NOT EXISTS "otel";
+
+CREATE TABLE IF NOT EXISTS "otel"."log"
+(
+	"log_id" UUID NOT NULL,
+	"trace_id" VARCHAR(32),
+	"span_id" VARCHAR(16),
+	"time_unix_nano" BIGINT NOT NULL,
+	"observed_time_unix_nano" BIGINT NOT NULL,
+	"severity_id" TINYINT NOT NULL,
+	"severity_text" VARCHAR NOT NULL,
+	"severity_level" VARCHAR,
+	"body" VARCHAR NOT NULL,
+	"flags" INTEGER NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("log_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."log_attribute"
+(
+	"log_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("log_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."log_resource_attribute"
+(
+	"log_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("log_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."log_scope_attribute"
+(
+	"log_id" UUID NOT NULL,
+	"scope_name" VARCHAR NOT NULL,
+	"scope_version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("log_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."trace_span"
+(
+	"id" UUID NOT NULL,
+	"trace_id" VARCHAR(32) NOT NULL,
+	"span_id" VARCHAR(16) NOT NULL,
+	"parent_span_id" VARCHAR(16),
+	"trace_state" VARCHAR NOT NULL,
+	"name" VARCHAR NOT NULL,
+	"span_kind" TINYINT NOT NULL,
+	"start_time_unix_nano" BIGINT NOT NULL,
+	"end_time_unix_nano" BIGINT NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"dropped_events_count" INTEGER NOT NULL,
+	"dropped_links_count" INTEGER NOT NULL,
+	"message" VARCHAR NOT NULL,
+	"status_code" TINYINT NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."trace_span_attribute"
+(
+	"span_id" VARCHAR(16) NOT NULL,
+	"span_record_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("span_record_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."trace_resource_attribute"
+(
+	"span_id" VARCHAR(16) NOT NULL,
+	"span_record_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("span_record_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."trace_scope_attribute"
+(
+	"span_id" VARCHAR(16) NOT NULL,
+	"span_record_id" UUID NOT NULL,
+	"scope_name" VARCHAR NOT NULL,
+	"scope_version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("span_record_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."trace_event_attribute"
+(
+	"span_id" VARCHAR(16) NOT NULL,
+	"span_record_id" UUID NOT NULL,
+	"event_index" INTEGER NOT NULL,
+	"event_name" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("span_record_id", "event_index", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."trace_link_attribute"
+(
+	"link_span_id" VARCHAR(16) NOT NULL,
+	"span_record_id" UUID NOT NULL,
+	"link_index" INTEGER NOT NULL,
+	"trace_id" VARCHAR(32) NOT NULL,
+	"span_id" VARCHAR(16) NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("span_record_id", "link_index", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge"
+(
+	"gauge_id" UUID NOT NULL,
+	"metric_name" VARCHAR NOT NULL,
+	"metric_description" VARCHAR NOT NULL,
+	"metric_unit" VARCHAR NOT NULL,
+	"job" VARCHAR NOT NULL,
+	"instance" VARCHAR NOT NULL,
+	PRIMARY KEY ("gauge_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge_datapoint"
+(
+	"gauge_id" UUID NOT NULL,
+	"id" UUID NOT NULL,
+	"start_time_unix" BIGINT NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"gauge_value" DOUBLE NOT NULL,
+	"flags" INTEGER NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge_resource_attribute"
+(
+	"gauge_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("gauge_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge_scope_attribute"
+(
+	"gauge_id" UUID NOT NULL,
+	"name" VARCHAR NOT NULL,
+	"version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("gauge_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge_datapoint_attribute"
+(
+	"gauge_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("datapoint_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge_datapoint_exemplar"
+(
+	"gauge_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"gauge_value" DOUBLE NOT NULL,
+	"trace_id" VARCHAR(32),
+	"span_id" VARCHAR(16),
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("exemplar_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_gauge_datapoint_exemplar_attribute"
+(
+	"gauge_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("exemplar_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum"
+(
+	"sum_id" UUID NOT NULL,
+	"metric_name" VARCHAR NOT NULL,
+	"metric_description" VARCHAR NOT NULL,
+	"metric_unit" VARCHAR NOT NULL,
+	"aggregation_temporality" TINYINT NOT NULL,
+	"is_monotonic" TINYINT NOT NULL,
+	"job" VARCHAR NOT NULL,
+	"instance" VARCHAR NOT NULL,
+	PRIMARY KEY ("sum_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum_datapoint"
+(
+	"sum_id" UUID NOT NULL,
+	"id" UUID NOT NULL,
+	"start_time_unix" BIGINT NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"sum_value" DOUBLE NOT NULL,
+	"flags" INTEGER NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum_resource_attribute"
+(
+	"sum_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("sum_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum_scope_attribute"
+(
+	"sum_id" UUID NOT NULL,
+	"name" VARCHAR NOT NULL,
+	"version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("sum_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum_datapoint_attribute"
+(
+	"sum_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("datapoint_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum_datapoint_exemplar"
+(
+	"sum_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"sum_value" DOUBLE NOT NULL,
+	"trace_id" VARCHAR(32),
+	"span_id" VARCHAR(16),
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("exemplar_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_sum_datapoint_exemplar_attribute"
+(
+	"sum_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("exemplar_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram"
+(
+	"histogram_id" UUID NOT NULL,
+	"metric_name" VARCHAR NOT NULL,
+	"metric_description" VARCHAR NOT NULL,
+	"metric_unit" VARCHAR NOT NULL,
+	"aggregation_temporality" TINYINT NOT NULL,
+	"job" VARCHAR NOT NULL,
+	"instance" VARCHAR NOT NULL,
+	PRIMARY KEY ("histogram_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_datapoint"
+(
+	"histogram_id" UUID NOT NULL,
+	"id" UUID NOT NULL,
+	"start_time_unix" BIGINT NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"count" BIGINT NOT NULL,
+	"data_sum" DOUBLE NOT NULL,
+	"data_min" DOUBLE NOT NULL,
+	"data_max" DOUBLE NOT NULL,
+	"flags" INTEGER NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_resource_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("histogram_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_scope_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"name" VARCHAR NOT NULL,
+	"version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("histogram_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_datapoint_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("datapoint_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_datapoint_bucket_count"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"count_id" UUID NOT NULL,
+	"count" BIGINT NOT NULL,
+	PRIMARY KEY ("count_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_datapoint_explicit_bound"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"bound_id" UUID NOT NULL,
+	"explicit_bound" DOUBLE NOT NULL,
+	PRIMARY KEY ("bound_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_datapoint_exemplar"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"histogram_value" DOUBLE NOT NULL,
+	"trace_id" VARCHAR(32),
+	"span_id" VARCHAR(16),
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("exemplar_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_histogram_datapoint_exemplar_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("exemplar_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram"
+(
+	"histogram_id" UUID NOT NULL,
+	"metric_name" VARCHAR NOT NULL,
+	"metric_description" VARCHAR NOT NULL,
+	"metric_unit" VARCHAR NOT NULL,
+	"aggregation_temporality" TINYINT NOT NULL,
+	"job" VARCHAR NOT NULL,
+	"instance" VARCHAR NOT NULL,
+	PRIMARY KEY ("histogram_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_datapoint"
+(
+	"histogram_id" UUID NOT NULL,
+	"id" UUID NOT NULL,
+	"start_time_unix" BIGINT NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"count" BIGINT NOT NULL,
+	"data_sum" DOUBLE NOT NULL,
+	"data_min" DOUBLE NOT NULL,
+	"data_max" DOUBLE NOT NULL,
+	"flags" INTEGER NOT NULL,
+	"scale" INTEGER NOT NULL,
+	"zero_count" BIGINT NOT NULL,
+	"buckets_positive_offset" INTEGER NOT NULL,
+	"buckets_negative_offset" INTEGER NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_resource_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("histogram_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_scope_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"name" VARCHAR NOT NULL,
+	"version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("histogram_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_datapoint_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("datapoint_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_datapoint_bucket_positive_count"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"count_id" UUID NOT NULL,
+	"count" BIGINT NOT NULL,
+	PRIMARY KEY ("count_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_datapoint_bucket_negative_count"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"count_id" UUID NOT NULL,
+	"count" BIGINT NOT NULL,
+	PRIMARY KEY ("count_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_datapoint_exemplar"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"histogram_value" DOUBLE NOT NULL,
+	"trace_id" VARCHAR(32),
+	"span_id" VARCHAR(16),
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("exemplar_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_exp_histogram_datapoint_exemplar_attribute"
+(
+	"histogram_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"exemplar_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("exemplar_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_summary"
+(
+	"summary_id" UUID NOT NULL,
+	"metric_name" VARCHAR NOT NULL,
+	"metric_description" VARCHAR NOT NULL,
+	"metric_unit" VARCHAR NOT NULL,
+	"job" VARCHAR NOT NULL,
+	"instance" VARCHAR NOT NULL,
+	PRIMARY KEY ("summary_id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_summary_datapoint"
+(
+	"summary_id" UUID NOT NULL,
+	"id" UUID NOT NULL,
+	"start_time_unix" BIGINT NOT NULL,
+	"time_unix" BIGINT NOT NULL,
+	"count" BIGINT NOT NULL,
+	"data_sum" DOUBLE NOT NULL,
+	"flags" INTEGER NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_summary_resource_attribute"
+(
+	"summary_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("summary_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_summary_scope_attribute"
+(
+	"summary_id" UUID NOT NULL,
+	"name" VARCHAR NOT NULL,
+	"version" VARCHAR NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"schema_url" VARCHAR NOT NULL,
+	"dropped_attributes_count" INTEGER NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("summary_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_summary_datapoint_attribute"
+(
+	"summary_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"key" VARCHAR(256) NOT NULL,
+	"int_value" INTEGER NOT NULL,
+	"string_value" VARCHAR NOT NULL,
+	"bool_value" TINYINT NOT NULL,
+	"double_value" DOUBLE NOT NULL,
+	"bytes_value" BLOB NOT NULL,
+	PRIMARY KEY ("datapoint_id", "key")
+);
+
+CREATE TABLE IF NOT EXISTS "otel"."metric_summary_datapoint_quantile_values"
+(
+	"summary_id" UUID NOT NULL,
+	"datapoint_id" UUID NOT NULL,
+	"quantile_id" UUID NOT NULL,
+	"quantile" DOUBLE NOT NULL,
+	"value" DOUBLE NOT NULL,
+	PRIMARY KEY ("quantile_id")
+);
@@ -0,0 +1,295 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SQL statements to manage the dead letter table
+const (
+	CreateDeadLetterTable string = `CREATE TABLE IF NOT EXISTS %s
+	(
+		dead_letter_id UUID (primary_key) NOT NULL,
+		signal VARCHAR (16) NOT NULL,
+		table_name VARCHAR (256) NOT NULL,
+		error VARCHAR NOT NULL,
+		record VARCHAR NOT NULL,
+		time_unix TIMESTAMP NOT NULL
+	)`
+)
+
+// DeadLetter - a record that could not be converted or was rejected by Kinetica.
+// Record holds the OTLP JSON of the telemetry record for conversion failures and the
+// JSON of the rejected row for insert failures.
+type DeadLetter struct {
+	DeadLetterID string `avro:"dead_letter_id" json:"dead_letter_id"`
+	Signal       string `avro:"signal" json:"signal"`
+	TableName    string `avro:"table_name" json:"table_name"`
+	Error        string `avro:"error" json:"error"`
+	Record       string `avro:"record" json:"record"`
+	TimeUnix     int64  `avro:"time_unix" json:"time_unix"`
+}
+
+// NewDeadLetter Constructor for DeadLetter
+//
+//	@param DeadLetterID
+//	@param Signal
+//	@param TableName
+//	@param Error
+//	@param Record
+//	@param TimeUnix
+//	@return *DeadLetter
+func NewDeadLetter(DeadLetterID string, Signal string, TableName string, Error string, Record string, TimeUnix int64) *DeadLetter {
+	o := new(DeadLetter)
+	o.DeadLetterID = DeadLetterID
+	o.Signal = Signal
+	o.TableName = TableName
+	o.Error = Error
+	o.Record = Record
+	o.TimeUnix = TimeUnix
+	return o
+}
+
+// deadLetterQueue stores dead letters of one signal in the dead letter table, or as
+// JSON lines in a local file when one is configured
+type deadLetterQueue struct {
+	cfg    DeadLetterConfig
+	signal string
+	writer *KiWriter
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newDeadLetterQueue
+//
+//	@param cfg
+//	@param signal
+//	@param writer
+//	@param logger
+//	@return *deadLetterQueue
+//	@return error
+func newDeadLetterQueue(cfg DeadLetterConfig, signal string, writer *KiWriter, logger *zap.Logger) (*deadLetterQueue, error) {
+	q := &deadLetterQueue{
+		cfg:    cfg,
+		signal: signal,
+		writer: writer,
+		logger: logger,
+	}
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open dead letter file %s: %w", cfg.File, err)
+		}
+		q.file = file
+	}
+	return q, nil
+}
+
+// start creates the dead letter table when dead letters are stored in Kinetica
+//
+//	@receiver q
+//	@param ctx
+//	@return error
+func (q *deadLetterQueue) start(ctx context.Context) error {
+	if q.file != nil {
+		return nil
+	}
+	statement := fmt.Sprintf(CreateDeadLetterTable, q.writer.qualifiedTableName(q.cfg.Table))
+	_, err := q.writer.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil)
+	return err
+}
+
+// shutdown closes the dead letter file
+//
+//	@receiver q
+//	@param ctx
+//	@return error
+func (q *deadLetterQueue) shutdown(ctx context.Context) error {
+	if q.file == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
+
+// add stores the records that failed for a table with the error that caused it
+//
+//	@receiver q
+//	@param ctx
+//	@param table
+//	@param cause
+//	@param records
+//	@return error
+func (q *deadLetterQueue) add(ctx context.Context, table string, cause error, records ...[]byte) error {
+	now := time.Now().UnixMilli()
+	deadLetters := make([]any, 0, len(records))
+	for _, record := range records {
+		deadLetters = append(deadLetters, *NewDeadLetter(uuid.New().String(), q.signal, table, cause.Error(), string(record), now))
+	}
+
+	if q.file == nil {
+		return q.writer.insertChunk(ctx, q.cfg.Table, deadLetters)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	encoder := json.NewEncoder(q.file)
+	for _, deadLetter := range deadLetters {
+		if err := encoder.Encode(deadLetter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enableDeadLetter - creates the dead letter queue of a signal when it is configured
+//
+//	@receiver kiwriter
+//	@param signal
+//	@return error
+func (kiwriter *KiWriter) enableDeadLetter(signal string) error {
+	if !kiwriter.cfg.DeadLetter.Enabled {
+		return nil
+	}
+	deadLetter, err := newDeadLetterQueue(kiwriter.cfg.DeadLetter, signal, kiwriter, kiwriter.logger)
+	if err != nil {
+		return err
+	}
+	kiwriter.deadLetter = deadLetter
+	return nil
+}
+
+// deadLetterRecord - stores the OTLP JSON of a record that could not be converted, the
+// cause is returned when there is no dead letter queue or the record cannot be stored
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param table
+//	@param cause
+//	@param record
+//	@return error
+func (kiwriter *KiWriter) deadLetterRecord(ctx context.Context, table string, cause error, record func() ([]byte, error)) error {
+	if kiwriter.deadLetter == nil {
+		return cause
+	}
+	data, err := record()
+	if err != nil {
+		return multierr.Append(cause, err)
+	}
+	if err := kiwriter.deadLetter.add(ctx, kiwriter.routedTableName(table), cause, data); err != nil {
+		return multierr.Append(cause, err)
+	}
+	kiwriter.logger.Warn("Stored record in the dead letter queue", zap.String("Table", table), zap.Error(cause))
+	return nil
+}
+
+// deadLetterChunk - stores the rows of a chunk Kinetica rejected, the cause is returned
+// when there is no dead letter queue or the rows cannot be stored
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param data
+//	@param cause
+//	@return error
+func (kiwriter *KiWriter) deadLetterChunk(ctx context.Context, tableName string, data []any, cause error) error {
+	if kiwriter.deadLetter == nil {
+		return cause
+	}
+
+	records := make([][]byte, 0, len(data))
+	for _, record := range data {
+		columns, err := recordColumns(record)
+		if err != nil {
+			return multierr.Append(cause, err)
+		}
+		row, err := json.Marshal(columns)
+		if err != nil {
+			return multierr.Append(cause, err)
+		}
+		records = append(records, row)
+	}
+	if err := kiwriter.deadLetter.add(ctx, kiwriter.routedTableName(tableName), cause, records...); err != nil {
+		return multierr.Append(cause, err)
+	}
+	kiwriter.logger.Warn("Stored rejected chunk in the dead letter queue", zap.String("Table", tableName), zap.Int("Record count", len(data)), zap.Error(cause))
+	return nil
+}
+
+// otlpLogJSON - the OTLP JSON of a single log record with its resource and scope
+//
+//	@param resource
+//	@param schemaURL
+//	@param scope
+//	@param scopeURL
+//	@param logRecord
+//	@return []byte
+//	@return error
+func otlpLogJSON(resource pcommon.Resource, schemaURL string, scope pcommon.InstrumentationScope, scopeURL string, logRecord plog.LogRecord) ([]byte, error) {
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resource.CopyTo(resourceLogs.Resource())
+	resourceLogs.SetSchemaUrl(schemaURL)
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	scope.CopyTo(scopeLogs.Scope())
+	scopeLogs.SetSchemaUrl(scopeURL)
+	logRecord.CopyTo(scopeLogs.LogRecords().AppendEmpty())
+	return (&plog.JSONMarshaler{}).MarshalLogs(logs)
+}
+
+// otlpSpanJSON - the OTLP JSON of a single span with its resource and scope
+//
+//	@param resource
+//	@param schemaURL
+//	@param scope
+//	@param scopeURL
+//	@param span
+//	@return []byte
+//	@return error
+func otlpSpanJSON(resource pcommon.Resource, schemaURL string, scope pcommon.InstrumentationScope, scopeURL string, span ptrace.Span) ([]byte, error) {
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resource.CopyTo(resourceSpans.Resource())
+	resourceSpans.SetSchemaUrl(schemaURL)
+	scopeSpans := resourceSpans.ScopeSpans().AppendEmpty()
+	scope.CopyTo(scopeSpans.Scope())
+	scopeSpans.SetSchemaUrl(scopeURL)
+	span.CopyTo(scopeSpans.Spans().AppendEmpty())
+	return (&ptrace.JSONMarshaler{}).MarshalTraces(traces)
+}
+
+// otlpMetricJSON - the OTLP JSON of a single metric with its resource and scope
+//
+//	@param resource
+//	@param schemaURL
+//	@param scope
+//	@param scopeURL
+//	@param metric
+//	@return []byte
+//	@return error
+func otlpMetricJSON(resource pcommon.Resource, schemaURL string, scope pcommon.InstrumentationScope, scopeURL string, metric pmetric.Metric) ([]byte, error) {
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	resource.CopyTo(resourceMetrics.Resource())
+	resourceMetrics.SetSchemaUrl(schemaURL)
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+	scope.CopyTo(scopeMetrics.Scope())
+	scopeMetrics.SetSchemaUrl(scopeURL)
+	metric.CopyTo(scopeMetrics.Metrics().AppendEmpty())
+	return (&pmetric.JSONMarshaler{}).MarshalMetrics(metrics)
+}
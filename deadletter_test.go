@@ -0,0 +1,100 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// newDeadLetterExporter - a logs exporter storing dead letters in a file when one is
+// given and otherwise in the dead letter table of a fake Kinetica
+//
+//	@param t
+//	@param server
+//	@param file
+//	@return exporter.Logs
+func newDeadLetterExporter(t *testing.T, server *kineticatest.Server, file string) exporter.Logs {
+	t.Helper()
+	if err := server.CreateTable("otel."+DeadLetterTable, kineticatest.TypeSchemaOf(DeadLetter{}), nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg := newTestConfig(server)
+	cfg.DeadLetter.Enabled = true
+	cfg.DeadLetter.File = file
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+	return exporter
+}
+
+// testLogs - logs with the severity texts
+//
+//	@param severities
+//	@return plog.Logs
+func testLogs(severities ...string) plog.Logs {
+	logs := plog.NewLogs()
+	scopeLogs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for i, severity := range severities {
+		logRecord := scopeLogs.LogRecords().AppendEmpty()
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, int64(i))))
+		logRecord.SetSeverityText(severity)
+	}
+	return logs
+}
+
+func TestDeadLetterStoresRejectedRows(t *testing.T) {
+	server := newTestServer(t)
+	server.RejectRows(func(table string, row map[string]any) bool {
+		return table == "otel."+LogTable && row["severity_text"] == "rejected"
+	})
+	exporter := newDeadLetterExporter(t, server, "")
+
+	if err := exporter.ConsumeLogs(context.Background(), testLogs("inserted", "rejected", "inserted")); err != nil {
+		t.Fatalf("ConsumeLogs() = %v, want the rejected row dead-lettered", err)
+	}
+
+	if got := len(server.Rows("otel." + LogTable)); got != 2 {
+		t.Errorf("%s has %d rows, want 2", LogTable, got)
+	}
+	deadLetters := server.Rows("otel." + DeadLetterTable)
+	if len(deadLetters) != 1 {
+		t.Fatalf("%s has %d rows, want the rejected row", DeadLetterTable, len(deadLetters))
+	}
+	if record := deadLetters[0]["record"].(string); !strings.Contains(record, `"severity_text":"rejected"`) {
+		t.Errorf("dead letter record = %s, want the rejected row", record)
+	}
+	if table := deadLetters[0]["table_name"]; table != LogTable {
+		t.Errorf("dead letter table_name = %v, want %s", table, LogTable)
+	}
+}
+
+func TestDeadLetterSkipsUnavailableKinetica(t *testing.T) {
+	for _, status := range []int{http.StatusBadGateway, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := newTestServer(t)
+			file := filepath.Join(t.TempDir(), "dead_letter.jsonl")
+			exporter := newDeadLetterExporter(t, server, file)
+
+			server.Unavailable(status)
+			if err := exporter.ConsumeLogs(context.Background(), testLogs("inserted")); err == nil {
+				t.Error("ConsumeLogs() to an unavailable Kinetica succeeded")
+			}
+
+			if data, err := os.ReadFile(file); err != nil || len(data) != 0 {
+				t.Errorf("dead letter file = %q, %v, want it empty for an unavailable Kinetica", data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,601 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// newTestServer - a fake Kinetica with every table of the exporter in the otel schema
+//
+//	@param t
+//	@return *kineticatest.Server
+func newTestServer(t *testing.T) *kineticatest.Server {
+	t.Helper()
+	server := kineticatest.NewServer(t)
+	for table, record := range tableRecords {
+		properties := make(map[string][]string)
+		for _, column := range primaryKeyColumns[table] {
+			properties[column] = []string{primaryKeyProperty}
+		}
+		if err := server.CreateTable("otel."+table, kineticatest.TypeSchemaOf(record), properties); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return server
+}
+
+// newTestConfig - the default config writing to a fake Kinetica
+//
+//	@param server
+//	@return *Config
+func newTestConfig(server *kineticatest.Server) *Config {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Host = server.URL
+	return cfg
+}
+
+// startExporter starts an exporter and shuts it down when the test ends
+//
+//	@param t
+//	@param exporter
+func startExporter(t *testing.T, exporter component.Component) {
+	t.Helper()
+	if err := exporter.Start(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := exporter.Shutdown(context.Background()); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// assertRows checks the rows of a table, ignoring the columns of generated ids which
+// are checked to reference their parent row instead
+//
+//	@param t
+//	@param server
+//	@param table
+//	@param ignore
+//	@param want
+func assertRows(t *testing.T, server *kineticatest.Server, table string, ignore []string, want []map[string]any) {
+	t.Helper()
+	got := server.Rows("otel." + table)
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d rows, want %d: %v", table, len(got), len(want), got)
+	}
+	remaining := append([]map[string]any(nil), got...)
+	for _, wantRow := range want {
+		found := -1
+		for i, gotRow := range remaining {
+			if rowMatches(gotRow, wantRow, ignore) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			t.Fatalf("%s: no row matches %#v in %#v", table, wantRow, got)
+		}
+		remaining = append(remaining[:found], remaining[found+1:]...)
+	}
+}
+
+// rowMatches
+//
+//	@param got
+//	@param want
+//	@param ignore
+//	@return bool
+func rowMatches(got map[string]any, want map[string]any, ignore []string) bool {
+	if len(got) != len(want)+len(ignore) {
+		return false
+	}
+	for column, value := range want {
+		if gotValue, ok := got[column]; !ok || !equalValue(gotValue, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValue compares a decoded column value to the expected value, integers of any
+// size are equal when their values are
+//
+//	@param got
+//	@param want
+//	@return bool
+func equalValue(got any, want any) bool {
+	if gotBytes, ok := got.([]byte); ok {
+		wantBytes, ok := want.([]byte)
+		return ok && string(gotBytes) == string(wantBytes)
+	}
+	gotValue, wantValue := reflect.ValueOf(got), reflect.ValueOf(want)
+	if gotValue.CanInt() && wantValue.CanInt() {
+		return gotValue.Int() == wantValue.Int()
+	}
+	return got == want
+}
+
+// assertReferences checks that every row of a table references an id of the parent rows
+//
+//	@param t
+//	@param server
+//	@param table
+//	@param column
+//	@param parent
+//	@param parentColumn
+func assertReferences(t *testing.T, server *kineticatest.Server, table string, column string, parent string, parentColumn string) {
+	t.Helper()
+	ids := make(map[any]bool)
+	for _, row := range server.Rows("otel." + parent) {
+		ids[row[parentColumn]] = true
+	}
+	for _, row := range server.Rows("otel." + table) {
+		if !ids[row[column]] {
+			t.Errorf("%s.%s %v references no %s.%s", table, column, row[column], parent, parentColumn)
+		}
+	}
+}
+
+func TestLogsExporter(t *testing.T) {
+	server := newTestServer(t)
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), newTestConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.SetSchemaUrl("https://opentelemetry.io/schemas/1.20.0")
+	resourceLogs.Resource().Attributes().PutStr("service.name", "checkout")
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	scopeLogs.Scope().SetName("io.opentelemetry.checkout")
+	scopeLogs.Scope().SetVersion("1.2.3")
+	logRecord := scopeLogs.LogRecords().AppendEmpty()
+	logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	logRecord.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000001, 0)))
+	logRecord.SetSeverityNumber(plog.SeverityNumberWarn)
+	logRecord.SetSeverityText("WARN")
+	logRecord.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	logRecord.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	logRecord.Body().SetStr("payment declined")
+	logRecord.Attributes().PutStr("http.method", "POST")
+	logRecord.Attributes().PutInt("http.status_code", 402)
+
+	if err := exporter.ConsumeLogs(context.Background(), logs); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRows(t, server, LogTable, []string{"log_id"}, []map[string]any{{
+		"trace_id":                 "0102030405060708090a0b0c0d0e0f10",
+		"span_id":                  "0102030405060708",
+		"time_unix_nano":           int64(1700000000),
+		"observed_time_unix_nano":  int64(1700000001),
+		"severity_id":              int(13),
+		"severity_text":            "WARN",
+		"severity_level":           "WARN",
+		"body":                     "",
+		"flags":                    int64(0),
+		"dropped_attributes_count": int64(0),
+	}})
+	assertRows(t, server, LogAttributeTable, []string{"log_id"}, []map[string]any{
+		{"key": "http.method", "string_value": "POST", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+		{"key": "http.status_code", "string_value": "", "bool_value": int(0), "int_value": int64(402), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	assertRows(t, server, LogResourceAttributeTable, []string{"log_id"}, []map[string]any{
+		{"key": "service.name", "schema_url": "https://opentelemetry.io/schemas/1.20.0", "dropped_attributes_count": int64(0),
+			"string_value": "checkout", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	assertRows(t, server, LogScopeAttributeTable, []string{"log_id"}, []map[string]any{
+		{"key": "", "scope_name": "io.opentelemetry.checkout", "scope_version": "1.2.3", "schema_url": "", "dropped_attributes_count": int64(0),
+			"string_value": "", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	for _, table := range []string{LogAttributeTable, LogResourceAttributeTable, LogScopeAttributeTable} {
+		assertReferences(t, server, table, "log_id", LogTable, "log_id")
+	}
+}
+
+func TestLogsExporterStreamsFullChunks(t *testing.T) {
+	for _, mode := range []string{WriteOrderingConcurrent, WriteOrderingParentFirst} {
+		t.Run(mode, func(t *testing.T) {
+			server := newTestServer(t)
+			cfg := newTestConfig(server)
+			cfg.WriteOrdering.Mode = mode
+			exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			startExporter(t, exporter)
+
+			count := 2*ChunkSize + 1
+			logs := plog.NewLogs()
+			scopeLogs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+			for i := 0; i < count; i++ {
+				logRecord := scopeLogs.LogRecords().AppendEmpty()
+				logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, int64(i))))
+				logRecord.Attributes().PutInt("sequence", int64(i))
+			}
+
+			if err := exporter.ConsumeLogs(context.Background(), logs); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, table := range []string{LogTable, LogAttributeTable} {
+				if got := len(server.Rows("otel." + table)); got != count {
+					t.Errorf("%s has %d rows, want %d", table, got, count)
+				}
+			}
+
+			// the full chunks are sent while converting, so the attributes of the first
+			// chunk are inserted before the last log rows are
+			inserts := server.Inserts()
+			firstAttributes, lastLogs := -1, -1
+			for i, table := range inserts {
+				switch table {
+				case "otel." + LogAttributeTable:
+					if firstAttributes < 0 {
+						firstAttributes = i
+					}
+				case "otel." + LogTable:
+					lastLogs = i
+				}
+			}
+			if firstAttributes < 0 || firstAttributes > lastLogs {
+				t.Errorf("inserts %v were not streamed", inserts)
+			}
+			assertReferences(t, server, LogAttributeTable, "log_id", LogTable, "log_id")
+		})
+	}
+}
+
+func TestLogsExporterChunksByRequestBytes(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newTestConfig(server)
+	cfg.Chunking.MaxRequestBytes = 4096
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	logs := plog.NewLogs()
+	scopeLogs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for i := 0; i < 10; i++ {
+		logRecord := scopeLogs.LogRecords().AppendEmpty()
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, int64(i))))
+		logRecord.Attributes().PutStr("stacktrace", strings.Repeat("x", 1000))
+	}
+
+	if err := exporter.ConsumeLogs(context.Background(), logs); err != nil {
+		t.Fatal(err)
+	}
+
+	// about 1KiB per attribute row, at most three rows per request
+	attributeInserts := 0
+	for _, table := range server.Inserts() {
+		if table == "otel."+LogAttributeTable {
+			attributeInserts++
+		}
+	}
+	if attributeInserts != 4 {
+		t.Errorf("%s got %d inserts, want 4", LogAttributeTable, attributeInserts)
+	}
+	if got := len(server.Rows("otel." + LogAttributeTable)); got != 10 {
+		t.Errorf("%s has %d rows, want 10", LogAttributeTable, got)
+	}
+}
+
+func TestLogsExporterIsolatesRejectedRows(t *testing.T) {
+	for _, mode := range []string{WriteOrderingConcurrent, WriteOrderingParentFirst} {
+		t.Run(mode, func(t *testing.T) {
+			server := newTestServer(t)
+			server.RejectRows(func(table string, row map[string]any) bool {
+				return table == "otel."+LogAttributeTable && (row["int_value"] == 37 || row["int_value"] == 12)
+			})
+			cfg := newTestConfig(server)
+			cfg.WriteOrdering.Mode = mode
+			exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			startExporter(t, exporter)
+
+			logs := plog.NewLogs()
+			scopeLogs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+			for i := 0; i < 100; i++ {
+				logRecord := scopeLogs.LogRecords().AppendEmpty()
+				logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, int64(i))))
+				logRecord.Attributes().PutInt("sequence", int64(i))
+			}
+
+			// without a dead letter table the rejected rows fail the push
+			if err := exporter.ConsumeLogs(context.Background(), logs); err == nil {
+				t.Error("ConsumeLogs() of rejected rows succeeded")
+			}
+
+			if got := len(server.Rows("otel." + LogTable)); got != 100 {
+				t.Errorf("%s has %d rows, want 100", LogTable, got)
+			}
+			sequences := make(map[int]bool)
+			for _, row := range server.Rows("otel." + LogAttributeTable) {
+				sequences[row["int_value"].(int)] = true
+			}
+			if len(sequences) != 98 || sequences[37] || sequences[12] {
+				t.Errorf("%s has the sequences %v, want all but 12 and 37", LogAttributeTable, sequences)
+			}
+		})
+	}
+}
+
+func TestTracesExporter(t *testing.T) {
+	server := newTestServer(t)
+	exporter, err := NewFactory().CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), newTestConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("service.name", "checkout")
+	scopeSpans := resourceSpans.ScopeSpans().AppendEmpty()
+	scopeSpans.Scope().SetName("io.opentelemetry.checkout")
+	span := scopeSpans.Spans().AppendEmpty()
+	span.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	span.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	span.SetName("POST /pay")
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000002, 0)))
+	span.Status().SetCode(ptrace.StatusCodeError)
+	span.Status().SetMessage("declined")
+	span.Attributes().PutBool("retry", true)
+	event := span.Events().AppendEmpty()
+	event.SetName("exception")
+	event.Attributes().PutDouble("amount", 12.5)
+	link := span.Links().AppendEmpty()
+	link.SetTraceID(pcommon.TraceID{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+	link.SetSpanID(pcommon.SpanID{8, 7, 6, 5, 4, 3, 2, 1})
+	link.Attributes().PutStr("link.kind", "follows_from")
+	// an event and a link whose attributes were all dropped
+	event = span.Events().AppendEmpty()
+	event.SetName("retry")
+	event.SetDroppedAttributesCount(2)
+	link = span.Links().AppendEmpty()
+	link.SetTraceID(pcommon.TraceID{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+	link.SetSpanID(pcommon.SpanID{1, 1, 1, 1, 1, 1, 1, 1})
+	link.SetDroppedAttributesCount(3)
+
+	if err := exporter.ConsumeTraces(context.Background(), traces); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRows(t, server, TraceSpanTable, []string{"id"}, []map[string]any{{
+		"trace_id":                 "0102030405060708090a0b0c0d0e0f10",
+		"span_id":                  "0102030405060708",
+		"parent_span_id":           nil,
+		"trace_state":              "",
+		"name":                     "POST /pay",
+		"span_kind":                int(ptrace.SpanKindServer),
+		"start_time_unix_nano":     int64(1700000000000000000),
+		"end_time_unix_nano":       int64(1700000002000000000),
+		"dropped_attributes_count": int64(0),
+		"dropped_events_count":     int64(0),
+		"dropped_links_count":      int64(0),
+		"message":                  "declined",
+		"status_code":              int(ptrace.StatusCodeError),
+	}})
+	assertRows(t, server, TraceSpanAttributeTable, []string{"span_record_id"}, []map[string]any{
+		{"span_id": "0102030405060708", "key": "retry", "string_value": "", "bool_value": int(1), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	assertRows(t, server, TraceEventAttributeTable, []string{"span_record_id"}, []map[string]any{
+		{"span_id": "0102030405060708", "event_index": int(0), "event_name": "exception", "key": "amount", "dropped_attributes_count": int64(0),
+			"string_value": "", "bool_value": int(0), "int_value": int64(0), "double_value": 12.5, "bytes_value": []byte{}},
+		{"span_id": "0102030405060708", "event_index": int(1), "event_name": "retry", "key": "", "dropped_attributes_count": int64(2),
+			"string_value": "", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	assertRows(t, server, TraceLinkAttributeTable, []string{"span_record_id"}, []map[string]any{
+		{"link_span_id": "0102030405060708", "link_index": int(0), "trace_id": "100f0e0d0c0b0a090807060504030201", "span_id": "0807060504030201", "key": "link.kind", "dropped_attributes_count": int64(0),
+			"string_value": "follows_from", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+		{"link_span_id": "0102030405060708", "link_index": int(1), "trace_id": "100f0e0d0c0b0a090807060504030201", "span_id": "0101010101010101", "key": "", "dropped_attributes_count": int64(3),
+			"string_value": "", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	for _, table := range []string{TraceSpanAttributeTable, TraceResourceAttributeTable, TraceScopeAttributeTable, TraceEventAttributeTable} {
+		assertReferences(t, server, table, "span_id", TraceSpanTable, "span_id")
+		assertReferences(t, server, table, "span_record_id", TraceSpanTable, "id")
+	}
+	assertReferences(t, server, TraceLinkAttributeTable, "link_span_id", TraceSpanTable, "span_id")
+	assertReferences(t, server, TraceLinkAttributeTable, "span_record_id", TraceSpanTable, "id")
+}
+
+func TestMetricsExporter(t *testing.T) {
+	server := newTestServer(t)
+	exporter, err := NewFactory().CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), newTestConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	resourceMetrics.Resource().Attributes().PutStr("service.name", "checkout")
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+	scopeMetrics.Scope().SetName("io.opentelemetry.checkout")
+
+	gauge := scopeMetrics.Metrics().AppendEmpty()
+	gauge.SetName("queue.depth")
+	gauge.SetUnit("1")
+	gaugeDatapoint := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gaugeDatapoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	gaugeDatapoint.SetDoubleValue(7.5)
+	gaugeDatapoint.Attributes().PutStr("queue", "payments")
+
+	sum := scopeMetrics.Metrics().AppendEmpty()
+	sum.SetName("payments.total")
+	sum.SetEmptySum().SetIsMonotonic(true)
+	sum.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sumDatapoint := sum.Sum().DataPoints().AppendEmpty()
+	sumDatapoint.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Unix(1699999940, 0)))
+	sumDatapoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	sumDatapoint.SetDoubleValue(99.5)
+
+	if err := exporter.ConsumeMetrics(context.Background(), metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRows(t, server, GaugeDatapointTable, []string{"gauge_id", "id"}, []map[string]any{{
+		"start_time_unix":          int64(0),
+		"time_unix":                int64(1700000000000),
+		"gauge_value":              7.5,
+		"flags":                    int64(0),
+		"dropped_attributes_count": int64(0),
+	}})
+	assertRows(t, server, GaugeDatapointAttributeTable, []string{"gauge_id", "datapoint_id"}, []map[string]any{
+		{"key": "queue", "string_value": "payments", "bool_value": int(0), "int_value": int64(0), "double_value": float64(0), "bytes_value": []byte{}},
+	})
+	assertRows(t, server, SumDatapointTable, []string{"sum_id", "id"}, []map[string]any{{
+		"start_time_unix":          int64(1699999940000),
+		"time_unix":                int64(1700000000000),
+		"sum_value":                99.5,
+		"flags":                    int64(0),
+		"dropped_attributes_count": int64(0),
+	}})
+	assertReferences(t, server, GaugeDatapointTable, "gauge_id", GaugeTable, "gauge_id")
+	assertReferences(t, server, GaugeDatapointAttributeTable, "datapoint_id", GaugeDatapointTable, "id")
+	assertReferences(t, server, GaugeResourceAttributeTable, "gauge_id", GaugeTable, "gauge_id")
+	assertReferences(t, server, SumDatapointTable, "sum_id", SumTable, "sum_id")
+	assertReferences(t, server, SumResourceAttributeTable, "sum_id", SumTable, "sum_id")
+}
+
+func TestMetricsExporterWritesMetricsAfterUnsupportedMetric(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newTestConfig(server)
+	// the first gauge is flushed before the unsupported metric
+	cfg.Chunking.MaxRecords = 1
+	exporter, err := NewFactory().CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	metrics := pmetric.NewMetrics()
+	scopeMetrics := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"before", "unsupported", "after"} {
+		metric := scopeMetrics.AppendEmpty()
+		metric.SetName(name)
+		if name == "unsupported" {
+			continue
+		}
+		datapoint := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+		datapoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+		datapoint.SetDoubleValue(1)
+	}
+
+	if err := exporter.ConsumeMetrics(context.Background(), metrics); err == nil {
+		t.Error("ConsumeMetrics() of an unsupported metric succeeded")
+	}
+
+	var names []string
+	for _, row := range server.Rows("otel." + GaugeTable) {
+		names = append(names, row["metric_name"].(string))
+	}
+	sort.Strings(names)
+	if want := []string{"after", "before"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("%s has metrics %v, want %v", GaugeTable, names, want)
+	}
+	if got := len(server.Rows("otel." + GaugeDatapointTable)); got != 2 {
+		t.Errorf("%s has %d rows, want 2", GaugeDatapointTable, got)
+	}
+}
+
+func TestScopeWithoutStoredAttributesHasAPlaceholderRow(t *testing.T) {
+	tests := []struct {
+		signal string
+		table  string
+		push   func(exporter component.Component, scope func(pcommon.InstrumentationScope)) error
+	}{
+		{SignalLogs, LogScopeAttributeTable, func(exporter component.Component, scope func(pcommon.InstrumentationScope)) error {
+			logs := testLogs("info")
+			scope(logs.ResourceLogs().At(0).ScopeLogs().At(0).Scope())
+			return exporter.(interface {
+				ConsumeLogs(context.Context, plog.Logs) error
+			}).ConsumeLogs(context.Background(), logs)
+		}},
+		{SignalTraces, TraceScopeAttributeTable, func(exporter component.Component, scope func(pcommon.InstrumentationScope)) error {
+			traces := ptrace.NewTraces()
+			scopeSpans := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+			scope(scopeSpans.Scope())
+			span := scopeSpans.Spans().AppendEmpty()
+			span.SetTraceID(pcommon.TraceID([16]byte{1}))
+			span.SetSpanID(pcommon.SpanID([8]byte{1}))
+			span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+			span.SetEndTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000001, 0)))
+			return exporter.(interface {
+				ConsumeTraces(context.Context, ptrace.Traces) error
+			}).ConsumeTraces(context.Background(), traces)
+		}},
+		{SignalMetrics, GaugeScopeAttributeTable, func(exporter component.Component, scope func(pcommon.InstrumentationScope)) error {
+			metrics := pmetric.NewMetrics()
+			scopeMetrics := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+			scope(scopeMetrics.Scope())
+			metric := scopeMetrics.Metrics().AppendEmpty()
+			metric.SetName("queue.depth")
+			datapoint := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+			datapoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+			datapoint.SetIntValue(1)
+			return exporter.(interface {
+				ConsumeMetrics(context.Context, pmetric.Metrics) error
+			}).ConsumeMetrics(context.Background(), metrics)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.signal, func(t *testing.T) {
+			server := newTestServer(t)
+			cfg := newTestConfig(server)
+			factory := NewFactory()
+			var exporter component.Component
+			var err error
+			switch tt.signal {
+			case SignalLogs:
+				exporter, err = factory.CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			case SignalTraces:
+				exporter, err = factory.CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			default:
+				exporter, err = factory.CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			startExporter(t, exporter)
+
+			// the only scope attribute is dropped for its empty key
+			err = tt.push(exporter, func(scope pcommon.InstrumentationScope) {
+				scope.SetName("io.opentelemetry.checkout")
+				scope.Attributes().PutStr("", "dropped")
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the scope name column of the metric tables is name
+			rows := server.Rows("otel." + tt.table)
+			if len(rows) != 1 || rows[0]["key"] != "" || rows[0]["dropped_attributes_count"] != 1 ||
+				(rows[0]["scope_name"] != "io.opentelemetry.checkout" && rows[0]["name"] != "io.opentelemetry.checkout") {
+				t.Errorf("%s has rows %v, want the placeholder row of the scope", tt.table, rows)
+			}
+		})
+	}
+}
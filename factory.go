@@ -3,6 +3,7 @@ package kineticaotelexporter
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/exporter"
@@ -32,6 +33,61 @@ func CreateDefaultConfig() component.Config {
 		Username:           "admin",
 		Password:           "Kinetica1.",
 		BypassSslCertCheck: true,
+		Rollup: RollupConfig{
+			Enabled:       false,
+			FlushInterval: time.Minute,
+			Levels: []RollupLevel{
+				{Resolution: time.Minute, Retention: 7 * 24 * time.Hour},
+				{Resolution: 5 * time.Minute, Retention: 30 * 24 * time.Hour},
+				{Resolution: time.Hour, Retention: 365 * 24 * time.Hour},
+			},
+		},
+		LogSampling: LogSamplingConfig{
+			Enabled:    false,
+			Tick:       time.Second,
+			Initial:    10,
+			Thereafter: 100,
+		},
+		Spill: SpillConfig{
+			Enabled:        false,
+			MaxSizeMiB:     1024,
+			MaxAge:         24 * time.Hour,
+			ReplayInterval: 30 * time.Second,
+		},
+		DeadLetter: DeadLetterConfig{
+			Enabled: false,
+			Table:   DeadLetterTable,
+		},
+		WriteOrdering: WriteOrderingConfig{
+			Mode: WriteOrderingConcurrent,
+		},
+		CreateTables: false,
+		Retention: RetentionConfig{
+			Enabled:           false,
+			Interval:          time.Hour,
+			PartitionInterval: 24 * time.Hour,
+			SetTTL:            false,
+		},
+		Routing: RoutingConfig{
+			Enabled:         false,
+			Target:          RoutingTargetSchema,
+			Prefix:          "otel_",
+			MaxDestinations: 1000,
+		},
+		InsertMode:          InsertModeRecords,
+		SQLRowsPerStatement: 500,
+		Encoding:            EncodingAvro,
+		Bulk: BulkConfig{
+			Enabled:         false,
+			KifsDirectory:   "otel_bulk",
+			MaxFileSizeMiB:  128,
+			FlushInterval:   time.Minute,
+			MaxLoadAttempts: 5,
+		},
+		Chunking: ChunkingConfig{
+			MaxRecords:      ChunkSize,
+			MaxRequestBytes: DefaultMaxRequestBytes,
+		},
 	}
 }
 
@@ -49,7 +105,13 @@ func createLogsExporter(
 ) (exporter.Logs, error) {
 	cf := cfg.(*Config)
 
-	exporter, err := newLogsExporter(set.Logger, cf)
+	telemetry, err := newExporterTelemetry(set.TelemetrySettings, set.ID, SignalLogs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure Kinetica logs exporter: %w", err)
+	}
+
+	logger := newExporterLogger(set.Logger, set.ID, SignalLogs, cf.LogSampling)
+	exporter, err := newLogsExporter(logger, telemetry, cf)
 	if err != nil {
 		return nil, fmt.Errorf("cannot configure Kinetica logs exporter: %w", err)
 	}
@@ -59,6 +121,8 @@ func createLogsExporter(
 		set,
 		cfg,
 		exporter.pushLogsData,
+		exporterhelper.WithStart(exporter.start),
+		exporterhelper.WithShutdown(exporter.shutdown),
 	)
 }
 
@@ -74,7 +138,13 @@ func createTracesExporter(ctx context.Context,
 	cfg component.Config) (exporter.Traces, error) {
 
 	cf := cfg.(*Config)
-	exporter, err := newTracesExporter(set.Logger, cf)
+	telemetry, err := newExporterTelemetry(set.TelemetrySettings, set.ID, SignalTraces)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure Kinetica traces exporter: %w", err)
+	}
+
+	logger := newExporterLogger(set.Logger, set.ID, SignalTraces, cf.LogSampling)
+	exporter, err := newTracesExporter(logger, telemetry, cf)
 	if err != nil {
 		return nil, fmt.Errorf("cannot configure Kinetica traces exporter: %w", err)
 	}
@@ -83,6 +153,8 @@ func createTracesExporter(ctx context.Context,
 		set,
 		cfg,
 		exporter.pushTraceData,
+		exporterhelper.WithStart(exporter.start),
+		exporterhelper.WithShutdown(exporter.shutdown),
 	)
 }
 
@@ -91,7 +163,13 @@ func createMetricsExporter(ctx context.Context,
 	cfg component.Config) (exporter.Metrics, error) {
 
 	cf := cfg.(*Config)
-	exporter, err := newMetricsExporter(set.Logger, cf)
+	telemetry, err := newExporterTelemetry(set.TelemetrySettings, set.ID, SignalMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure Kinetica metrics exporter: %w", err)
+	}
+
+	logger := newExporterLogger(set.Logger, set.ID, SignalMetrics, cf.LogSampling)
+	exporter, err := newMetricsExporter(logger, telemetry, cf)
 	if err != nil {
 		return nil, fmt.Errorf("cannot configure Kinetica metrics exporter: %w", err)
 	}
@@ -100,5 +178,7 @@ func createMetricsExporter(ctx context.Context,
 		set,
 		cfg,
 		exporter.pushMetricsData,
+		exporterhelper.WithStart(exporter.start),
+		exporterhelper.WithShutdown(exporter.shutdown),
 	)
 }
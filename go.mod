@@ -4,16 +4,25 @@ go 1.20
 
 require (
 	github.com/am-kinetica/gpudb-api-go v0.0.11
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20200817004010-026bad9b25d0
 	go.opentelemetry.io/collector/component v0.76.1
 	go.opentelemetry.io/collector/exporter v0.76.1
 	go.uber.org/zap v1.24.0
+)
 
+require (
+	github.com/apache/arrow/go/arrow v0.0.0-20200730104253-651201b0f516 // indirect
+	github.com/apache/thrift v0.14.2 // indirect
+	github.com/klauspost/compress v1.16.5 // indirect
+	github.com/pierrec/lz4/v4 v4.1.8 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
 
 require (
 	github.com/go-resty/resty/v2 v2.7.0 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
-	github.com/hamba/avro v1.8.0 // indirect
+	github.com/hamba/avro v1.8.0
 	github.com/logrusorgru/aurora v2.0.3+incompatible // indirect
 	github.com/ztrue/tracerr v0.3.0 // indirect
 	go.opentelemetry.io/collector v0.76.1 // indirect
@@ -44,8 +53,8 @@ require (
 	go.opentelemetry.io/collector/featuregate v0.76.1 // indirect
 	go.opentelemetry.io/collector/receiver v0.76.1 // indirect
 	go.opentelemetry.io/collector/semconv v0.77.0
-	go.opentelemetry.io/otel v1.14.0 // indirect
-	go.opentelemetry.io/otel/metric v0.37.0 // indirect
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/metric v0.37.0
 	go.opentelemetry.io/otel/trace v1.14.0 // indirect
 	go.uber.org/atomic v1.10.0 // indirect
 	golang.org/x/net v0.9.0 // indirect
@@ -54,8 +63,6 @@ require (
 	google.golang.org/genproto v0.0.0-20230110181048-76db0878b65f // indirect
 	google.golang.org/grpc v1.54.0 // indirect
 	google.golang.org/protobuf v1.30.0 // indirect
-	gopkg.in/natefinch/lumberjack.v2 v2.2.1
-	gopkg.in/yaml.v3 v3.0.1
 )
 
 replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal => ../../internal/coreinternal
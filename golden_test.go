@@ -0,0 +1,161 @@
+package kineticaotelexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden rows in testdata/golden")
+
+// TestGolden pushes every OTLP JSON fixture of testdata/otlp through its exporter and
+// compares the rows written per table to testdata/golden, the signal of a fixture is
+// the prefix of its file name
+func TestGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "otlp", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures in testdata/otlp")
+	}
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			server := newTestServer(t)
+			consumeFixture(t, server, fixture)
+
+			got, err := goldenRows(server)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run the tests with -update to create it", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("rows differ from %s, run the tests with -update if the change is intended\ngot:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// consumeFixture decodes an OTLP JSON fixture and pushes it through the exporter of its signal
+//
+//	@param t
+//	@param server
+//	@param fixture
+func consumeFixture(t *testing.T, server *kineticatest.Server, fixture string) {
+	t.Helper()
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	factory := NewFactory()
+	settings := exportertest.NewNopCreateSettings()
+	cfg := newTestConfig(server)
+
+	signal, _, _ := strings.Cut(filepath.Base(fixture), "_")
+	switch strings.TrimSuffix(signal, ".json") {
+	case "logs":
+		logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exporter, err := factory.CreateLogsExporter(ctx, settings, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		startExporter(t, exporter)
+		if err := exporter.ConsumeLogs(ctx, logs); err != nil {
+			t.Fatal(err)
+		}
+	case "traces":
+		traces, err := (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exporter, err := factory.CreateTracesExporter(ctx, settings, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		startExporter(t, exporter)
+		if err := exporter.ConsumeTraces(ctx, traces); err != nil {
+			t.Fatal(err)
+		}
+	case "metrics":
+		metrics, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exporter, err := factory.CreateMetricsExporter(ctx, settings, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		startExporter(t, exporter)
+		if err := exporter.ConsumeMetrics(ctx, metrics); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("%s: the file name does not start with logs, traces or metrics", fixture)
+	}
+}
+
+// goldenRows - the rows of every table written to, as indented JSON with the tables and
+// the rows of each table in a stable order
+//
+//	@param server
+//	@return []byte
+//	@return error
+func goldenRows(server *kineticatest.Server) ([]byte, error) {
+	tables := make(map[string][]json.RawMessage)
+	for _, table := range server.Tables() {
+		rows := server.Rows(table)
+		if len(rows) == 0 {
+			continue
+		}
+		encoded := make([]json.RawMessage, 0, len(rows))
+		for _, row := range rows {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, data)
+		}
+		sort.Slice(encoded, func(i, j int) bool {
+			return bytes.Compare(encoded[i], encoded[j]) < 0
+		})
+		tables[strings.TrimPrefix(table, "otel.")] = encoded
+	}
+
+	data, err := json.MarshalIndent(tables, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,151 @@
+package kineticatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Load - a load of KiFS files into a table
+type Load struct {
+	Table   string
+	Path    string
+	Options map[string]string
+}
+
+// Files - the paths of the files in KiFS, sorted
+//
+//	@receiver s
+//	@return []string
+func (s *Server) Files() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Loads - the loads of KiFS files that succeeded, in order
+//
+//	@receiver s
+//	@return []Load
+func (s *Server) Loads() []Load {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Load(nil), s.loads...)
+}
+
+// RejectLoads - fails the loads of KiFS files into the tables reject returns true for
+//
+//	@receiver s
+//	@param reject
+func (s *Server) RejectLoads(reject func(table string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectLoad = reject
+}
+
+// createDirectory accepts every KiFS directory, the directories are not kept
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) createDirectory(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, "OK", "")
+}
+
+// uploadFiles keeps the uploaded files by their KiFS path
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) uploadFiles(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		FileNames []string `json:"file_names"`
+		FileData  [][]byte `json:"file_data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONResponse(w, "ERROR", err.Error())
+		return
+	}
+	if len(request.FileNames) != len(request.FileData) {
+		writeJSONResponse(w, "ERROR", fmt.Sprintf("%d file names for %d files", len(request.FileNames), len(request.FileData)))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, name := range request.FileNames {
+		s.files[name] = request.FileData[i]
+	}
+	writeJSONResponse(w, "OK", "")
+}
+
+// deleteFiles deletes files from KiFS
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) deleteFiles(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		FileNames []string `json:"file_names"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONResponse(w, "ERROR", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range request.FileNames {
+		if _, ok := s.files[name]; !ok {
+			writeJSONResponse(w, "ERROR", fmt.Sprintf("File '%s' does not exist", name))
+			return
+		}
+		delete(s.files, name)
+	}
+	writeJSONResponse(w, "OK", "")
+}
+
+// insertFromFiles records the load of KiFS files into an existing table, the rows of
+// the files are not decoded
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) insertFromFiles(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		TableName string            `json:"table_name"`
+		Filepaths []string          `json:"filepaths"`
+		Options   map[string]string `json:"options"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONResponse(w, "ERROR", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tables[request.TableName]; !ok {
+		writeJSONResponse(w, "ERROR", fmt.Sprintf("Table '%s' does not exist", request.TableName))
+		return
+	}
+	if s.rejectLoad != nil && s.rejectLoad(request.TableName) {
+		writeJSONResponse(w, "ERROR", fmt.Sprintf("Load into '%s' rejected", request.TableName))
+		return
+	}
+	for _, filepath := range request.Filepaths {
+		path := strings.TrimPrefix(filepath, "kifs://")
+		if _, ok := s.files[path]; !ok {
+			writeJSONResponse(w, "ERROR", fmt.Sprintf("File '%s' does not exist", path))
+			return
+		}
+		s.loads = append(s.loads, Load{Table: request.TableName, Path: path, Options: request.Options})
+	}
+	writeJSONResponse(w, "OK", "")
+}
@@ -0,0 +1,800 @@
+// Package kineticatest provides an in-process stand-in for the Kinetica endpoints the
+// exporter uses, so the exporter can be tested without a live Kinetica.
+package kineticatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hamba/avro"
+)
+
+// Avro schemas of the requests and responses of the gpudb endpoints, limited to the
+// field types the wire format needs
+var (
+	responseSchema = avro.MustParse(`{"type": "record", "name": "gpudb_response", "fields": [
+		{"name": "status", "type": "string"},
+		{"name": "message", "type": "string"},
+		{"name": "data_type", "type": "string"},
+		{"name": "data", "type": "bytes"},
+		{"name": "data_str", "type": "string"}]}`)
+
+	insertRecordsRequestSchema = avro.MustParse(`{"type": "record", "name": "insert_records_request", "fields": [
+		{"name": "table_name", "type": "string"},
+		{"name": "list", "type": {"type": "array", "items": "bytes"}},
+		{"name": "list_str", "type": {"type": "array", "items": "string"}},
+		{"name": "list_encoding", "type": "string"},
+		{"name": "options", "type": {"type": "map", "values": "string"}}]}`)
+
+	insertRecordsResponseSchema = avro.MustParse(`{"type": "record", "name": "insert_records_response", "fields": [
+		{"name": "record_ids", "type": {"type": "array", "items": "string"}},
+		{"name": "count_inserted", "type": "int"},
+		{"name": "count_updated", "type": "int"},
+		{"name": "info", "type": {"type": "map", "values": "string"}}]}`)
+
+	showTableRequestSchema = avro.MustParse(`{"type": "record", "name": "show_table_request", "fields": [
+		{"name": "table_name", "type": "string"},
+		{"name": "options", "type": {"type": "map", "values": "string"}}]}`)
+
+	showTableResponseSchema = avro.MustParse(`{"type": "record", "name": "show_table_response", "fields": [
+		{"name": "table_name", "type": "string"},
+		{"name": "table_names", "type": {"type": "array", "items": "string"}},
+		{"name": "table_descriptions", "type": {"type": "array", "items": {"type": "array", "items": "string"}}},
+		{"name": "type_ids", "type": {"type": "array", "items": "string"}},
+		{"name": "type_schemas", "type": {"type": "array", "items": "string"}},
+		{"name": "type_labels", "type": {"type": "array", "items": "string"}},
+		{"name": "properties", "type": {"type": "array", "items": {"type": "map", "values": {"type": "array", "items": "string"}}}},
+		{"name": "additional_info", "type": {"type": "array", "items": {"type": "map", "values": "string"}}},
+		{"name": "sizes", "type": {"type": "array", "items": "long"}},
+		{"name": "full_sizes", "type": {"type": "array", "items": "long"}},
+		{"name": "join_sizes", "type": {"type": "array", "items": "double"}},
+		{"name": "total_size", "type": "long"},
+		{"name": "total_full_size", "type": "long"},
+		{"name": "info", "type": {"type": "map", "values": "string"}}]}`)
+
+	executeSQLRequestSchema = avro.MustParse(`{"type": "record", "name": "execute_sql_request", "fields": [
+		{"name": "statement", "type": "string"},
+		{"name": "offset", "type": "long"},
+		{"name": "limit", "type": "long"},
+		{"name": "encoding", "type": "string"},
+		{"name": "request_schema_str", "type": "string"},
+		{"name": "data", "type": {"type": "array", "items": "bytes"}},
+		{"name": "options", "type": {"type": "map", "values": "string"}}]}`)
+
+	executeSQLResponseSchema = avro.MustParse(`{"type": "record", "name": "execute_sql_response", "fields": [
+		{"name": "count_affected", "type": "long"},
+		{"name": "response_schema_str", "type": "string"},
+		{"name": "binary_encoded_response", "type": "bytes"},
+		{"name": "json_encoded_response", "type": "string"},
+		{"name": "total_number_of_records", "type": "long"},
+		{"name": "has_more_records", "type": "boolean"},
+		{"name": "paging_table", "type": "string"},
+		{"name": "info", "type": {"type": "map", "values": "string"}}]}`)
+)
+
+// createTableLike matches the statement the exporter bootstraps routed tables with
+var createTableLike = regexp.MustCompile(`^CREATE TABLE (?:"([^"]*)"\.)?"([^"]*)" LIKE (?:"([^"]*)"\.)?"([^"]*)"$`)
+
+type response struct {
+	Status   string `avro:"status"`
+	Message  string `avro:"message"`
+	DataType string `avro:"data_type"`
+	Data     []byte `avro:"data"`
+	DataStr  string `avro:"data_str"`
+}
+
+type insertRecordsRequest struct {
+	TableName    string            `avro:"table_name"`
+	List         [][]byte          `avro:"list"`
+	ListString   []string          `avro:"list_str"`
+	ListEncoding string            `avro:"list_encoding"`
+	Options      map[string]string `avro:"options"`
+}
+
+type insertRecordsResponse struct {
+	RecordIDs     []string          `avro:"record_ids"`
+	CountInserted int               `avro:"count_inserted"`
+	CountUpdated  int               `avro:"count_updated"`
+	Info          map[string]string `avro:"info"`
+}
+
+type showTableRequest struct {
+	TableName string            `avro:"table_name"`
+	Options   map[string]string `avro:"options"`
+}
+
+type showTableResponse struct {
+	TableName         string                `avro:"table_name"`
+	TableNames        []string              `avro:"table_names"`
+	TableDescriptions [][]string            `avro:"table_descriptions"`
+	TypeIds           []string              `avro:"type_ids"`
+	TypeSchemas       []string              `avro:"type_schemas"`
+	TypeLabels        []string              `avro:"type_labels"`
+	Properties        []map[string][]string `avro:"properties"`
+	AdditionalInfo    []map[string]string   `avro:"additional_info"`
+	Sizes             []int64               `avro:"sizes"`
+	FullSizes         []int64               `avro:"full_sizes"`
+	JoinSizes         []float64             `avro:"join_sizes"`
+	TotalSize         int64                 `avro:"total_size"`
+	TotalFullSize     int64                 `avro:"total_full_size"`
+	Info              map[string]string     `avro:"info"`
+}
+
+type executeSQLRequest struct {
+	Statement     string            `avro:"statement"`
+	Offset        int64             `avro:"offset"`
+	Limit         int64             `avro:"limit"`
+	Encoding      string            `avro:"encoding"`
+	RequestSchema string            `avro:"request_schema_str"`
+	Data          [][]byte          `avro:"data"`
+	Options       map[string]string `avro:"options"`
+}
+
+type executeSQLResponse struct {
+	CountAffected         int64             `avro:"count_affected"`
+	ResponseSchema        string            `avro:"response_schema_str"`
+	BinaryEncodedResponse []byte            `avro:"binary_encoded_response"`
+	JSONEncodedResponse   string            `avro:"json_encoded_response"`
+	TotalNumberOfRecords  int64             `avro:"total_number_of_records"`
+	HasMoreRecords        bool              `avro:"has_more_records"`
+	PagingTable           string            `avro:"paging_table"`
+	Info                  map[string]string `avro:"info"`
+}
+
+// Table - a table of the fake server and the rows inserted into it
+type Table struct {
+	Name       string
+	TypeSchema string
+	Properties map[string][]string
+
+	schema avro.Schema
+	rows   []map[string]any
+
+	// partitionColumn is the column the table is range partitioned on, partitions holds
+	// the minimum and maximum of every partition
+	partitionColumn string
+	partitions      map[string][2]int64
+}
+
+// Server - a fake Kinetica serving /show/table, /insert/records, /insert/records/json,
+// /execute/sql and the KiFS endpoints of bulk loading. Inserted records are decoded with
+// the type schema of their table and kept in memory.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	tables     map[string]*Table
+	statements []string
+	inserts    []string
+	reject     func(table string, row map[string]any) bool
+	// rejectStatement - called before a statement runs without holding mu, so it may
+	// block the statement
+	rejectStatement func(statement string) bool
+	// unavailable - the HTTP status every request is answered with by an HTML error
+	// page, zero serves the requests
+	unavailable int
+	requests    int
+
+	files      map[string][]byte
+	loads      []Load
+	rejectLoad func(table string) bool
+}
+
+// NewServer - a fake Kinetica closed when the test ends
+//
+//	@param t
+//	@return *Server
+func NewServer(t testing.TB) *Server {
+	s := &Server{tables: make(map[string]*Table), files: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/show/table", s.showTable)
+	mux.HandleFunc("/insert/records", s.insertRecords)
+	mux.HandleFunc("/insert/records/json", s.insertRecordsJSON)
+	mux.HandleFunc("/execute/sql", s.executeSQL)
+	mux.HandleFunc("/create/directory", s.createDirectory)
+	mux.HandleFunc("/upload/files", s.uploadFiles)
+	mux.HandleFunc("/delete/files", s.deleteFiles)
+	mux.HandleFunc("/insert/records/fromfiles", s.insertFromFiles)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		status := s.unavailable
+		s.mu.Unlock()
+		if status != 0 {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(status)
+			fmt.Fprintf(w, "<html>\r\n<head><title>%[1]d %[2]s</title></head>\r\n<body>\r\n<center><h1>%[1]d %[2]s</h1></center>\r\n<hr><center>nginx</center>\r\n</body>\r\n</html>\r\n", status, http.StatusText(status))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// CreateTable - creates a table with an Avro type schema, properties maps column names
+// to their column properties, e.g. primary_key
+//
+//	@receiver s
+//	@param name
+//	@param typeSchema
+//	@param properties
+//	@return error
+func (s *Server) CreateTable(name string, typeSchema string, properties map[string][]string) error {
+	schema, err := avro.Parse(typeSchema)
+	if err != nil {
+		return fmt.Errorf("invalid type schema of %s: %w", name, err)
+	}
+	if properties == nil {
+		properties = make(map[string][]string)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[name] = &Table{Name: name, TypeSchema: typeSchema, Properties: properties, schema: schema}
+	return nil
+}
+
+// Tables - the names of the tables, sorted
+//
+//	@receiver s
+//	@return []string
+func (s *Server) Tables() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rows - the rows inserted into a table, in insert order
+//
+//	@receiver s
+//	@param table
+//	@return []map[string]any
+func (s *Server) Rows(table string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tables[table]; ok {
+		return append([]map[string]any(nil), t.rows...)
+	}
+	return nil
+}
+
+// Inserts - the tables of the binary insert requests, in order
+//
+//	@receiver s
+//	@return []string
+func (s *Server) Inserts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.inserts...)
+}
+
+// RejectRows - fails the binary inserts holding a row reject returns true for, without
+// inserting any of their rows like Kinetica does for an invalid value
+//
+//	@receiver s
+//	@param reject
+func (s *Server) RejectRows(reject func(table string, row map[string]any) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject = reject
+}
+
+// RejectStatements - fails the SQL statements reject returns true for. reject is called
+// before the statement runs and may block to hold the statement back.
+//
+//	@receiver s
+//	@param reject
+func (s *Server) RejectStatements(reject func(statement string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectStatement = reject
+}
+
+// Requests - the number of requests received
+//
+//	@receiver s
+//	@return int
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Unavailable - answers every request with an HTML error page of an HTTP status like a
+// proxy in front of an unavailable Kinetica does, zero serves the requests again
+//
+//	@receiver s
+//	@param status
+func (s *Server) Unavailable(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unavailable = status
+}
+
+// Statements - the SQL statements executed, in order
+//
+//	@receiver s
+//	@return []string
+func (s *Server) Statements() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.statements...)
+}
+
+// showTable
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) showTable(w http.ResponseWriter, r *http.Request) {
+	var request showTableRequest
+	if !decodeRequest(w, r, showTableRequestSchema, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	table, ok := s.tables[request.TableName]
+	children := s.children(request.TableName)
+	s.mu.Unlock()
+	if !ok && len(children) > 0 {
+		s.showSchema(w, request.TableName, children)
+		return
+	}
+	if !ok {
+		writeError(w, fmt.Sprintf("Table '%s' does not exist", request.TableName))
+		return
+	}
+
+	writeResponse(w, "show_table_response", showTableResponseSchema, showTableResponse{
+		TableName:         request.TableName,
+		TableNames:        []string{table.Name},
+		TableDescriptions: [][]string{{}},
+		TypeIds:           []string{table.Name},
+		TypeSchemas:       []string{table.TypeSchema},
+		TypeLabels:        []string{table.Name},
+		Properties:        []map[string][]string{table.Properties},
+		AdditionalInfo:    []map[string]string{{}},
+		Sizes:             []int64{int64(len(table.rows))},
+		FullSizes:         []int64{int64(len(table.rows))},
+		JoinSizes:         []float64{0},
+		Info:              map[string]string{},
+	})
+}
+
+// children - the schemas of the tables when name is empty, otherwise the tables of the
+// schema name, sorted. The caller holds s.mu.
+//
+//	@receiver s
+//	@param name
+//	@return []string
+func (s *Server) children(name string) []string {
+	found := make(map[string]bool)
+	for tableName := range s.tables {
+		schema, _, ok := strings.Cut(tableName, ".")
+		if !ok {
+			continue
+		}
+		if name == "" {
+			found[schema] = true
+		} else if schema == name {
+			found[tableName] = true
+		}
+	}
+	names := make([]string, 0, len(found))
+	for child := range found {
+		names = append(names, child)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// showSchema answers /show/table for the empty name with the schemas and for a schema
+// with its tables
+//
+//	@receiver s
+//	@param w
+//	@param name
+//	@param children
+func (s *Server) showSchema(w http.ResponseWriter, name string, children []string) {
+	response := showTableResponse{TableName: name, Info: map[string]string{}}
+	for _, child := range children {
+		description := []string{}
+		if name == "" {
+			description = []string{"SCHEMA"}
+		}
+		response.TableNames = append(response.TableNames, child)
+		response.TableDescriptions = append(response.TableDescriptions, description)
+		response.TypeIds = append(response.TypeIds, "")
+		response.TypeSchemas = append(response.TypeSchemas, "")
+		response.TypeLabels = append(response.TypeLabels, "")
+		response.Properties = append(response.Properties, map[string][]string{})
+		response.AdditionalInfo = append(response.AdditionalInfo, map[string]string{})
+		response.Sizes = append(response.Sizes, 0)
+		response.FullSizes = append(response.FullSizes, 0)
+		response.JoinSizes = append(response.JoinSizes, 0)
+	}
+	writeResponse(w, "show_table_response", showTableResponseSchema, response)
+}
+
+// insertRecords decodes the binary records with the type schema of the table
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) insertRecords(w http.ResponseWriter, r *http.Request) {
+	var request insertRecordsRequest
+	if !decodeRequest(w, r, insertRecordsRequestSchema, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[request.TableName]
+	if !ok {
+		writeError(w, fmt.Sprintf("Table '%s' does not exist", request.TableName))
+		return
+	}
+	rows := make([]map[string]any, 0, len(request.List))
+	for _, record := range request.List {
+		row := make(map[string]any)
+		if err := avro.Unmarshal(table.schema, record, &row); err != nil {
+			writeError(w, fmt.Sprintf("Cannot decode record of %s: %v", request.TableName, err))
+			return
+		}
+		if s.reject != nil && s.reject(request.TableName, row) {
+			writeError(w, fmt.Sprintf("Insertion failed for %s: invalid value in record %d", request.TableName, len(rows)))
+			return
+		}
+		rows = append(rows, row)
+	}
+	inserted, updated := table.insert(rows, request.Options)
+	s.inserts = append(s.inserts, request.TableName)
+
+	writeResponse(w, "insert_records_response", insertRecordsResponseSchema, insertRecordsResponse{
+		RecordIDs:     []string{},
+		CountInserted: inserted,
+		CountUpdated:  updated,
+		Info:          map[string]string{},
+	})
+}
+
+// insert adds rows to the table. With update_on_existing_pk a row replaces the row with
+// its primary key and with ignore_existing_pk it is skipped, otherwise it is added.
+//
+//	@receiver t
+//	@param rows
+//	@param options
+//	@return int
+//	@return int
+func (t *Table) insert(rows []map[string]any, options map[string]string) (int, int) {
+	var primaryKey []string
+	for column, properties := range t.Properties {
+		for _, property := range properties {
+			if property == "primary_key" {
+				primaryKey = append(primaryKey, column)
+			}
+		}
+	}
+	update, ignore := options["update_on_existing_pk"] == "true", options["ignore_existing_pk"] == "true"
+	if len(primaryKey) == 0 || (!update && !ignore) {
+		t.rows = append(t.rows, rows...)
+		return len(rows), 0
+	}
+
+	sort.Strings(primaryKey)
+	key := func(row map[string]any) string {
+		values := make([]any, len(primaryKey))
+		for i, column := range primaryKey {
+			values[i] = row[column]
+		}
+		return fmt.Sprintf("%#v", values)
+	}
+	existing := make(map[string]int, len(t.rows))
+	for i, row := range t.rows {
+		existing[key(row)] = i
+	}
+
+	inserted, updated := 0, 0
+	for _, row := range rows {
+		i, ok := existing[key(row)]
+		switch {
+		case !ok:
+			existing[key(row)] = len(t.rows)
+			t.rows = append(t.rows, row)
+			inserted++
+		case update:
+			t.rows[i] = row
+			updated++
+		}
+	}
+	return inserted, updated
+}
+
+// insertRecordsJSON keeps the JSON objects of the request as rows
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) insertRecordsJSON(w http.ResponseWriter, r *http.Request) {
+	var rows []map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&rows); err != nil {
+		writeJSONResponse(w, "ERROR", err.Error())
+		return
+	}
+
+	tableName := r.URL.Query().Get("table_name")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableName]
+	if !ok {
+		writeJSONResponse(w, "ERROR", fmt.Sprintf("Table '%s' does not exist", tableName))
+		return
+	}
+	table.rows = append(table.rows, rows...)
+	writeJSONResponse(w, "OK", "")
+}
+
+// executeSQL records the statement, CREATE TABLE creates the table when it does not
+// exist, CREATE TABLE ... LIKE creates the table, ALTER TABLE adds columns and adds and
+// deletes range partitions and DELETE deletes the matching rows. JSON requests are the INSERT statements of the sql insert mode.
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) executeSQL(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") == "application/json" {
+		s.executeSQLJSON(w, r)
+		return
+	}
+
+	var request executeSQLRequest
+	if !decodeRequest(w, r, executeSQLRequestSchema, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	s.statements = append(s.statements, request.Statement)
+	rejectStatement := s.rejectStatement
+	s.mu.Unlock()
+	if rejectStatement != nil && rejectStatement(request.Statement) {
+		writeError(w, fmt.Sprintf("Statement rejected: %s", request.Statement))
+		return
+	}
+
+	s.mu.Lock()
+	if match := createTableLike.FindStringSubmatch(request.Statement); match != nil {
+		source, ok := s.tables[tableKey(match[3], match[4])]
+		if !ok {
+			s.mu.Unlock()
+			writeError(w, fmt.Sprintf("Table '%s' does not exist", tableKey(match[3], match[4])))
+			return
+		}
+		s.tables[tableKey(match[1], match[2])] = &Table{Name: tableKey(match[1], match[2]), TypeSchema: source.TypeSchema, Properties: source.Properties, schema: source.schema}
+	}
+	var affected int64
+	var err error
+	if match := createTable.FindStringSubmatch(request.Statement); match != nil {
+		err = s.createTable(match)
+	} else if match := addPartition.FindStringSubmatch(request.Statement); match != nil {
+		err = s.addPartition(match)
+	} else if match := addColumn.FindStringSubmatch(request.Statement); match != nil {
+		err = s.addColumn(match)
+	} else if match := deletePartition.FindStringSubmatch(request.Statement); match != nil {
+		affected, err = s.deletePartition(match)
+	} else if match := deleteRows.FindStringSubmatch(request.Statement); match != nil {
+		affected, err = s.deleteRows(match)
+	}
+	if err != nil {
+		s.mu.Unlock()
+		writeError(w, err.Error())
+		return
+	}
+	s.mu.Unlock()
+
+	writeResponse(w, "execute_sql_response", executeSQLResponseSchema, executeSQLResponse{
+		CountAffected:         affected,
+		BinaryEncodedResponse: []byte{},
+		JSONEncodedResponse:   "{}",
+		Info:                  map[string]string{},
+	})
+}
+
+// executeSQLJSON records the statement and inserts the rows of an INSERT statement, the
+// statement fails as a whole when a row is rejected
+//
+//	@receiver s
+//	@param w
+//	@param r
+func (s *Server) executeSQLJSON(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Statement string            `json:"statement"`
+		Options   map[string]string `json:"options"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONResponse(w, "ERROR", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statements = append(s.statements, request.Statement)
+	match := insertRows.FindStringSubmatch(request.Statement)
+	if match == nil {
+		writeJSONResponse(w, "OK", "")
+		return
+	}
+	tableName := tableKey(match[1], match[2])
+	table, ok := s.tables[tableName]
+	if !ok {
+		writeJSONResponse(w, "ERROR", fmt.Sprintf("Table '%s' does not exist", tableName))
+		return
+	}
+	rows, err := insertStatementRows(match, request.Options["query_parameters"])
+	if err != nil {
+		writeJSONResponse(w, "ERROR", err.Error())
+		return
+	}
+	for i, row := range rows {
+		if s.reject != nil && s.reject(tableName, row) {
+			writeJSONResponse(w, "ERROR", fmt.Sprintf("Insertion failed for %s: invalid value in record %d", tableName, i))
+			return
+		}
+	}
+	table.insert(rows, request.Options)
+	s.inserts = append(s.inserts, tableName)
+	writeJSONResponse(w, "OK", "")
+}
+
+// decodeRequest decodes the Avro request body, answering with an error when it cannot
+//
+//	@param w
+//	@param r
+//	@param schema
+//	@param request
+//	@return bool
+func decodeRequest(w http.ResponseWriter, r *http.Request, schema avro.Schema, request any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = avro.Unmarshal(schema, body, request)
+	}
+	if err != nil {
+		writeError(w, fmt.Sprintf("Cannot decode %s request: %v", r.URL.Path, err))
+		return false
+	}
+	return true
+}
+
+// writeResponse answers OK with the Avro encoded response
+//
+//	@param w
+//	@param dataType
+//	@param schema
+//	@param data
+func writeResponse(w http.ResponseWriter, dataType string, schema avro.Schema, data any) {
+	buf, err := avro.Marshal(schema, data)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	writeWrapper(w, response{Status: "OK", DataType: dataType, Data: buf})
+}
+
+// writeError answers ERROR with a message
+//
+//	@param w
+//	@param message
+func writeError(w http.ResponseWriter, message string) {
+	writeWrapper(w, response{Status: "ERROR", Message: message, DataType: "none", Data: []byte{}})
+}
+
+// writeWrapper
+//
+//	@param w
+//	@param wrapper
+func writeWrapper(w http.ResponseWriter, wrapper response) {
+	buf, err := avro.Marshal(responseSchema, wrapper)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(buf)
+}
+
+// writeJSONResponse answers a JSON endpoint
+//
+//	@param w
+//	@param status
+//	@param message
+func writeJSONResponse(w http.ResponseWriter, status string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status, "message": message, "data_type": "none", "data_str": ""})
+}
+
+// TypeSchemaOf - the Avro type schema of a table storing a record type, from the avro
+// tags of its fields. Embedded structs contribute their fields, pointer fields are nullable.
+//
+//	@param record
+//	@return string
+func TypeSchemaOf(record any) string {
+	var fields []string
+	appendFields(reflect.TypeOf(record), &fields)
+	return fmt.Sprintf(`{"type": "record", "name": "type_name", "fields": [%s]}`, strings.Join(fields, ", "))
+}
+
+// appendFields
+//
+//	@param recordType
+//	@param fields
+func appendFields(recordType reflect.Type, fields *[]string) {
+	for recordType.Kind() == reflect.Pointer {
+		recordType = recordType.Elem()
+	}
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		name := field.Tag.Get("avro")
+		if field.Anonymous && name == "" {
+			appendFields(field.Type, fields)
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		nullable := fieldType.Kind() == reflect.Pointer
+		if nullable {
+			fieldType = fieldType.Elem()
+		}
+		avroType := avroTypeOf(fieldType)
+		if nullable {
+			avroType = fmt.Sprintf(`[%s, "null"]`, avroType)
+		}
+		*fields = append(*fields, fmt.Sprintf(`{"name": %q, "type": %s}`, name, avroType))
+	}
+}
+
+// avroTypeOf - the Avro type Kinetica uses for the column of a Go type
+//
+//	@param fieldType
+//	@return string
+func avroTypeOf(fieldType reflect.Type) string {
+	switch fieldType.Kind() {
+	case reflect.String:
+		return `"string"`
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return `"int"`
+	case reflect.Int64:
+		return `"long"`
+	case reflect.Float32:
+		return `"float"`
+	case reflect.Float64:
+		return `"double"`
+	case reflect.Bool:
+		return `"boolean"`
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return `"bytes"`
+		}
+	}
+	panic(fmt.Sprintf("no Avro type for %s", fieldType))
+}
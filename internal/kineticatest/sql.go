@@ -0,0 +1,457 @@
+package kineticatest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hamba/avro"
+)
+
+// SQL the fake server executes, the INSERT statements of the sql insert mode, the
+// DELETE statements of the exporter's retention and compensating deletes, the CREATE
+// TABLE statements of create_tables and the partitions of the retention
+var (
+	insertRows   = regexp.MustCompile(`(?s)^INSERT INTO (?:"([^"]*)"\.)?"([^"]*)" \(([^)]*)\) VALUES (.+)$`)
+	deleteRows   = regexp.MustCompile(`(?s)^DELETE FROM (?:"([^"]*)"\.)?"([^"]*)" WHERE (.+)$`)
+	compareValue = regexp.MustCompile(`^"?(\w+)"? (<|<=|>|>=|=) (-?\d+)$`)
+	inSelect     = regexp.MustCompile(`(?s)^"?(\w+)"? (NOT )?IN \(SELECT "?(\w+)"? FROM (?:"([^"]*)"\.)?"([^"]*)"(?: WHERE (.+))?\)$`)
+	hexParameter = regexp.MustCompile(`UNHEX\(\$(\d+)\)`)
+	inValues     = regexp.MustCompile(`(?s)^"?(\w+)"? (NOT )?IN \((.*)\)$`)
+
+	createTable      = regexp.MustCompile(`(?s)^CREATE TABLE IF NOT EXISTS (?:"([^"]*)"\.)?"([^"]*)"\s*\((.*?)\)(?:\s*PARTITION BY RANGE \("?(\w+)"?\))?\s*$`)
+	columnDefinition = regexp.MustCompile(`^"?(\w+)"? (\w+)(?: ?\(\d+\))?(?: \(([\w, ]+)\))?( NOT NULL)?$`)
+	primaryKey       = regexp.MustCompile(`^PRIMARY KEY \(([^)]*)\)$`)
+	addPartition     = regexp.MustCompile(`^ALTER TABLE (?:"([^"]*)"\.)?"([^"]*)" ADD PARTITION "?(\w+)"? MIN\((-?\d+)\) MAX\((-?\d+)\)$`)
+	addColumn        = regexp.MustCompile(`^ALTER TABLE (?:"([^"]*)"\.)?"([^"]*)" ADD "?(\w+)"? (\w+)(?:\(\d+\))?( NOT NULL)?(?: DEFAULT ('[^']*'|-?[\d.]+))?$`)
+	deletePartition  = regexp.MustCompile(`^ALTER TABLE (?:"([^"]*)"\.)?"([^"]*)" DELETE PARTITION "?(\w+)"?$`)
+)
+
+// columnAvroTypes - the Avro type Kinetica stores the columns of an SQL type as
+var columnAvroTypes = map[string]string{
+	"UUID":      `"string"`,
+	"VARCHAR":   `"string"`,
+	"TINYINT":   `"int"`,
+	"SMALLINT":  `"int"`,
+	"INTEGER":   `"int"`,
+	"BIGINT":    `"long"`,
+	"TIMESTAMP": `"long"`,
+	"REAL":      `"float"`,
+	"DOUBLE":    `"double"`,
+	"BLOB":      `"bytes"`,
+}
+
+// predicate - whether a row matches a condition
+type predicate func(row map[string]any) bool
+
+// tableKey - the name a table is stored under, schema.table, or table when the
+// statement names no schema
+//
+//	@param schema
+//	@param table
+//	@return string
+func tableKey(schema string, table string) string {
+	if schema == "" {
+		return table
+	}
+	return schema + "." + table
+}
+
+// insertStatementRows - the rows of an INSERT statement, the values are the JSON query
+// parameters bound to the placeholders in column order, the hex parameters of UNHEX
+// placeholders are decoded to the string of their bytes
+//
+//	@param match
+//	@param queryParameters
+//	@return []map[string]any
+//	@return error
+func insertStatementRows(match []string, queryParameters string) ([]map[string]any, error) {
+	var parameters []any
+	if err := json.Unmarshal([]byte(queryParameters), &parameters); err != nil {
+		return nil, fmt.Errorf("cannot decode query_parameters: %w", err)
+	}
+	columns := strings.Split(match[3], ", ")
+	if len(parameters)%len(columns) != 0 {
+		return nil, fmt.Errorf("%d query parameters for %d columns", len(parameters), len(columns))
+	}
+	for _, placeholder := range hexParameter.FindAllStringSubmatch(match[4], -1) {
+		n, _ := strconv.Atoi(placeholder[1])
+		text, ok := parameters[n-1].(string)
+		if !ok {
+			return nil, fmt.Errorf("UNHEX parameter $%d is not a string", n)
+		}
+		bytes, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("UNHEX parameter $%d: %w", n, err)
+		}
+		parameters[n-1] = string(bytes)
+	}
+
+	rows := make([]map[string]any, 0, len(parameters)/len(columns))
+	for i := 0; i < len(parameters); i += len(columns) {
+		row := make(map[string]any, len(columns))
+		for j, column := range columns {
+			row[strings.Trim(column, `"`)] = parameters[i+j]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// createTable creates the table of a CREATE TABLE statement unless it exists, the
+// caller holds s.mu
+//
+//	@receiver s
+//	@param match
+//	@return error
+func (s *Server) createTable(match []string) error {
+	name := tableKey(match[1], match[2])
+	if _, ok := s.tables[name]; ok {
+		return nil
+	}
+	typeSchema, properties, err := createTableStatement(match)
+	if err != nil {
+		return err
+	}
+	schema, err := avro.Parse(typeSchema)
+	if err != nil {
+		return err
+	}
+	s.tables[name] = &Table{Name: name, TypeSchema: typeSchema, Properties: properties, schema: schema,
+		partitionColumn: match[4], partitions: make(map[string][2]int64)}
+	return nil
+}
+
+// addColumn adds a column to a table, the rows of the table get the default of the
+// column or null, the caller holds s.mu
+//
+//	@receiver s
+//	@param match
+//	@return error
+func (s *Server) addColumn(match []string) error {
+	table, ok := s.tables[tableKey(match[1], match[2])]
+	if !ok {
+		return fmt.Errorf("Table '%s' does not exist", tableKey(match[1], match[2]))
+	}
+	record, ok := table.schema.(*avro.RecordSchema)
+	if !ok {
+		return fmt.Errorf("Table '%s' has no record type", table.Name)
+	}
+	column := match[3]
+	for _, field := range record.Fields() {
+		if field.Name() == column {
+			return fmt.Errorf("Column '%s' of table '%s' already exists", column, table.Name)
+		}
+	}
+	avroType, ok := columnAvroTypes[strings.ToUpper(match[4])]
+	if !ok {
+		return fmt.Errorf("unsupported column type: %s", match[4])
+	}
+	if match[5] == "" {
+		avroType = fmt.Sprintf(`[%s, "null"]`, avroType)
+	} else if match[6] == "" {
+		return fmt.Errorf("Column '%s' is NOT NULL without a default", column)
+	}
+
+	var value any
+	if text := match[6]; strings.HasPrefix(text, "'") {
+		value = strings.Trim(text, "'")
+	} else if text != "" {
+		switch avroType {
+		case `"int"`:
+			value, _ = strconv.Atoi(text)
+		case `"long"`:
+			value, _ = strconv.ParseInt(text, 10, 64)
+		case `"float"`:
+			parsed, _ := strconv.ParseFloat(text, 32)
+			value = float32(parsed)
+		default:
+			value, _ = strconv.ParseFloat(text, 64)
+		}
+	}
+
+	var typeSchema map[string]any
+	if err := json.Unmarshal([]byte(table.TypeSchema), &typeSchema); err != nil {
+		return err
+	}
+	var fieldType any
+	if err := json.Unmarshal([]byte(avroType), &fieldType); err != nil {
+		return err
+	}
+	fields, _ := typeSchema["fields"].([]any)
+	typeSchema["fields"] = append(fields, map[string]any{"name": column, "type": fieldType})
+	encoded, err := json.Marshal(typeSchema)
+	if err != nil {
+		return err
+	}
+	schema, err := avro.Parse(string(encoded))
+	if err != nil {
+		return err
+	}
+	table.TypeSchema, table.schema = string(encoded), schema
+	for _, row := range table.rows {
+		row[column] = value
+	}
+	return nil
+}
+
+// addPartition adds a range partition to a partitioned table, the caller holds s.mu
+//
+//	@receiver s
+//	@param match
+//	@return error
+func (s *Server) addPartition(match []string) error {
+	table, ok := s.tables[tableKey(match[1], match[2])]
+	if !ok {
+		return fmt.Errorf("Table '%s' does not exist", tableKey(match[1], match[2]))
+	}
+	if table.partitionColumn == "" {
+		return fmt.Errorf("Table '%s' is not range partitioned", table.Name)
+	}
+	if _, ok := table.partitions[match[3]]; ok {
+		return fmt.Errorf("Partition '%s' of table '%s' already exists", match[3], table.Name)
+	}
+	from, _ := strconv.ParseInt(match[4], 10, 64)
+	to, _ := strconv.ParseInt(match[5], 10, 64)
+	table.partitions[match[3]] = [2]int64{from, to}
+	return nil
+}
+
+// deletePartition deletes a partition of a table together with its rows, the caller
+// holds s.mu
+//
+//	@receiver s
+//	@param match
+//	@return int64
+//	@return error
+func (s *Server) deletePartition(match []string) (int64, error) {
+	table, ok := s.tables[tableKey(match[1], match[2])]
+	if !ok {
+		return 0, fmt.Errorf("Table '%s' does not exist", tableKey(match[1], match[2]))
+	}
+	bounds, ok := table.partitions[match[3]]
+	if !ok {
+		return 0, fmt.Errorf("Partition '%s' of table '%s' does not exist", match[3], table.Name)
+	}
+	delete(table.partitions, match[3])
+
+	kept := table.rows[:0]
+	for _, row := range table.rows {
+		if value, ok := toInt64(row[table.partitionColumn]); !ok || value < bounds[0] || value >= bounds[1] {
+			kept = append(kept, row)
+		}
+	}
+	deleted := int64(len(table.rows) - len(kept))
+	table.rows = kept
+	return deleted, nil
+}
+
+// createTableStatement - the type schema and column properties of a CREATE TABLE
+// statement, columns without NOT NULL are nullable
+//
+//	@param match
+//	@return string
+//	@return map[string][]string
+//	@return error
+func createTableStatement(match []string) (string, map[string][]string, error) {
+	var fields []string
+	properties := make(map[string][]string)
+	for _, definition := range splitTopLevel(match[3], ",") {
+		definition = strings.TrimSpace(definition)
+		if key := primaryKey.FindStringSubmatch(definition); key != nil {
+			for _, column := range strings.Split(key[1], ",") {
+				column = strings.Trim(strings.TrimSpace(column), `"`)
+				properties[column] = append(properties[column], "primary_key")
+			}
+			continue
+		}
+		column := columnDefinition.FindStringSubmatch(definition)
+		if column == nil {
+			return "", nil, fmt.Errorf("unsupported column definition: %s", definition)
+		}
+		avroType, ok := columnAvroTypes[strings.ToUpper(column[2])]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported column type: %s", column[2])
+		}
+		if column[4] == "" {
+			avroType = fmt.Sprintf(`[%s, "null"]`, avroType)
+		}
+		fields = append(fields, fmt.Sprintf(`{"name": %q, "type": %s}`, column[1], avroType))
+		for _, property := range strings.Split(column[3], ",") {
+			if property = strings.TrimSpace(property); property != "" {
+				properties[column[1]] = append(properties[column[1]], property)
+			}
+		}
+	}
+	return fmt.Sprintf(`{"type": "record", "name": "type_name", "fields": [%s]}`, strings.Join(fields, ", ")), properties, nil
+}
+
+// deleteRows deletes the rows matching the WHERE clause of a DELETE statement, the
+// caller holds s.mu
+//
+//	@receiver s
+//	@param match
+//	@return int64
+//	@return error
+func (s *Server) deleteRows(match []string) (int64, error) {
+	table, ok := s.tables[tableKey(match[1], match[2])]
+	if !ok {
+		return 0, fmt.Errorf("Table '%s' does not exist", tableKey(match[1], match[2]))
+	}
+	matches, err := s.condition(match[3])
+	if err != nil {
+		return 0, err
+	}
+
+	kept := table.rows[:0]
+	for _, row := range table.rows {
+		if !matches(row) {
+			kept = append(kept, row)
+		}
+	}
+	deleted := int64(len(table.rows) - len(kept))
+	table.rows = kept
+	return deleted, nil
+}
+
+// condition parses a conjunction of comparisons with a number, IN lists and IN
+// subqueries
+//
+//	@receiver s
+//	@param clause
+//	@return predicate
+//	@return error
+func (s *Server) condition(clause string) (predicate, error) {
+	var predicates []predicate
+	for _, term := range splitTopLevel(clause, " AND ") {
+		p, err := s.term(strings.TrimSpace(term))
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, p)
+	}
+	return func(row map[string]any) bool {
+		for _, p := range predicates {
+			if !p(row) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// term parses one condition of a WHERE clause
+//
+//	@receiver s
+//	@param term
+//	@return predicate
+//	@return error
+func (s *Server) term(term string) (predicate, error) {
+	if match := compareValue.FindStringSubmatch(term); match != nil {
+		column, operator := match[1], match[2]
+		value, _ := strconv.ParseInt(match[3], 10, 64)
+		return func(row map[string]any) bool {
+			actual, ok := toInt64(row[column])
+			if !ok {
+				return false
+			}
+			switch operator {
+			case "<":
+				return actual < value
+			case "<=":
+				return actual <= value
+			case ">":
+				return actual > value
+			case ">=":
+				return actual >= value
+			default:
+				return actual == value
+			}
+		}, nil
+	}
+
+	var column string
+	var negate bool
+	values := make(map[string]bool)
+	if match := inSelect.FindStringSubmatch(term); match != nil {
+		column, negate = match[1], match[2] != ""
+		table, ok := s.tables[tableKey(match[4], match[5])]
+		if !ok {
+			return nil, fmt.Errorf("Table '%s' does not exist", tableKey(match[4], match[5]))
+		}
+		matches := predicate(func(map[string]any) bool { return true })
+		if match[6] != "" {
+			var err error
+			if matches, err = s.condition(match[6]); err != nil {
+				return nil, err
+			}
+		}
+		for _, row := range table.rows {
+			if matches(row) {
+				values[fmt.Sprint(row[match[3]])] = true
+			}
+		}
+	} else if match := inValues.FindStringSubmatch(term); match != nil {
+		column, negate = match[1], match[2] != ""
+		for _, value := range splitTopLevel(match[3], ",") {
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) >= 2 {
+				value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+			}
+			values[value] = true
+		}
+	} else {
+		return nil, fmt.Errorf("unsupported condition: %s", term)
+	}
+
+	return func(row map[string]any) bool {
+		value, ok := row[column]
+		return ok && values[fmt.Sprint(value)] != negate
+	}, nil
+}
+
+// splitTopLevel splits s at sep outside of parentheses and quoted strings
+//
+//	@param s
+//	@param sep
+//	@return []string
+func splitTopLevel(s string, sep string) []string {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			quoted = !quoted
+		case quoted:
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// toInt64 - an integer column value as int64
+//
+//	@param value
+//	@return int64
+//	@return bool
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,75 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// Record encodings of the records insert mode
+const (
+	// EncodingAvro inserts the records Avro binary encoded through /insert/records
+	EncodingAvro = "avro"
+	// EncodingJSON inserts the records as JSON objects through the /insert/records/json endpoint
+	EncodingJSON = "json"
+
+	insertJSONEndpoint = "/insert/records/json"
+)
+
+// encodeJSONRecords - the records as a JSON array of objects keyed by column name. Struct
+// records are keyed by their avro tags, map records are sent as they are, so rows do not
+// need a Go type matching the table.
+//
+//	@param data
+//	@return []byte
+//	@return error
+func encodeJSONRecords(data []any) ([]byte, error) {
+	rows := make([]map[string]any, 0, len(data))
+	for _, record := range data {
+		row, err := recordColumns(record)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return json.Marshal(rows)
+}
+
+// insertJSON inserts a JSON array of records into a table
+//
+//	@receiver c
+//	@param ctx
+//	@param finalTable
+//	@param body
+//	@param options
+//	@return error
+func (c *restClient) insertJSON(ctx context.Context, finalTable string, body []byte, options map[string]string) error {
+	query := url.Values{}
+	query.Set("table_name", finalTable)
+	for key, value := range options {
+		query.Set(key, value)
+	}
+	return c.post(ctx, insertJSONEndpoint, query, body)
+}
+
+// insertChunkJSON - inserts a chunk of records through the JSON insert endpoint
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param data
+//	@return int64
+//	@return error
+func (kiwriter *KiWriter) insertChunkJSON(ctx context.Context, tableName string, data []any) (int64, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	body, err := encodeJSONRecords(data)
+	if err != nil {
+		return 0, err
+	}
+	if err := kiwriter.rest.insertJSON(ctx, kiwriter.finalTableName(tableName), body, kiwriter.restInsertOptions(tableName)); err != nil {
+		return 0, err
+	}
+	return int64(len(body)), nil
+}
@@ -0,0 +1,78 @@
+package kineticaotelexporter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hamba/avro"
+)
+
+// logTypeSchema - the type schema Kinetica reports for the log table
+const logTypeSchema = `{
+	"type": "record",
+	"name": "type_name",
+	"fields": [
+		{"name": "log_id", "type": "string"},
+		{"name": "trace_id", "type": ["string", "null"]},
+		{"name": "span_id", "type": ["string", "null"]},
+		{"name": "time_unix_nano", "type": "long"},
+		{"name": "observed_time_unix_nano", "type": "long"},
+		{"name": "severity_id", "type": "int"},
+		{"name": "severity_text", "type": "string"},
+		{"name": "severity_level", "type": ["string", "null"]},
+		{"name": "body", "type": "string"},
+		{"name": "flags", "type": "int"},
+		{"name": "dropped_attributes_count", "type": "int"}
+	]
+}`
+
+// benchmarkLogs - a chunk of log records as the logs exporter builds them
+//
+//	@param n
+//	@return []any
+func benchmarkLogs(n int) []any {
+	traceID := "5b8efff798038103d269b633813fc60c"
+	spanID := "eee19b7ec3c1b174"
+	severityLevel := "INFO"
+	records := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		records = append(records, *NewLog(fmt.Sprintf("log-%d", i), &traceID, &spanID, 1700000000, 1700000001, 9, "Info", &severityLevel, "", 1, 0))
+	}
+	return records
+}
+
+func BenchmarkEncodeAvro(b *testing.B) {
+	schema, err := avro.Parse(logTypeSchema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	records := benchmarkLogs(ChunkSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var size int
+		for _, record := range records {
+			buf, err := avro.Marshal(schema, record)
+			if err != nil {
+				b.Fatal(err)
+			}
+			size += len(buf)
+		}
+		b.SetBytes(int64(size))
+	}
+}
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	records := benchmarkLogs(ChunkSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body, err := encodeJSONRecords(records)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(body)))
+	}
+}
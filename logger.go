@@ -0,0 +1,28 @@
+package kineticaotelexporter
+
+import (
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newExporterLogger - the collector provided logger with the exporter and signal attached,
+// sampled when log sampling is enabled
+//
+//	@param logger
+//	@param id
+//	@param signal
+//	@param sampling
+//	@return *zap.Logger
+func newExporterLogger(logger *zap.Logger, id component.ID, signal string, sampling LogSamplingConfig) *zap.Logger {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	logger = logger.With(zap.String("exporter", id.String()), zap.String("signal", signal))
+	if !sampling.Enabled {
+		return logger
+	}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, sampling.Tick, sampling.Initial, sampling.Thereafter)
+	}))
+}
@@ -2,10 +2,9 @@ package kineticaotelexporter
 
 import (
 	"context"
-	"encoding/hex"
+	"sync"
 
-	"github.com/google/uuid"
-	"github.com/influxdata/influxdb-observability/common"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/multierr"
@@ -13,37 +12,86 @@ import (
 )
 
 type kineticaLogsExporter struct {
-	logger *zap.Logger
+	logger    *zap.Logger
+	telemetry *exporterTelemetry
 
-	writer *KiWriter
-}
-
-type kineticaLogRecord struct {
-	log               *Log
-	logAttribute      []LogAttribute
-	resourceAttribute []ResourceAttribute
-	scopeAttribute    []ScopeAttribute
+	writer   *KiWriter
+	severity *severityMapper
+	// batches - the pooled log batches the pushes are converted into
+	batches sync.Pool
 }
 
 // newLogsExporter
 //
 //	@param logger
+//	@param telemetry
 //	@param cfg
 //	@return *kineticaLogsExporter
 //	@return error
-func newLogsExporter(logger *zap.Logger, cfg *Config) (*kineticaLogsExporter, error) {
+func newLogsExporter(logger *zap.Logger, telemetry *exporterTelemetry, cfg *Config) (*kineticaLogsExporter, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
-	writer := NewKiWriter(context.TODO(), *cfg, logger)
+	severity, err := newSeverityMapper(cfg.SeverityTextMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := NewKiWriter(context.TODO(), cfg.forSignal(SignalLogs), logger, telemetry)
+	if err := writer.enableDeadLetter(SignalLogs); err != nil {
+		return nil, err
+	}
+	if err := writer.enableSpill(SignalLogs); err != nil {
+		return nil, err
+	}
+	if err := writer.enableBulk(SignalLogs); err != nil {
+		return nil, err
+	}
+	writer.enableRetention(SignalLogs)
+	writer.enableRouting(SignalLogs)
 	logsExp := &kineticaLogsExporter{
-		logger: logger,
-		writer: writer,
+		logger:    logger,
+		telemetry: telemetry,
+		writer:    writer,
+		severity:  severity,
+	}
+	logsExp.batches.New = func() any {
+		return newLogBatch(logger, telemetry)
 	}
 	return logsExp, nil
 }
 
+// start
+//
+//	@receiver e
+//	@param ctx
+//	@param host
+//	@return error
+func (e *kineticaLogsExporter) start(ctx context.Context, host component.Host) error {
+	if e.writer.cfg.CreateTables {
+		if err := e.writer.createTables(ctx, SignalLogs); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.ensureColumns(ctx, signalTables[SignalLogs]); err != nil {
+		return err
+	}
+	if err := e.writer.checkPrimaryKeys(ctx, SignalLogs); err != nil {
+		return err
+	}
+	return e.writer.start(ctx)
+}
+
+// shutdown
+//
+//	@receiver e
+//	@param ctx
+//	@return error
+func (e *kineticaLogsExporter) shutdown(ctx context.Context) error {
+	return e.writer.shutdown(ctx)
+}
+
 // pushLogsData
 //
 //	@receiver e
@@ -51,229 +99,122 @@ func newLogsExporter(logger *zap.Logger, cfg *Config) (*kineticaLogsExporter, er
 //	@param ld
 //	@return error
 func (e *kineticaLogsExporter) pushLogsData(ctx context.Context, logData plog.Logs) error {
-	var errs []error
-	var logRecords []kineticaLogRecord
+	// the data of a destination that cannot be bootstrapped fails, the other
+	// destinations are still written
+	routed, err := e.writer.routeLogs(ctx, logData)
+	errs := []error{err}
+	for writer, data := range routed {
+		if err := e.pushLogs(ctx, writer, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+// pushLogs - converts and writes the logs of one destination
+//
+//	@receiver e
+//	@param ctx
+//	@param writer
+//	@param ld
+//	@return error
+func (e *kineticaLogsExporter) pushLogs(ctx context.Context, writer *KiWriter, logData plog.Logs) error {
+	batch := e.batches.Get().(*logBatch)
+	defer func() {
+		batch.reset()
+		e.batches.Put(batch)
+	}()
 
+	var errs []error
 	resourceLogs := logData.ResourceLogs()
 	for i := 0; i < resourceLogs.Len(); i++ {
 		rl := resourceLogs.At(i)
 		resource := rl.Resource()
+		batch.shared.setResource(batch.converter, resource)
 		scopeLogs := rl.ScopeLogs()
 		for j := 0; j < scopeLogs.Len(); j++ {
 			scopeLog := scopeLogs.At(j)
-			logs := scopeLogs.At(j).LogRecords()
+			batch.shared.setScope(batch.converter, scopeLog.Scope())
+			logs := scopeLog.LogRecords()
 			for k := 0; k < logs.Len(); k++ {
-				if kiLogRecord, err := e.createLogRecord(ctx, resource, scopeLog.Scope(), logs.At(k)); err != nil {
+				if err := e.appendLogRecord(batch, resource, rl.SchemaUrl(), scopeLog.Scope(), scopeLog.SchemaUrl(), logs.At(k)); err != nil {
 					if cerr := ctx.Err(); cerr != nil {
 						return cerr
 					}
 
+					e.telemetry.recordConversionFailure()
+					logRecord := logs.At(k)
+					if err := writer.deadLetterRecord(ctx, LogTable, err, func() ([]byte, error) {
+						return otlpLogJSON(resource, rl.SchemaUrl(), scopeLog.Scope(), scopeLog.SchemaUrl(), logRecord)
+					}); err != nil {
+						errs = append(errs, err)
+					}
+				}
+
+				if err := writer.flushFullRows(ctx, batch.tables); err != nil {
 					errs = append(errs, err)
-				} else {
-					logRecords = append(logRecords, *kiLogRecord)
 				}
 			}
 		}
 	}
 
-	if err := e.writer.persistLogRecord(logRecords); err != nil {
+	// the rows left after the last full chunks
+	if err := writer.writeRows(ctx, batch.tables); err != nil {
 		errs = append(errs, err)
 	}
 	return multierr.Combine(errs...)
 }
 
-// createLogRecord //
+// appendLogRecord - appends the rows of a log record to the batch, the resource and scope
+// attributes are the ones converted for its scope
 //
 //	@receiver e
-//	@param ctx
+//	@param batch
 //	@param resource
-//	@param instrumentationLibrary
+//	@param schemaURL
+//	@param scope
+//	@param scopeURL
 //	@param logRecord
-//	@return *kineticaLogRecord
 //	@return error
-func (e *kineticaLogsExporter) createLogRecord(ctx context.Context, resource pcommon.Resource, instrumentationLibrary pcommon.InstrumentationScope, logRecord plog.LogRecord) (*kineticaLogRecord, error) {
-	var errs []error
-	ts := logRecord.Timestamp().AsTime().Unix()
-	ots := logRecord.ObservedTimestamp().AsTime().Unix()
-
-	tags := make(map[string]string)
-	fields := make(map[string]interface{})
-
-	// TODO handle logRecord.Flags()
-
-	if traceID := logRecord.TraceID(); !traceID.IsEmpty() {
-		tags[AttributeTraceID] = hex.EncodeToString(traceID[:])
-		if spanID := logRecord.SpanID(); !spanID.IsEmpty() {
-			tags[AttributeSpanID] = hex.EncodeToString(spanID[:])
-		}
-	}
-
-	if severityNumber := logRecord.SeverityNumber(); severityNumber != plog.SeverityNumberUnspecified {
-		fields[AttributeSeverityNumber] = int64(severityNumber)
-	}
-	if severityText := logRecord.SeverityText(); severityText != "" {
-		fields[AttributeSeverityText] = severityText
-	}
-
-	if v, err := AttributeValueToKineticaFieldValue(logRecord.Body()); err != nil {
-		e.logger.Debug("invalid log record body", zap.String("Error", err.Error()))
-		fields[AttributeBody] = nil
-	} else {
-		fields[AttributeBody] = v
-	}
-
-	var logAttribute []LogAttribute
-	logAttributes := make(map[string]ValueTypePair)
-	droppedAttributesCount := uint64(logRecord.DroppedAttributesCount())
-	logRecord.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			droppedAttributesCount++
-			e.logger.Debug("log record attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-			droppedAttributesCount++
-			e.logger.Debug("invalid log record attribute value", zap.String("Error", err.Error()))
-		} else {
-			logAttributes[k] = v
-		}
-		return true
+func (e *kineticaLogsExporter) appendLogRecord(batch *logBatch, resource pcommon.Resource, schemaURL string, scope pcommon.InstrumentationScope, scopeURL string, logRecord plog.LogRecord) error {
+	severityText := logRecord.SeverityText()
+	severityNumber, severityLevel := e.severity.severity(logRecord.SeverityNumber(), severityText)
+
+	var droppedAttributesCount int
+	batch.attributes, droppedAttributesCount, _ = batch.converter.convert(batch.attributes[:0], logRecord.Attributes(), "log record", nil)
+	droppedAttributesCount += int(logRecord.DroppedAttributesCount())
+
+	// Body not handled now, the flags carry the W3C trace flags, the sampled bit included
+	logID := logRecordID(resource, scope, logRecord)
+	batch.logs.add(Log{
+		LogID:                  logID,
+		TraceID:                traceIDToHex(logRecord.TraceID()),
+		SpanID:                 spanIDToHex(logRecord.SpanID()),
+		TimeUnixNano:           logRecord.Timestamp().AsTime().Unix(),
+		ObservedTimeUnixNano:   logRecord.ObservedTimestamp().AsTime().Unix(),
+		SeverityID:             int8(severityNumber),
+		SeverityText:           severityText,
+		SeverityLevel:          severityLevel,
+		Flags:                  int(logRecord.Flags()),
+		DroppedAttributesCount: droppedAttributesCount,
 	})
-	if droppedAttributesCount > 0 {
-		fields[common.AttributeDroppedAttributesCount] = droppedAttributesCount
-	}
 
-	severityNumber, ok := (fields[AttributeSeverityNumber]).(int)
-	if !ok {
-		severityNumber = 0
-		e.logger.Warn("severity_number conversion failed, possibly not an integer; storing 0")
+	for _, attribute := range batch.attributes {
+		batch.logAttributes.add(LogAttribute{logID, attribute.key, attribute.value})
 	}
 
-	severityText, ok := (fields[AttributeSeverityText]).(string)
-	if !ok {
-		e.logger.Warn("severity_text conversion failed, possibly not a string; storing empty string")
-		severityText = ""
+	for _, attribute := range batch.shared.resource {
+		batch.resourceAttributes.add(ResourceAttribute{logID, attribute.key, schemaURL, batch.shared.resourceDropped, attribute.value})
 	}
 
-	// create log - Body, dropped_attribute_count and flags not handled now
-	log := NewLog(uuid.New().String(), tags[AttributeTraceID], tags[AttributeSpanID], ts, ots, int8(severityNumber), severityText, "", 0)
-	// _, err := log.insertLog()
-	// errs = append(errs, err)
-
-	// Insert log attributes
-	for key := range logAttributes {
-		vtPair := logAttributes[key]
-		la := newLogAttributeValue(log.LogID, key, vtPair)
-		logAttribute = append(logAttribute, *la)
+	scopeName := scope.Name()
+	scopeVersion := scope.Version()
+	for _, attribute := range batch.shared.scope {
+		batch.scopeAttributes.add(ScopeAttribute{logID, scopeName, scopeVersion, attribute.key, scopeURL, batch.shared.scopeDropped, attribute.value})
 	}
-
-	var resourceAttribute []ResourceAttribute
-	// Insert resource attributes
-	resourceAttributes := make(map[string]ValueTypePair)
-	resource.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("resource attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-			e.logger.Debug("Invalid resource attribute value", zap.String("Error", err.Error()))
-		} else {
-			resourceAttributes[k] = v
-		}
-		return true
-	})
-
-	for key := range resourceAttributes {
-		vtPair := resourceAttributes[key]
-		ra := newResourceAttributeValue(log.LogID, key, vtPair)
-		resourceAttribute = append(resourceAttribute, *ra)
-	}
-
-	// Insert scope attributes
-	var scopeAttribute []ScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
-	scopeName := instrumentationLibrary.Name()
-	scopeVersion := instrumentationLibrary.Version()
-	instrumentationLibrary.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("scope attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-			e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-		} else {
-			scopeAttributes[k] = v
-		}
-		return true
-	})
-
-	for key := range scopeAttributes {
-		vtPair := scopeAttributes[key]
-		sa := newScopeAttributeValue(log.LogID, key, scopeName, scopeVersion, vtPair)
-		scopeAttribute = append(scopeAttribute, *sa)
+	if len(batch.shared.scope) == 0 {
+		// No attributes found - just basic scope
+		batch.scopeAttributes.add(ScopeAttribute{logID, scopeName, scopeVersion, "", scopeURL, batch.shared.scopeDropped, AttributeValue{}})
 	}
-
-	kiLogRecord := new(kineticaLogRecord)
-	kiLogRecord.log = log
-	copy(kiLogRecord.logAttribute, logAttribute)
-	copy(kiLogRecord.resourceAttribute, resourceAttribute)
-	copy(kiLogRecord.scopeAttribute, scopeAttribute)
-
-	return kiLogRecord, multierr.Combine(errs...)
-
-}
-
-// newLogAttributeValue
-//
-//	@param logID
-//	@param key
-//	@param vtPair
-//	@return *LogsAttribute
-func newLogAttributeValue(logID string, key string, vtPair ValueTypePair) *LogAttribute {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-
-	if err != nil {
-		la := NewLogAttribute(logID, key, *av)
-		return la
-	}
-
-	return nil
-}
-
-// newResourceAttributeValue
-//
-//	@param resourceID
-//	@param key
-//	@param vtPair
-//	@return *LogsResourceAttribute
-func newResourceAttributeValue(resourceID string, key string, vtPair ValueTypePair) *ResourceAttribute {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-
-	if err != nil {
-		la := NewResourceAttribute(resourceID, key, *av)
-		return la
-	}
-
-	return nil
-}
-
-// newScopeAttributeValue newResourceAttributeValue
-//
-//	@param key
-//	@param scopeName
-//	@param scopeVersion
-//	@param vtPair
-//	@return *LogsScopeAttribute
-func newScopeAttributeValue(scopeID string, key string, scopeName string, scopeVersion string, vtPair ValueTypePair) *ScopeAttribute {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-
-	if err != nil {
-		sa := NewScopeAttribute(scopeID, key, scopeName, scopeVersion, *av)
-		return sa
-	}
-
 	return nil
 }
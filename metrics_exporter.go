@@ -3,8 +3,9 @@ package kineticaotelexporter
 import (
 	"context"
 	"fmt"
+	"sync"
 
-	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/multierr"
@@ -12,168 +13,219 @@ import (
 )
 
 type kineticaMetricsExporter struct {
-	logger *zap.Logger
+	logger    *zap.Logger
+	telemetry *exporterTelemetry
 
 	writer *KiWriter
-}
-
-// Metrics handling
-
-type kineticaGaugeRecord struct {
-	gauge              *Gauge
-	resourceAttribute  []GaugeResourceAttribute
-	scopeAttribute     []GaugeScopeAttribute
-	datapoint          []GaugeDatapoint
-	datapointAttribute []GaugeDatapointAttribute
-	exemplars          []GaugeDatapointExemplar
-	exemplarAttribute  []GaugeDataPointExemplarAttribute
-}
+	rollup *metricRollup
 
-type kineticaSumRecord struct {
-	sum                  *Sum
-	sumResourceAttribute []SumResourceAttribute
-	sumScopeAttribute    []SumScopeAttribute
-	datapoint            []SumDatapoint
-	datapointAttribute   []SumDataPointAttribute
-	exemplars            []SumDatapointExemplar
-	exemplarAttribute    []SumDataPointExemplarAttribute
-}
-
-type kineticaHistogramRecord struct {
-	histogram                  *Histogram
-	histogramResourceAttribute []HistogramResourceAttribute
-	histogramScopeAttribute    []HistogramScopeAttribute
-	histogramDatapoint         []HistogramDatapoint
-	histogramDatapointAtribute []HistogramDataPointAttribute
-	histogramBucketCount       []HistogramDatapointBucketCount
-	histogramExplicitBound     []HistogramDatapointExplicitBound
-	exemplars                  []HistogramDatapointExemplar
-	exemplarAttribute          []HistogramDataPointExemplarAttribute
-}
+	normalizePrometheus bool
 
-type kineticaExponentialHistogramRecord struct {
-	histogram                    *ExponentialHistogram
-	histogramResourceAttribute   []ExponentialHistogramResourceAttribute
-	histogramScopeAttribute      []ExponentialHistogramScopeAttribute
-	histogramDatapoint           []ExponentialHistogramDatapoint
-	histogramDatapointAttribute  []ExponentialHistogramDataPointAttribute
-	histogramBucketNegativeCount []ExponentialHistogramBucketNegativeCount
-	histogramBucketPositiveCount []ExponentialHistogramBucketPositiveCount
-	exemplars                    []ExponentialHistogramDatapointExemplar
-	exemplarAttribute            []ExponentialHistogramDataPointExemplarAttribute
-}
+	// summaryQuantileColumns maps the quantiles pivoted into summary datapoint columns to their column name
+	summaryQuantileColumns map[float64]string
 
-type kineticaSummaryRecord struct {
-	summary                        *Summary
-	summaryDatapoint               []SummaryDatapoint
-	summaryDatapointAttribute      []SummaryDataPointAttribute
-	summaryResourceAttribute       []SummaryResourceAttribute
-	summaryScopeAttribute          []SummaryScopeAttribute
-	summaryDatapointQuantileValues []SummaryDatapointQuantileValues
+	// batches - the pooled metric batches the pushes are converted into
+	batches sync.Pool
 }
 
-func newMetricsExporter(logger *zap.Logger, cfg *Config) (*kineticaMetricsExporter, error) {
+func newMetricsExporter(logger *zap.Logger, telemetry *exporterTelemetry, cfg *Config) (*kineticaMetricsExporter, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
-	kineticaLogger := cfg.createLogger()
-	writer := NewKiWriter(context.TODO(), *cfg, kineticaLogger)
+	writer := NewKiWriter(context.TODO(), cfg.forSignal(SignalMetrics), logger, telemetry)
+	if err := writer.enableDeadLetter(SignalMetrics); err != nil {
+		return nil, err
+	}
+	if err := writer.enableSpill(SignalMetrics); err != nil {
+		return nil, err
+	}
+	if err := writer.enableBulk(SignalMetrics); err != nil {
+		return nil, err
+	}
+	writer.enableRetention(SignalMetrics)
+	writer.enableRouting(SignalMetrics)
 	metricsExp := &kineticaMetricsExporter{
-		logger: kineticaLogger,
-		writer: writer,
+		logger:              logger,
+		telemetry:           telemetry,
+		writer:              writer,
+		normalizePrometheus: cfg.NormalizePrometheus,
+	}
+	if cfg.Rollup.Enabled {
+		metricsExp.rollup = newMetricRollup(cfg.Rollup, writer, logger)
+	}
+	if len(cfg.SummaryQuantiles) > 0 {
+		quantileColumns, err := summaryQuantileColumns(cfg.SummaryQuantiles)
+		if err != nil {
+			return nil, err
+		}
+		metricsExp.summaryQuantileColumns = quantileColumns
+	}
+	quantileColumnNames := writer.summaryQuantileColumnNames()
+	metricsExp.batches.New = func() any {
+		return newMetricBatch(logger, telemetry, quantileColumnNames)
 	}
 	return metricsExp, nil
 }
 
+// start
+//
+//	@receiver e
+//	@param ctx
+//	@param host
+//	@return error
+func (e *kineticaMetricsExporter) start(ctx context.Context, host component.Host) error {
+	if e.writer.cfg.CreateTables {
+		if err := e.writer.createTables(ctx, SignalMetrics); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.ensureColumns(ctx, signalTables[SignalMetrics]); err != nil {
+		return err
+	}
+	if len(e.summaryQuantileColumns) > 0 {
+		if err := e.writer.ensureSummaryQuantileColumns(ctx, e.writer.summaryQuantileColumnNames()); err != nil {
+			return err
+		}
+	}
+	if e.writer.cfg.ExemplarTraceView {
+		if err := e.writer.createExemplarTraceSpanView(ctx); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.checkPrimaryKeys(ctx, SignalMetrics); err != nil {
+		return err
+	}
+	if err := e.writer.start(ctx); err != nil {
+		return err
+	}
+	if e.rollup != nil {
+		return e.rollup.start(ctx)
+	}
+	return nil
+}
+
+// shutdown
+//
+//	@receiver e
+//	@param ctx
+//	@return error
+func (e *kineticaMetricsExporter) shutdown(ctx context.Context) error {
+	var errs error
+	if e.rollup != nil {
+		errs = multierr.Append(errs, e.rollup.shutdown(ctx))
+	}
+	return multierr.Append(errs, e.writer.shutdown(ctx))
+}
+
+// pushMetricsData - routes the metrics to their destinations and writes them
+//
+//	@receiver e
+//	@param ctx
+//	@param md
+//	@return error
 func (e *kineticaMetricsExporter) pushMetricsData(ctx context.Context, md pmetric.Metrics) error {
-	var metricType pmetric.MetricType
-	var errs []error
+	// the data of a destination that cannot be bootstrapped fails, the other
+	// destinations are still written
+	routed, err := e.writer.routeMetrics(ctx, md)
+	errs := []error{err}
+	for writer, data := range routed {
+		if err := e.pushMetrics(ctx, writer, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multierr.Combine(errs...)
+}
 
-	var gaugeRecords []kineticaGaugeRecord
-	var sumRecords []kineticaSumRecord
-	var histogramRecords []kineticaHistogramRecord
-	var exponentialHistogramRecords []kineticaExponentialHistogramRecord
-	var summaryRecords []kineticaSummaryRecord
+// pushMetrics - converts and writes the metrics of one destination
+//
+//	@receiver e
+//	@param ctx
+//	@param writer
+//	@param md
+//	@return error
+func (e *kineticaMetricsExporter) pushMetrics(ctx context.Context, writer *KiWriter, md pmetric.Metrics) error {
+	batch := e.batches.Get().(*metricBatch)
+	defer func() {
+		batch.reset()
+		e.batches.Put(batch)
+	}()
+
+	var errs []error
+	// per root table, the number of rollups converted when a write of the rows of the
+	// metric type last failed, the rollups of the metrics converted before are not recorded
+	failedRollups := make(map[string]int)
 
 	e.logger.Debug("Resource metrics ", zap.Int("count = ", md.ResourceMetrics().Len()))
 
 	for i := 0; i < md.ResourceMetrics().Len(); i++ {
 		metrics := md.ResourceMetrics().At(i)
-		resAttr := metrics.Resource().Attributes()
-
-		e.logger.Debug("Scope metrics ", zap.Int("count = ", metrics.ScopeMetrics().Len()))
+		resource := metrics.Resource()
+		batch.shared.setResource(batch.converter, resource)
 
 		for j := 0; j < metrics.ScopeMetrics().Len(); j++ {
 			metricSlice := metrics.ScopeMetrics().At(j).Metrics()
 			scopeInstr := metrics.ScopeMetrics().At(j).Scope()
 			scopeURL := metrics.ScopeMetrics().At(j).SchemaUrl()
-
-			e.logger.Debug("metrics ", zap.Int("count = ", metricSlice.Len()))
+			batch.shared.setScope(batch.converter, scopeInstr)
 
 			for k := 0; k < metricSlice.Len(); k++ {
-
 				metric := metricSlice.At(k)
-				metricType = metric.Type()
+				metricName := metric.Name()
+				if e.normalizePrometheus {
+					metricName = normalizePrometheusMetricName(metric)
+				}
+
+				// the rows and rollup datapoints of a metric that cannot be converted are
+				// dropped from the batch
+				rollups := len(batch.rollups)
+				var tables []rowBuffer
+				var rootTable string
+				var err error
 				switch metric.Type() {
 				case pmetric.MetricTypeGauge:
-					gaugeRecord, err := e.createGaugeRecord(resAttr, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Gauge(), metric.Name(), metric.Description(), metric.Unit())
-					if err == nil {
-						gaugeRecords = append(gaugeRecords, *gaugeRecord)
-						e.logger.Debug("Added gauge")
-					} else {
-						e.logger.Error(err.Error())
-						errs = append(errs, err)
-					}
+					tables, rootTable = batch.gauge.tables, GaugeTable
+					markRows(tables)
+					err = e.appendGauge(batch, resource, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Gauge(), metricName, metric.Description(), metric.Unit())
 				case pmetric.MetricTypeSum:
-					sumRecord, err := e.createSumRecord(resAttr, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Sum(), metric.Name(), metric.Description(), metric.Unit())
-					if err == nil {
-						sumRecords = append(sumRecords, *sumRecord)
-						e.logger.Debug("Added sum")
-					} else {
-						e.logger.Error(err.Error())
-						errs = append(errs, err)
-					}
+					tables, rootTable = batch.sum.tables, SumTable
+					markRows(tables)
+					err = e.appendSum(batch, resource, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Sum(), metricName, metric.Description(), metric.Unit())
 				case pmetric.MetricTypeHistogram:
-					histogramRecord, err := e.createHistogramRecord(resAttr, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Histogram(), metric.Name(), metric.Description(), metric.Unit())
-					if err == nil {
-						histogramRecords = append(histogramRecords, *histogramRecord)
-						e.logger.Debug("Added histogram")
-					} else {
-						e.logger.Error(err.Error())
-						errs = append(errs, err)
-					}
+					tables, rootTable = batch.histogram.tables, HistogramTable
+					markRows(tables)
+					err = e.appendHistogram(batch, resource, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Histogram(), metricName, metric.Description(), metric.Unit())
 				case pmetric.MetricTypeExponentialHistogram:
-					exponentialHistogramRecord, err := e.createExponentialHistogramRecord(resAttr, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.ExponentialHistogram(), metric.Name(), metric.Description(), metric.Unit())
-					if err == nil {
-						exponentialHistogramRecords = append(exponentialHistogramRecords, *exponentialHistogramRecord)
-						e.logger.Debug("Added exp histogram")
-					} else {
-						e.logger.Error(err.Error())
-						errs = append(errs, err)
-					}
+					tables, rootTable = batch.exponentialHistogram.tables, ExpHistogramTable
+					markRows(tables)
+					err = e.appendExponentialHistogram(batch, resource, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.ExponentialHistogram(), metricName, metric.Description(), metric.Unit())
 				case pmetric.MetricTypeSummary:
-					summaryRecord, err := e.createSummaryRecord(resAttr, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Summary(), metric.Name(), metric.Description(), metric.Unit())
-					if err == nil {
-						summaryRecords = append(summaryRecords, *summaryRecord)
-						e.logger.Debug("Added summary")
-					} else {
-						e.logger.Error(err.Error())
-						errs = append(errs, err)
-					}
+					tables, rootTable = batch.summary.tables, SummaryTable
+					markRows(tables)
+					err = e.appendSummary(batch, resource, metrics.SchemaUrl(), scopeInstr, scopeURL, metric.Summary(), metricName, metric.Description(), metric.Unit())
 				default:
-					return fmt.Errorf("unsupported metrics type")
+					err = fmt.Errorf("unsupported metrics type %s of metric %s", metric.Type(), metricName)
 				}
 
-				e.logger.Debug("Gague ", zap.Int("count = ", len(gaugeRecords)))
-				e.logger.Debug("Sum ", zap.Int("count = ", len(sumRecords)))
-				e.logger.Debug("Histogram ", zap.Int("count = ", len(histogramRecords)))
-				e.logger.Debug("Exp Histogram ", zap.Int("count = ", len(exponentialHistogramRecords)))
-				e.logger.Debug("Summary ", zap.Int("count = ", len(summaryRecords)))
+				// neither a failed conversion nor a failed write stops the conversion, the
+				// remaining rows are still written
+				if err != nil {
+					rollbackRows(tables)
+					batch.rollups = batch.rollups[:rollups]
+					e.logger.Error(err.Error())
+					e.telemetry.recordConversionFailure()
+					if err := writer.deadLetterRecord(ctx, rootTable, err, func() ([]byte, error) {
+						return otlpMetricJSON(resource, metrics.SchemaUrl(), scopeInstr, scopeURL, metric)
+					}); err != nil {
+						errs = append(errs, err)
+					}
+					continue
+				}
 
-				if errs != nil && len(errs) > 0 {
-					e.logger.Error(multierr.Combine(errs...).Error())
-					return multierr.Combine(errs...)
+				if err := writer.flushFullRows(ctx, tables); err != nil {
+					errs = append(errs, err)
+					failedRollups[rootTable] = len(batch.rollups)
+					e.logger.Error(err.Error())
 				}
 			}
 		}
@@ -181,411 +233,232 @@ func (e *kineticaMetricsExporter) pushMetricsData(ctx context.Context, md pmetri
 
 	e.logger.Debug("Before writing metrics into Kinetica")
 
-	switch metricType {
-	case pmetric.MetricTypeGauge:
-		if err := e.writer.persistGaugeRecord(gaugeRecords); err != nil {
-			errs = append(errs, err)
-			e.logger.Error(err.Error())
-		}
-	case pmetric.MetricTypeSum:
-		if err := e.writer.persistSumRecord(sumRecords); err != nil {
-			errs = append(errs, err)
-			e.logger.Error(err.Error())
-		}
-	case pmetric.MetricTypeHistogram:
-		if err := e.writer.persistHistogramRecord(histogramRecords); err != nil {
+	// a batch can mix metric types, so the rows left in the tables of every type are written
+	for _, metricType := range []struct {
+		rootTable string
+		tables    []rowBuffer
+	}{
+		{GaugeTable, batch.gauge.tables},
+		{SumTable, batch.sum.tables},
+		{HistogramTable, batch.histogram.tables},
+		{ExpHistogramTable, batch.exponentialHistogram.tables},
+		{SummaryTable, batch.summary.tables},
+	} {
+		rootTable, tables := metricType.rootTable, metricType.tables
+		if rowCount(tables) == 0 {
+			continue
+		}
+		if err := writer.writeRows(ctx, tables); err != nil {
 			errs = append(errs, err)
+			failedRollups[rootTable] = len(batch.rollups)
 			e.logger.Error(err.Error())
 		}
-	case pmetric.MetricTypeExponentialHistogram:
-		if err := e.writer.persistExponentialHistogramRecord(exponentialHistogramRecords); err != nil {
-			errs = append(errs, err)
-			e.logger.Error(err.Error())
-		}
-	case pmetric.MetricTypeSummary:
-		if err := e.writer.persistSummaryRecord(summaryRecords); err != nil {
-			errs = append(errs, err)
-			e.logger.Error(err.Error())
-		}
-	default:
-		return fmt.Errorf("unsupported metrics type")
+	}
 
+	// the rollups of the metrics whose rows were written are recorded, the others when
+	// the push is retried, the buckets leave out the datapoints recorded again
+	if e.rollup != nil {
+		e.rollup.record(writer, writtenRollups(batch.rollups, failedRollups))
 	}
 	return multierr.Combine(errs...)
 }
 
-// createSummaryRecord
+// appendGauge - appends the rows of a gauge to the batch
 //
 //	@receiver e
-//	@param resAttr
+//	@param batch
+//	@param resource
 //	@param schemaURL
 //	@param scopeInstr
 //	@param scopeURL
-//	@param summaryRecord
+//	@param gaugeRecord
 //	@param name
 //	@param description
 //	@param unit
-//	@return *kineticaSummaryRecord
 //	@return error
-func (e *kineticaMetricsExporter) createSummaryRecord(resAttr pcommon.Map, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, summaryRecord pmetric.Summary, name, description, unit string) (*kineticaSummaryRecord, error) {
-	var errs []error
-
-	kiSummaryRecord := new(kineticaSummaryRecord)
+func (e *kineticaMetricsExporter) appendGauge(batch *metricBatch, resource pcommon.Resource, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, gaugeRecord pmetric.Gauge, name, description, unit string) error {
+	rows := &batch.gauge
+	resAttr := resource.Attributes()
+
+	job, instance := e.prometheusLabels(resAttr)
+	series := seriesIDBuilder(pmetric.MetricTypeGauge.String(), name, resource, scopeInstr)
+	datapoints := gaugeRecord.DataPoints()
+	datapointIDs := datapointRecordIDs[pmetric.NumberDataPoint](series, datapoints)
+	gaugeID := metricRecordID(series, datapointIDs)
+	rows.gauges.add(Gauge{gaugeID, name, description, unit, job, instance})
+
+	errs := batch.shared.err()
+	for i := 0; i < datapoints.Len(); i++ {
+		datapoint := datapoints.At(i)
+
+		var dropped int
+		var err error
+		batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], datapoint.Attributes(), "gauge datapoint", e.skipDatapointAttribute)
+		errs = multierr.Append(errs, err)
 
-	summary := &Summary{
-		SummaryID:   uuid.New().String(),
-		MetricName:  name,
-		Description: description,
-		Unit:        unit,
-	}
-
-	kiSummaryRecord.summary = summary
-
-	// Handle data points
-	var datapointAttribute []SummaryDataPointAttribute
-	datapointAttributes := make(map[string]ValueTypePair)
-
-	for i := 0; i < summaryRecord.DataPoints().Len(); i++ {
-		datapoint := summaryRecord.DataPoints().At(i)
-		summaryDatapoint := &SummaryDatapoint{
-			SummaryID:     summary.SummaryID,
-			ID:            uuid.New().String(),
-			StartTimeUnix: datapoint.StartTimestamp().AsTime().UnixMilli(),
-			TimeUnix:      datapoint.Timestamp().AsTime().UnixMilli(),
-			Count:         int64(datapoint.Count()),
-			Sum:           datapoint.Sum(),
-			Flags:         int(datapoint.Flags()),
+		gaugeDatapoint := GaugeDatapoint{
+			GaugeID:                gaugeID,
+			ID:                     datapointIDs[i],
+			StartTimeUnix:          datapoint.StartTimestamp().AsTime().UnixMilli(),
+			TimeUnix:               datapoint.Timestamp().AsTime().UnixMilli(),
+			GaugeValue:             numberDatapointValue(datapoint),
+			Flags:                  int(datapoint.Flags()),
+			DroppedAttributesCount: dropped,
 		}
-		kiSummaryRecord.summaryDatapoint = append(kiSummaryRecord.summaryDatapoint, *summaryDatapoint)
-
-		// Handle summary datapoint attribute
-		datapoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Sum record attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				datapointAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid sum record attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
+		rows.datapoints.add(gaugeDatapoint)
 
-		for key := range datapointAttributes {
-			vtPair := datapointAttributes[key]
-			sa, err := e.newSummaryDatapointAttributeValue(summary.SummaryID, summaryDatapoint.ID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				datapointAttribute = append(datapointAttribute, *sa)
-			}
+		if e.rollup != nil {
+			batch.rollups = append(batch.rollups, rollupDatapointOf(GaugeDatapointTable, name, datapoint.Attributes(), resAttr, gaugeDatapoint.TimeUnix, gaugeDatapoint.GaugeValue))
 		}
-		kiSummaryRecord.summaryDatapointAttribute = append(kiSummaryRecord.summaryDatapointAttribute, datapointAttribute...)
 
-		for k := range datapointAttributes {
-			delete(datapointAttributes, k)
+		for _, attribute := range batch.attributes {
+			rows.datapointAttributes.add(GaugeDatapointAttribute{gaugeID, gaugeDatapoint.ID, attribute.key, attribute.value})
 		}
 
-		// Handle quantile values
-		quantileValues := datapoint.QuantileValues()
-		for i := 0; i < quantileValues.Len(); i++ {
-			quantileValue := quantileValues.At(i)
-			summaryQV := &SummaryDatapointQuantileValues{
-				SummaryID:   summary.SummaryID,
-				DatapointID: summaryDatapoint.ID,
-				QuantileID:  uuid.New().String(),
-				Quantile:    quantileValue.Quantile(),
-				Value:       quantileValue.Value(),
+		exemplars := datapoint.Exemplars()
+		for j := 0; j < exemplars.Len(); j++ {
+			exemplar := exemplars.At(j)
+			batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], exemplar.FilteredAttributes(), "gauge exemplar", nil)
+			errs = multierr.Append(errs, err)
+
+			exemplarID := childRecordID(gaugeDatapoint.ID, "exemplar", j)
+			rows.exemplars.add(GaugeDatapointExemplar{
+				GaugeID:                gaugeID,
+				DatapointID:            gaugeDatapoint.ID,
+				ExemplarID:             exemplarID,
+				TimeUnix:               exemplar.Timestamp().AsTime().UnixMilli(),
+				GaugeValue:             exemplarValue(exemplar),
+				TraceID:                traceIDToHex(exemplar.TraceID()),
+				SpanID:                 spanIDToHex(exemplar.SpanID()),
+				DroppedAttributesCount: dropped,
+			})
+			for _, attribute := range batch.attributes {
+				rows.exemplarAttributes.add(GaugeDataPointExemplarAttribute{gaugeID, gaugeDatapoint.ID, exemplarID, attribute.key, attribute.value})
 			}
-			kiSummaryRecord.summaryDatapointQuantileValues = append(kiSummaryRecord.summaryDatapointQuantileValues, *summaryQV)
 		}
-
 	}
 
-	// Handle Resource attribute
-	var resourceAttribute []SummaryResourceAttribute
-	resourceAttributes := make(map[string]ValueTypePair)
-
-	resAttr.Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("Resource attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-			resourceAttributes[k] = v
-		} else {
-			e.logger.Debug("invalid resource attribute value", zap.String("Error", err.Error()))
-			errs = append(errs, err)
-		}
-		return true
-	})
-
-	for key := range resourceAttributes {
-		vtPair := resourceAttributes[key]
-		ga, err := e.newSummaryResourceAttributeValue(summary.SummaryID, key, vtPair)
-		if err != nil {
-			e.logger.Error(err.Error())
-		} else {
-			resourceAttribute = append(resourceAttribute, *ga)
-		}
+	shared := &batch.shared
+	for _, attribute := range shared.resource {
+		rows.resourceAttributes.add(GaugeResourceAttribute{gaugeID, attribute.key, schemaURL, shared.resourceDropped, attribute.value})
 	}
 
-	copy(kiSummaryRecord.summaryResourceAttribute, resourceAttribute)
-
-	// Handle Scope attribute
-	var scopeAttribute []SummaryScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
 	scopeName := scopeInstr.Name()
 	scopeVersion := scopeInstr.Version()
-
-	scopeInstr.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("Scope attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-			scopeAttributes[k] = v
-		} else {
-			e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-			errs = append(errs, err)
-		}
-		return true
-	})
-
-	for key := range scopeAttributes {
-		vtPair := scopeAttributes[key]
-		sa, err := e.newSummaryScopeAttributeValue(summary.SummaryID, key, scopeName, scopeVersion, vtPair)
-		if err != nil {
-			e.logger.Error(err.Error())
-		} else {
-			scopeAttribute = append(scopeAttribute, *sa)
-		}
+	for _, attribute := range shared.scope {
+		rows.scopeAttributes.add(GaugeScopeAttribute{gaugeID, scopeName, scopeVersion, attribute.key, scopeURL, shared.scopeDropped, attribute.value})
+	}
+	if len(shared.scope) == 0 {
+		// No attributes found - just basic scope
+		rows.scopeAttributes.add(GaugeScopeAttribute{gaugeID, scopeName, scopeVersion, "", scopeURL, shared.scopeDropped, AttributeValue{}})
 	}
 
-	copy(kiSummaryRecord.summaryScopeAttribute, scopeAttribute)
-
-	return kiSummaryRecord, multierr.Combine(errs...)
+	return errs
 }
 
-// createExponentialHistogramRecord
+// appendSum - appends the rows of a sum to the batch
 //
 //	@receiver e
-//	@param resAttr
+//	@param batch
+//	@param resource
 //	@param schemaURL
 //	@param scopeInstr
 //	@param scopeURL
-//	@param exponentialHistogramRecord
+//	@param sumRecord
 //	@param name
 //	@param description
 //	@param unit
-//	@return *kineticaExponentialHistogramRecord
 //	@return error
-func (e *kineticaMetricsExporter) createExponentialHistogramRecord(resAttr pcommon.Map, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, exponentialHistogramRecord pmetric.ExponentialHistogram, name, description, unit string) (*kineticaExponentialHistogramRecord, error) {
-	var errs []error
+func (e *kineticaMetricsExporter) appendSum(batch *metricBatch, resource pcommon.Resource, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, sumRecord pmetric.Sum, name, description, unit string) error {
+	rows := &batch.sum
+	resAttr := resource.Attributes()
 
-	kiExpHistogramRecord := new(kineticaExponentialHistogramRecord)
-
-	histogram := &ExponentialHistogram{
-		HistogramID:            uuid.New().String(),
-		MetricName:             name,
-		Description:            description,
-		Unit:                   unit,
-		AggregationTemporality: int8(exponentialHistogramRecord.AggregationTemporality()),
+	var isMonotonic int8
+	if sumRecord.IsMonotonic() {
+		isMonotonic = 1
 	}
 
-	kiExpHistogramRecord.histogram = histogram
-
-	// Handle data points
-	var datapointAttribute []ExponentialHistogramDataPointAttribute
-	datapointAttributes := make(map[string]ValueTypePair)
-
-	var exemplarAttribute []ExponentialHistogramDataPointExemplarAttribute
-	exemplarAttributes := make(map[string]ValueTypePair)
-
-	var datapointBucketPositiveCount []ExponentialHistogramBucketPositiveCount
-	var datapointBucketNegativeCount []ExponentialHistogramBucketNegativeCount
-
-	for i := 0; i < exponentialHistogramRecord.DataPoints().Len(); i++ {
-		datapoint := exponentialHistogramRecord.DataPoints().At(i)
-
-		expHistogramDatapoint := ExponentialHistogramDatapoint{
-			HistogramID:           histogram.HistogramID,
-			ID:                    uuid.New().String(),
-			StartTimeUnix:         datapoint.StartTimestamp().AsTime().UnixMilli(),
-			TimeUnix:              datapoint.Timestamp().AsTime().UnixMilli(),
-			Count:                 int64(datapoint.Count()),
-			Sum:                   datapoint.Sum(),
-			Min:                   datapoint.Min(),
-			Max:                   datapoint.Max(),
-			Flags:                 int(datapoint.Flags()),
-			Scale:                 int(datapoint.Scale()),
-			ZeroCount:             int64(datapoint.ZeroCount()),
-			BucketsPositiveOffset: int(datapoint.Positive().Offset()),
-			BucketsNegativeOffset: int(datapoint.Negative().Offset()),
+	job, instance := e.prometheusLabels(resAttr)
+	series := seriesIDBuilder(pmetric.MetricTypeSum.String(), name, resource, scopeInstr)
+	datapoints := sumRecord.DataPoints()
+	datapointIDs := datapointRecordIDs[pmetric.NumberDataPoint](series, datapoints)
+	sumID := metricRecordID(series, datapointIDs)
+	rows.sums.add(Sum{sumID, name, description, unit, int8(sumRecord.AggregationTemporality()), isMonotonic, job, instance})
+
+	errs := batch.shared.err()
+	for i := 0; i < datapoints.Len(); i++ {
+		datapoint := datapoints.At(i)
+
+		var dropped int
+		var err error
+		batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], datapoint.Attributes(), "sum datapoint", e.skipDatapointAttribute)
+		errs = multierr.Append(errs, err)
+
+		sumDatapoint := SumDatapoint{
+			SumID:                  sumID,
+			ID:                     datapointIDs[i],
+			StartTimeUnix:          datapoint.StartTimestamp().AsTime().UnixMilli(),
+			TimeUnix:               datapoint.Timestamp().AsTime().UnixMilli(),
+			SumValue:               numberDatapointValue(datapoint),
+			Flags:                  int(datapoint.Flags()),
+			DroppedAttributesCount: dropped,
 		}
-		kiExpHistogramRecord.histogramDatapoint = append(kiExpHistogramRecord.histogramDatapoint, expHistogramDatapoint)
-
-		// Handle histogram datapoint attribute
-		datapoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Sum record attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				datapointAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid sum record attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
+		rows.datapoints.add(sumDatapoint)
 
-		for key := range datapointAttributes {
-			vtPair := datapointAttributes[key]
-			sa, err := e.newExponentialHistogramDatapointAttributeValue(histogram.HistogramID, expHistogramDatapoint.ID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				datapointAttribute = append(datapointAttribute, *sa)
-			}
+		if e.rollup != nil {
+			batch.rollups = append(batch.rollups, rollupDatapointOf(SumDatapointTable, name, datapoint.Attributes(), resAttr, sumDatapoint.TimeUnix, sumDatapoint.SumValue))
 		}
-		kiExpHistogramRecord.histogramDatapointAttribute = append(kiExpHistogramRecord.histogramDatapointAttribute, datapointAttribute...)
 
-		for k := range datapointAttributes {
-			delete(datapointAttributes, k)
+		for _, attribute := range batch.attributes {
+			rows.datapointAttributes.add(SumDataPointAttribute{sumID, sumDatapoint.ID, attribute.key, attribute.value})
 		}
 
-		// Handle datapoint exemplars
 		exemplars := datapoint.Exemplars()
-
-		for i := 0; i < exemplars.Len(); i++ {
-			exemplar := exemplars.At(i)
-			sumDatapointExemplar := ExponentialHistogramDatapointExemplar{
-				HistogramID:    histogram.HistogramID,
-				DatapointID:    expHistogramDatapoint.ID,
-				ExemplarID:     uuid.New().String(),
-				TimeUnix:       exemplar.Timestamp().AsTime().UnixMilli(),
-				HistogramValue: exemplar.DoubleValue(),
-				TraceID:        exemplar.TraceID().String(),
-				SpanID:         exemplar.SpanID().String(),
-			}
-			kiExpHistogramRecord.exemplars = append(kiExpHistogramRecord.exemplars, sumDatapointExemplar)
-
-			// Handle Exemplar attribute
-			exemplar.FilteredAttributes().Range(func(k string, v pcommon.Value) bool {
-				if k == "" {
-					e.logger.Debug("Sum record attribute key is empty")
-				} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-					exemplarAttributes[k] = v
-				} else {
-					e.logger.Debug("invalid sum record attribute value", zap.String("Error", err.Error()))
-					errs = append(errs, err)
-				}
-				return true
+		for j := 0; j < exemplars.Len(); j++ {
+			exemplar := exemplars.At(j)
+			batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], exemplar.FilteredAttributes(), "sum exemplar", nil)
+			errs = multierr.Append(errs, err)
+
+			exemplarID := childRecordID(sumDatapoint.ID, "exemplar", j)
+			rows.exemplars.add(SumDatapointExemplar{
+				SumID:                  sumID,
+				DatapointID:            sumDatapoint.ID,
+				ExemplarID:             exemplarID,
+				TimeUnix:               exemplar.Timestamp().AsTime().UnixMilli(),
+				SumValue:               exemplarValue(exemplar),
+				TraceID:                traceIDToHex(exemplar.TraceID()),
+				SpanID:                 spanIDToHex(exemplar.SpanID()),
+				DroppedAttributesCount: dropped,
 			})
-
-			for key := range exemplarAttributes {
-				vtPair := exemplarAttributes[key]
-				ea, err := e.newExponentialHistogramDatapointExemplarAttributeValue(expHistogramDatapoint.HistogramID, expHistogramDatapoint.ID, sumDatapointExemplar.ExemplarID, key, vtPair)
-				if err != nil {
-					e.logger.Error(err.Error())
-				} else {
-					exemplarAttribute = append(exemplarAttribute, *ea)
-				}
+			for _, attribute := range batch.attributes {
+				rows.exemplarAttributes.add(SumDataPointExemplarAttribute{sumID, sumDatapoint.ID, exemplarID, attribute.key, attribute.value})
 			}
-
-			kiExpHistogramRecord.exemplarAttribute = append(kiExpHistogramRecord.exemplarAttribute, exemplarAttribute...)
-
-			for k := range exemplarAttributes {
-				delete(exemplarAttributes, k)
-			}
-
 		}
-
-		// Handle positive and negative bucket counts
-		for i := 0; i < datapoint.Positive().BucketCounts().Len(); i++ {
-			positiveBucketCount := datapoint.Positive().BucketCounts().At(i)
-			datapointBucketPositiveCount = append(datapointBucketPositiveCount, ExponentialHistogramBucketPositiveCount{
-				HistogramID: expHistogramDatapoint.HistogramID,
-				DatapointID: expHistogramDatapoint.ID,
-				CountID:     uuid.New().String(),
-				Count:       int64(positiveBucketCount),
-			})
-		}
-		kiExpHistogramRecord.histogramBucketPositiveCount = append(kiExpHistogramRecord.histogramBucketPositiveCount, datapointBucketPositiveCount...)
-
-		for i := 0; i < datapoint.Negative().BucketCounts().Len(); i++ {
-			negativeBucketCount := datapoint.Negative().BucketCounts().At(i)
-			datapointBucketNegativeCount = append(datapointBucketNegativeCount, ExponentialHistogramBucketNegativeCount{
-				HistogramID: expHistogramDatapoint.HistogramID,
-				DatapointID: expHistogramDatapoint.ID,
-				CountID:     uuid.New().String(),
-				Count:       negativeBucketCount,
-			})
-		}
-		kiExpHistogramRecord.histogramBucketNegativeCount = append(kiExpHistogramRecord.histogramBucketNegativeCount, datapointBucketNegativeCount...)
-
 	}
 
-	// Handle Resource attribute
-	var resourceAttribute []ExponentialHistogramResourceAttribute
-	resourceAttributes := make(map[string]ValueTypePair)
-
-	resAttr.Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("Resource attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-			resourceAttributes[k] = v
-		} else {
-			e.logger.Debug("invalid resource attribute value", zap.String("Error", err.Error()))
-			errs = append(errs, err)
-		}
-		return true
-	})
-
-	for key := range resourceAttributes {
-		vtPair := resourceAttributes[key]
-		ga, err := e.newExponentialHistogramResourceAttributeValue(histogram.HistogramID, key, vtPair)
-		if err != nil {
-			e.logger.Error(err.Error())
-		} else {
-			resourceAttribute = append(resourceAttribute, *ga)
-		}
+	shared := &batch.shared
+	for _, attribute := range shared.resource {
+		rows.resourceAttributes.add(SumResourceAttribute{sumID, attribute.key, schemaURL, shared.resourceDropped, attribute.value})
 	}
 
-	copy(kiExpHistogramRecord.histogramResourceAttribute, resourceAttribute)
-
-	// Handle Scope attribute
-	var scopeAttribute []ExponentialHistogramScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
 	scopeName := scopeInstr.Name()
 	scopeVersion := scopeInstr.Version()
-
-	scopeInstr.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("Scope attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-			scopeAttributes[k] = v
-		} else {
-			e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-			errs = append(errs, err)
-		}
-		return true
-	})
-
-	for key := range scopeAttributes {
-		vtPair := scopeAttributes[key]
-		sa, err := e.newExponentialHistogramScopeAttributeValue(histogram.HistogramID, key, scopeName, scopeVersion, vtPair)
-		if err != nil {
-			e.logger.Error(err.Error())
-		} else {
-			scopeAttribute = append(scopeAttribute, *sa)
-		}
+	for _, attribute := range shared.scope {
+		rows.scopeAttributes.add(SumScopeAttribute{sumID, scopeName, scopeVersion, attribute.key, scopeURL, shared.scopeDropped, attribute.value})
+	}
+	if len(shared.scope) == 0 {
+		// No attributes found - just basic scope
+		rows.scopeAttributes.add(SumScopeAttribute{sumID, scopeName, scopeVersion, "", scopeURL, shared.scopeDropped, AttributeValue{}})
 	}
 
-	copy(kiExpHistogramRecord.histogramScopeAttribute, scopeAttribute)
-
-	return kiExpHistogramRecord, multierr.Combine(errs...)
+	return errs
 }
 
-// createHistogramRecord
+// appendHistogram - appends the rows of a histogram to the batch
 //
 //	@receiver e
-//	@param resAttr
+//	@param batch
+//	@param resource
 //	@param schemaURL
 //	@param scopeInstr
 //	@param scopeURL
@@ -593,878 +466,312 @@ func (e *kineticaMetricsExporter) createExponentialHistogramRecord(resAttr pcomm
 //	@param name
 //	@param description
 //	@param unit
-//	@return *kineticaHistogramRecord
 //	@return error
-func (e *kineticaMetricsExporter) createHistogramRecord(resAttr pcommon.Map, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, histogramRecord pmetric.Histogram, name, description, unit string) (*kineticaHistogramRecord, error) {
-
-	e.logger.Debug("In createHistogramRecord ...")
-
-	var errs []error
-
-	kiHistogramRecord := new(kineticaHistogramRecord)
-
-	histogram := &Histogram{
-		HistogramID:            uuid.New().String(),
-		MetricName:             name,
-		Description:            description,
-		Unit:                   unit,
-		AggregationTemporality: int8(histogramRecord.AggregationTemporality()),
-	}
+func (e *kineticaMetricsExporter) appendHistogram(batch *metricBatch, resource pcommon.Resource, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, histogramRecord pmetric.Histogram, name, description, unit string) error {
+	rows := &batch.histogram
+	resAttr := resource.Attributes()
+
+	job, instance := e.prometheusLabels(resAttr)
+	series := seriesIDBuilder(pmetric.MetricTypeHistogram.String(), name, resource, scopeInstr)
+	datapoints := histogramRecord.DataPoints()
+	datapointIDs := datapointRecordIDs[pmetric.HistogramDataPoint](series, datapoints)
+	histogramID := metricRecordID(series, datapointIDs)
+	rows.histograms.add(Histogram{histogramID, name, description, unit, int8(histogramRecord.AggregationTemporality()), job, instance})
+
+	errs := batch.shared.err()
+	for i := 0; i < datapoints.Len(); i++ {
+		datapoint := datapoints.At(i)
+
+		var dropped int
+		var err error
+		batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], datapoint.Attributes(), "histogram datapoint", e.skipDatapointAttribute)
+		errs = multierr.Append(errs, err)
+
+		datapointID := datapointIDs[i]
+		rows.datapoints.add(HistogramDatapoint{
+			HistogramID:            histogramID,
+			ID:                     datapointID,
+			StartTimeUnix:          datapoint.StartTimestamp().AsTime().UnixMilli(),
+			TimeUnix:               datapoint.Timestamp().AsTime().UnixMilli(),
+			Count:                  int64(datapoint.Count()),
+			Sum:                    datapoint.Sum(),
+			Min:                    datapoint.Min(),
+			Max:                    datapoint.Max(),
+			Flags:                  int(datapoint.Flags()),
+			DroppedAttributesCount: dropped,
+		})
 
-	kiHistogramRecord.histogram = histogram
-
-	// Handle data points
-	var datapointAttribute []HistogramDataPointAttribute
-	datapointAttributes := make(map[string]ValueTypePair)
-
-	var exemplarAttribute []HistogramDataPointExemplarAttribute
-	exemplarAttributes := make(map[string]ValueTypePair)
-
-	// Handle data points
-	for i := 0; i < histogramRecord.DataPoints().Len(); i++ {
-		datapoint := histogramRecord.DataPoints().At(i)
-
-		histogramDatapoint := &HistogramDatapoint{
-			HistogramID:   histogram.HistogramID,
-			ID:            uuid.New().String(),
-			StartTimeUnix: datapoint.StartTimestamp().AsTime().UnixMilli(),
-			TimeUnix:      datapoint.Timestamp().AsTime().UnixMilli(),
-			Count:         int64(datapoint.Count()),
-			Sum:           datapoint.Sum(),
-			Min:           datapoint.Min(),
-			Max:           datapoint.Max(),
-			Flags:         int(datapoint.Flags()),
+		for _, attribute := range batch.attributes {
+			rows.datapointAttributes.add(HistogramDataPointAttribute{histogramID, datapointID, attribute.key, attribute.value})
 		}
-		kiHistogramRecord.histogramDatapoint = append(kiHistogramRecord.histogramDatapoint, *histogramDatapoint)
-
-		// Handle histogram datapoint attribute
-		datapoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Histogram record attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				datapointAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid histogram record attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
 
-		for key := range datapointAttributes {
-			vtPair := datapointAttributes[key]
-			sa, err := e.newHistogramDatapointAttributeValue(histogram.HistogramID, histogramDatapoint.ID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				datapointAttribute = append(datapointAttribute, *sa)
-			}
+		bucketCounts := datapoint.BucketCounts()
+		for j := 0; j < bucketCounts.Len(); j++ {
+			rows.bucketCounts.add(HistogramDatapointBucketCount{histogramID, datapointID, childRecordID(datapointID, "bucket_count", j), int64(bucketCounts.At(j))})
 		}
-		kiHistogramRecord.histogramDatapointAtribute = append(kiHistogramRecord.histogramDatapointAtribute, datapointAttribute...)
 
-		for k := range datapointAttributes {
-			delete(datapointAttributes, k)
+		explicitBounds := datapoint.ExplicitBounds()
+		for j := 0; j < explicitBounds.Len(); j++ {
+			rows.explicitBounds.add(HistogramDatapointExplicitBound{histogramID, datapointID, childRecordID(datapointID, "explicit_bound", j), explicitBounds.At(j)})
 		}
 
-		// Handle data point exemplars
 		exemplars := datapoint.Exemplars()
-
-		for i := 0; i < exemplars.Len(); i++ {
-			exemplar := exemplars.At(i)
-			histogramDatapointExemplar := HistogramDatapointExemplar{
-				HistogramID:    histogram.HistogramID,
-				DatapointID:    histogramDatapoint.ID,
-				ExemplarID:     uuid.New().String(),
-				TimeUnix:       exemplar.Timestamp().AsTime().UnixMilli(),
-				HistogramValue: exemplar.DoubleValue(),
-				TraceID:        exemplar.TraceID().String(),
-				SpanID:         exemplar.SpanID().String(),
-			}
-			kiHistogramRecord.exemplars = append(kiHistogramRecord.exemplars, histogramDatapointExemplar)
-
-			// Handle Exemplar attribute
-			exemplar.FilteredAttributes().Range(func(k string, v pcommon.Value) bool {
-				if k == "" {
-					e.logger.Debug("Histogram record attribute key is empty")
-				} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-					exemplarAttributes[k] = v
-				} else {
-					e.logger.Debug("invalid histogram record attribute value", zap.String("Error", err.Error()))
-					errs = append(errs, err)
-				}
-				return true
+		for j := 0; j < exemplars.Len(); j++ {
+			exemplar := exemplars.At(j)
+			batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], exemplar.FilteredAttributes(), "histogram exemplar", nil)
+			errs = multierr.Append(errs, err)
+
+			exemplarID := childRecordID(datapointID, "exemplar", j)
+			rows.exemplars.add(HistogramDatapointExemplar{
+				HistogramID:            histogramID,
+				DatapointID:            datapointID,
+				ExemplarID:             exemplarID,
+				TimeUnix:               exemplar.Timestamp().AsTime().UnixMilli(),
+				HistogramValue:         exemplarValue(exemplar),
+				TraceID:                traceIDToHex(exemplar.TraceID()),
+				SpanID:                 spanIDToHex(exemplar.SpanID()),
+				DroppedAttributesCount: dropped,
 			})
-
-			for key := range exemplarAttributes {
-				vtPair := exemplarAttributes[key]
-				ea, err := e.newHistogramDatapointExemplarAttributeValue(histogramDatapoint.HistogramID, histogramDatapoint.ID, histogramDatapointExemplar.ExemplarID, key, vtPair)
-				if err != nil {
-					e.logger.Error(err.Error())
-				} else {
-					exemplarAttribute = append(exemplarAttribute, *ea)
-				}
-			}
-
-			kiHistogramRecord.exemplarAttribute = append(kiHistogramRecord.exemplarAttribute, exemplarAttribute...)
-
-			for k := range exemplarAttributes {
-				delete(exemplarAttributes, k)
-			}
-		}
-
-		histogramBucketCounts := datapoint.BucketCounts()
-		for i := 0; i < histogramBucketCounts.Len(); i++ {
-			bucketCount := HistogramDatapointBucketCount{
-				HistogramID: histogramDatapoint.HistogramID,
-				DatapointID: histogramDatapoint.ID,
-				CountID:     uuid.New().String(),
-				Count:       int64(histogramBucketCounts.At(i)),
-			}
-			kiHistogramRecord.histogramBucketCount = append(kiHistogramRecord.histogramBucketCount, bucketCount)
-		}
-
-		histogramExplicitBounds := datapoint.ExplicitBounds()
-		for i := 0; i < histogramExplicitBounds.Len(); i++ {
-			explicitBound := HistogramDatapointExplicitBound{
-				HistogramID:   histogramDatapoint.HistogramID,
-				DatapointID:   histogramDatapoint.ID,
-				BoundID:       uuid.New().String(),
-				ExplicitBound: histogramExplicitBounds.At(i),
+			for _, attribute := range batch.attributes {
+				rows.exemplarAttributes.add(HistogramDataPointExemplarAttribute{histogramID, datapointID, exemplarID, attribute.key, attribute.value})
 			}
-			kiHistogramRecord.histogramExplicitBound = append(kiHistogramRecord.histogramExplicitBound, explicitBound)
 		}
 	}
 
-	// Handle Resource attribute
-	var resourceAttribute []HistogramResourceAttribute
-	resourceAttributes := make(map[string]ValueTypePair)
-
-	if resAttr.Len() > 0 {
-		resAttr.Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Resource attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				resourceAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid resource attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
-
-		for key := range resourceAttributes {
-			vtPair := resourceAttributes[key]
-			ga, err := e.newHistogramResourceAttributeValue(histogram.HistogramID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				resourceAttribute = append(resourceAttribute, *ga)
-			}
-		}
-
-		kiHistogramRecord.histogramResourceAttribute = make([]HistogramResourceAttribute, len(resourceAttribute))
-		copy(kiHistogramRecord.histogramResourceAttribute, resourceAttribute)
-
+	shared := &batch.shared
+	for _, attribute := range shared.resource {
+		rows.resourceAttributes.add(HistogramResourceAttribute{histogramID, attribute.key, schemaURL, shared.resourceDropped, attribute.value})
 	}
 
-	// Handle Scope attribute
-	var scopeAttribute []HistogramScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
 	scopeName := scopeInstr.Name()
 	scopeVersion := scopeInstr.Version()
-
-	if scopeInstr.Attributes().Len() > 0 {
-		scopeInstr.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Scope attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				scopeAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
-
-		for key := range scopeAttributes {
-			vtPair := scopeAttributes[key]
-			sa, err := e.newHistogramScopeAttributeValue(histogram.HistogramID, key, scopeName, scopeVersion, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				scopeAttribute = append(scopeAttribute, *sa)
-			}
-		}
-
-		kiHistogramRecord.histogramScopeAttribute = make([]HistogramScopeAttribute, len(scopeAttribute))
-		copy(kiHistogramRecord.histogramScopeAttribute, scopeAttribute)
+	for _, attribute := range shared.scope {
+		rows.scopeAttributes.add(HistogramScopeAttribute{histogramID, scopeName, scopeVersion, attribute.key, scopeURL, shared.scopeDropped, attribute.value})
+	}
+	if len(shared.scope) == 0 {
+		// No attributes found - just basic scope
+		rows.scopeAttributes.add(HistogramScopeAttribute{histogramID, scopeName, scopeVersion, "", scopeURL, shared.scopeDropped, AttributeValue{}})
 	}
 
-	return kiHistogramRecord, multierr.Combine(errs...)
+	return errs
 }
 
-// createSumRecord
+// appendExponentialHistogram - appends the rows of an exponential histogram to the batch
 //
 //	@receiver e
-//	@param resAttr
+//	@param batch
+//	@param resource
 //	@param schemaURL
 //	@param scopeInstr
 //	@param scopeURL
-//	@param sumRecord
+//	@param exponentialHistogramRecord
 //	@param name
 //	@param description
 //	@param unit
-//	@return *kineticaSumRecord
 //	@return error
-func (e *kineticaMetricsExporter) createSumRecord(resAttr pcommon.Map, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, sumRecord pmetric.Sum, name, description, unit string) (*kineticaSumRecord, error) {
-	var errs []error
-
-	kiSumRecord := new(kineticaSumRecord)
-	var isMonotonic int8
-	if sumRecord.IsMonotonic() {
-		isMonotonic = 1
-	}
-
-	sum := &Sum{
-		SumID:                  uuid.New().String(),
-		MetricName:             name,
-		Description:            description,
-		Unit:                   unit,
-		AggregationTemporality: int8(sumRecord.AggregationTemporality()),
-		IsMonotonic:            isMonotonic,
-	}
-
-	kiSumRecord.sum = sum
-
-	// Handle data points
-	var sumDatapointAttribute []SumDataPointAttribute
-	sumDatapointAttributes := make(map[string]ValueTypePair)
-
-	var exemplarAttribute []SumDataPointExemplarAttribute
-	exemplarAttributes := make(map[string]ValueTypePair)
-
-	for i := 0; i < sumRecord.DataPoints().Len(); i++ {
-		datapoint := sumRecord.DataPoints().At(i)
-
-		sumDatapoint := SumDatapoint{
-			SumID:         sum.SumID,
-			ID:            uuid.New().String(),
-			StartTimeUnix: datapoint.StartTimestamp().AsTime().UnixMilli(),
-			TimeUnix:      datapoint.Timestamp().AsTime().UnixMilli(),
-			SumValue:      datapoint.DoubleValue(),
-			Flags:         int(datapoint.Flags()),
-		}
-		kiSumRecord.datapoint = append(kiSumRecord.datapoint, sumDatapoint)
-
-		// Handle Sum attribute
-		datapoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Sum record attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				sumDatapointAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid sum record attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
+func (e *kineticaMetricsExporter) appendExponentialHistogram(batch *metricBatch, resource pcommon.Resource, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, exponentialHistogramRecord pmetric.ExponentialHistogram, name, description, unit string) error {
+	rows := &batch.exponentialHistogram
+	resAttr := resource.Attributes()
+
+	job, instance := e.prometheusLabels(resAttr)
+	series := seriesIDBuilder(pmetric.MetricTypeExponentialHistogram.String(), name, resource, scopeInstr)
+	datapoints := exponentialHistogramRecord.DataPoints()
+	datapointIDs := datapointRecordIDs[pmetric.ExponentialHistogramDataPoint](series, datapoints)
+	histogramID := metricRecordID(series, datapointIDs)
+	rows.histograms.add(ExponentialHistogram{histogramID, name, description, unit, int8(exponentialHistogramRecord.AggregationTemporality()), job, instance})
+
+	errs := batch.shared.err()
+	for i := 0; i < datapoints.Len(); i++ {
+		datapoint := datapoints.At(i)
+
+		var dropped int
+		var err error
+		batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], datapoint.Attributes(), "exponential histogram datapoint", e.skipDatapointAttribute)
+		errs = multierr.Append(errs, err)
+
+		datapointID := datapointIDs[i]
+		rows.datapoints.add(ExponentialHistogramDatapoint{
+			HistogramID:            histogramID,
+			ID:                     datapointID,
+			StartTimeUnix:          datapoint.StartTimestamp().AsTime().UnixMilli(),
+			TimeUnix:               datapoint.Timestamp().AsTime().UnixMilli(),
+			Count:                  int64(datapoint.Count()),
+			Sum:                    datapoint.Sum(),
+			Min:                    datapoint.Min(),
+			Max:                    datapoint.Max(),
+			Flags:                  int(datapoint.Flags()),
+			Scale:                  int(datapoint.Scale()),
+			ZeroCount:              int64(datapoint.ZeroCount()),
+			BucketsPositiveOffset:  int(datapoint.Positive().Offset()),
+			BucketsNegativeOffset:  int(datapoint.Negative().Offset()),
+			DroppedAttributesCount: dropped,
 		})
 
-		for key := range sumDatapointAttributes {
-			vtPair := sumDatapointAttributes[key]
-			sa, err := e.newSumDatapointAttributeValue(sum.SumID, sumDatapoint.ID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				sumDatapointAttribute = append(sumDatapointAttribute, *sa)
-			}
-		}
-		kiSumRecord.datapointAttribute = append(kiSumRecord.datapointAttribute, sumDatapointAttribute...)
-
-		for k := range sumDatapointAttributes {
-			delete(sumDatapointAttributes, k)
+		for _, attribute := range batch.attributes {
+			rows.datapointAttributes.add(ExponentialHistogramDataPointAttribute{histogramID, datapointID, attribute.key, attribute.value})
 		}
 
-		// Handle data point exemplars
 		exemplars := datapoint.Exemplars()
-
-		for i := 0; i < exemplars.Len(); i++ {
-			exemplar := exemplars.At(i)
-			sumDatapointExemplar := SumDatapointExemplar{
-				SumID:       sum.SumID,
-				DatapointID: sumDatapoint.ID,
-				ExemplarID:  uuid.New().String(),
-				TimeUnix:    exemplar.Timestamp().AsTime().UnixMilli(),
-				SumValue:    exemplar.DoubleValue(),
-				TraceID:     exemplar.TraceID().String(),
-				SpanID:      exemplar.SpanID().String(),
-			}
-			kiSumRecord.exemplars = append(kiSumRecord.exemplars, sumDatapointExemplar)
-
-			// Handle Exemplar attribute
-			exemplar.FilteredAttributes().Range(func(k string, v pcommon.Value) bool {
-				if k == "" {
-					e.logger.Debug("Sum record attribute key is empty")
-				} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-					exemplarAttributes[k] = v
-				} else {
-					e.logger.Debug("invalid sum record attribute value", zap.String("Error", err.Error()))
-					errs = append(errs, err)
-				}
-				return true
+		for j := 0; j < exemplars.Len(); j++ {
+			exemplar := exemplars.At(j)
+			batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], exemplar.FilteredAttributes(), "exponential histogram exemplar", nil)
+			errs = multierr.Append(errs, err)
+
+			exemplarID := childRecordID(datapointID, "exemplar", j)
+			rows.exemplars.add(ExponentialHistogramDatapointExemplar{
+				HistogramID:            histogramID,
+				DatapointID:            datapointID,
+				ExemplarID:             exemplarID,
+				TimeUnix:               exemplar.Timestamp().AsTime().UnixMilli(),
+				HistogramValue:         exemplarValue(exemplar),
+				TraceID:                traceIDToHex(exemplar.TraceID()),
+				SpanID:                 spanIDToHex(exemplar.SpanID()),
+				DroppedAttributesCount: dropped,
 			})
-
-			for key := range exemplarAttributes {
-				vtPair := exemplarAttributes[key]
-				ea, err := e.newSumDatapointExemplarAttributeValue(sum.SumID, sumDatapoint.ID, sumDatapointExemplar.ExemplarID, key, vtPair)
-				if err != nil {
-					e.logger.Error(err.Error())
-				} else {
-					exemplarAttribute = append(exemplarAttribute, *ea)
-				}
+			for _, attribute := range batch.attributes {
+				rows.exemplarAttributes.add(ExponentialHistogramDataPointExemplarAttribute{histogramID, datapointID, exemplarID, attribute.key, attribute.value})
 			}
-
-			kiSumRecord.exemplarAttribute = append(kiSumRecord.exemplarAttribute, exemplarAttribute...)
-
-			for k := range exemplarAttributes {
-				delete(exemplarAttributes, k)
-			}
-
 		}
 
-	}
-
-	// Handle Resource attribute
-	var resourceAttribute []SumResourceAttribute
-	resourceAttributes := make(map[string]ValueTypePair)
-
-	if resAttr.Len() > 0 {
-		resAttr.Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Resource attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				resourceAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid resource attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
+		// Handle positive and negative bucket counts
+		positiveBucketCounts := datapoint.Positive().BucketCounts()
+		for j := 0; j < positiveBucketCounts.Len(); j++ {
+			rows.positiveBucketCounts.add(ExponentialHistogramBucketPositiveCount{histogramID, datapointID, childRecordID(datapointID, "bucket_positive_count", j), int64(positiveBucketCounts.At(j))})
+		}
 
-		for key := range resourceAttributes {
-			vtPair := resourceAttributes[key]
-			ga, err := e.newSumResourceAttributeValue(sum.SumID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				resourceAttribute = append(resourceAttribute, *ga)
-			}
+		negativeBucketCounts := datapoint.Negative().BucketCounts()
+		for j := 0; j < negativeBucketCounts.Len(); j++ {
+			rows.negativeBucketCounts.add(ExponentialHistogramBucketNegativeCount{histogramID, datapointID, childRecordID(datapointID, "bucket_negative_count", j), int64(negativeBucketCounts.At(j))})
 		}
+	}
 
-		kiSumRecord.sumResourceAttribute = make([]SumResourceAttribute, len(resourceAttribute))
-		copy(kiSumRecord.sumResourceAttribute, resourceAttribute)
+	shared := &batch.shared
+	for _, attribute := range shared.resource {
+		rows.resourceAttributes.add(ExponentialHistogramResourceAttribute{histogramID, attribute.key, schemaURL, shared.resourceDropped, attribute.value})
 	}
 
-	// Handle Scope attribute
-	var scopeAttribute []SumScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
 	scopeName := scopeInstr.Name()
 	scopeVersion := scopeInstr.Version()
-
-	if scopeInstr.Attributes().Len() > 0 {
-		scopeInstr.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Scope attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				scopeAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
-
-		for key := range scopeAttributes {
-			vtPair := scopeAttributes[key]
-			sa, err := e.newSumScopeAttributeValue(sum.SumID, key, scopeName, scopeVersion, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				scopeAttribute = append(scopeAttribute, *sa)
-			}
-		}
-
-		copy(kiSumRecord.sumScopeAttribute, scopeAttribute)
-	} else {
+	for _, attribute := range shared.scope {
+		rows.scopeAttributes.add(ExponentialHistogramScopeAttribute{histogramID, scopeName, scopeVersion, attribute.key, scopeURL, shared.scopeDropped, attribute.value})
+	}
+	if len(shared.scope) == 0 {
 		// No attributes found - just basic scope
-		kiSumRecord.sumScopeAttribute = append(kiSumRecord.sumScopeAttribute, SumScopeAttribute{
-			SumID:          sum.SumID,
-			ScopeName:      scopeName,
-			ScopeVersion:   scopeVersion,
-			Key:            "",
-			AttributeValue: AttributeValue{},
-		})
-
+		rows.scopeAttributes.add(ExponentialHistogramScopeAttribute{histogramID, scopeName, scopeVersion, "", scopeURL, shared.scopeDropped, AttributeValue{}})
 	}
 
-	return kiSumRecord, multierr.Combine(errs...)
+	return errs
 }
 
-// createGaugeRecord
+// appendSummary - appends the rows of a summary to the batch, the configured quantiles
+// are pivoted into datapoint columns
 //
 //	@receiver e
-//	@param resAttr
+//	@param batch
+//	@param resource
 //	@param schemaURL
 //	@param scopeInstr
 //	@param scopeURL
-//	@param gaugeRecord
+//	@param summaryRecord
 //	@param name
 //	@param description
 //	@param unit
-//	@return *kineticaGaugeRecord
 //	@return error
-func (e *kineticaMetricsExporter) createGaugeRecord(resAttr pcommon.Map, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, gaugeRecord pmetric.Gauge, name, description, unit string) (*kineticaGaugeRecord, error) {
-
-	var errs []error
-
-	kiGaugeRecord := new(kineticaGaugeRecord)
-
-	gauge := &Gauge{
-		GaugeID:     uuid.New().String(),
-		MetricName:  name,
-		Description: description,
-		Unit:        unit,
-	}
+func (e *kineticaMetricsExporter) appendSummary(batch *metricBatch, resource pcommon.Resource, schemaURL string, scopeInstr pcommon.InstrumentationScope, scopeURL string, summaryRecord pmetric.Summary, name, description, unit string) error {
+	rows := &batch.summary
+	resAttr := resource.Attributes()
 
-	kiGaugeRecord.gauge = gauge
+	job, instance := e.prometheusLabels(resAttr)
+	series := seriesIDBuilder(pmetric.MetricTypeSummary.String(), name, resource, scopeInstr)
+	datapoints := summaryRecord.DataPoints()
+	datapointIDs := datapointRecordIDs[pmetric.SummaryDataPoint](series, datapoints)
+	summaryID := metricRecordID(series, datapointIDs)
+	rows.summaries.add(Summary{summaryID, name, description, unit, job, instance})
 
-	// Handle data points
-	var gaugeDatapointAttribute []GaugeDatapointAttribute
-	gaugeDatapointAttributes := make(map[string]ValueTypePair)
+	errs := batch.shared.err()
+	for i := 0; i < datapoints.Len(); i++ {
+		datapoint := datapoints.At(i)
 
-	var exemplarAttribute []GaugeDataPointExemplarAttribute
-	exemplarAttributes := make(map[string]ValueTypePair)
-
-	for i := 0; i < gaugeRecord.DataPoints().Len(); i++ {
-		datapoint := gaugeRecord.DataPoints().At(i)
-
-		gaugeDatapoint := GaugeDatapoint{
-			GaugeID:       gauge.GaugeID,
-			ID:            uuid.New().String(),
-			StartTimeUnix: datapoint.StartTimestamp().AsTime().UnixMilli(),
-			TimeUnix:      datapoint.Timestamp().AsTime().UnixMilli(),
-			GaugeValue:    datapoint.DoubleValue(),
-			Flags:         int(datapoint.Flags()),
-		}
-		kiGaugeRecord.datapoint = append(kiGaugeRecord.datapoint, gaugeDatapoint)
-
-		datapoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Gauge record attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				gaugeDatapointAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid gauge record attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
+		var dropped int
+		var err error
+		batch.attributes, dropped, err = batch.converter.convert(batch.attributes[:0], datapoint.Attributes(), "summary datapoint", e.skipDatapointAttribute)
+		errs = multierr.Append(errs, err)
 
-		for key := range gaugeDatapointAttributes {
-			vtPair := gaugeDatapointAttributes[key]
-			ga, err := e.newGaugeDatapointAttributeValue(gauge.GaugeID, gaugeDatapoint.ID, key, vtPair)
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				gaugeDatapointAttribute = append(gaugeDatapointAttribute, *ga)
-			}
+		summaryDatapoint := SummaryDatapoint{
+			SummaryID:              summaryID,
+			ID:                     datapointIDs[i],
+			StartTimeUnix:          datapoint.StartTimestamp().AsTime().UnixMilli(),
+			TimeUnix:               datapoint.Timestamp().AsTime().UnixMilli(),
+			Count:                  int64(datapoint.Count()),
+			Sum:                    datapoint.Sum(),
+			Flags:                  int(datapoint.Flags()),
+			DroppedAttributesCount: dropped,
 		}
 
-		kiGaugeRecord.datapointAttribute = append(kiGaugeRecord.datapointAttribute, gaugeDatapointAttribute...)
-
-		for k := range gaugeDatapointAttributes {
-			delete(gaugeDatapointAttributes, k)
+		for _, attribute := range batch.attributes {
+			rows.datapointAttributes.add(SummaryDataPointAttribute{summaryID, summaryDatapoint.ID, attribute.key, attribute.value})
 		}
 
-		// Handle data point exemplars
-		exemplars := datapoint.Exemplars()
-		for i := 0; i < exemplars.Len(); i++ {
-			exemplar := exemplars.At(i)
-			gaugeDatapointExemplar := GaugeDatapointExemplar{
-				GaugeID:     gauge.GaugeID,
-				DatapointID: gaugeDatapoint.ID,
-				ExemplarID:  uuid.New().String(),
-				TimeUnix:    exemplar.Timestamp().AsTime().UnixMilli(),
-				GaugeValue:  exemplar.DoubleValue(),
-				TraceID:     exemplar.TraceID().String(),
-				SpanID:      exemplar.SpanID().String(),
-			}
-			kiGaugeRecord.exemplars = append(kiGaugeRecord.exemplars, gaugeDatapointExemplar)
-
-			// Handle Exemplar attribute
-			exemplar.FilteredAttributes().Range(func(k string, v pcommon.Value) bool {
-				if k == "" {
-					e.logger.Debug("Sum record attribute key is empty")
-				} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-					exemplarAttributes[k] = v
-				} else {
-					e.logger.Debug("invalid sum record attribute value", zap.String("Error", err.Error()))
-					errs = append(errs, err)
-				}
-				return true
-			})
-
-			for key := range exemplarAttributes {
-				vtPair := exemplarAttributes[key]
-				ea, err := e.newGaugeDatapointExemplarAttributeValue(gauge.GaugeID, gaugeDatapoint.ID, gaugeDatapointExemplar.ExemplarID, key, vtPair)
-				if err != nil {
-					e.logger.Error(err.Error())
-				} else {
-					exemplarAttribute = append(exemplarAttribute, *ea)
+		// Handle quantile values
+		quantileValues := datapoint.QuantileValues()
+		for j := 0; j < quantileValues.Len(); j++ {
+			quantileValue := quantileValues.At(j)
+			if column, ok := e.summaryQuantileColumns[quantileValue.Quantile()]; ok {
+				if summaryDatapoint.Quantiles == nil {
+					summaryDatapoint.Quantiles = make(map[string]float64, len(e.summaryQuantileColumns))
 				}
+				summaryDatapoint.Quantiles[column] = quantileValue.Value()
+				continue
 			}
-
-			kiGaugeRecord.exemplarAttribute = append(kiGaugeRecord.exemplarAttribute, exemplarAttribute...)
-
-			for k := range exemplarAttributes {
-				delete(exemplarAttributes, k)
-			}
+			rows.quantileValues.add(SummaryDatapointQuantileValues{
+				SummaryID:   summaryID,
+				DatapointID: summaryDatapoint.ID,
+				QuantileID:  childRecordID(summaryDatapoint.ID, "quantile", j),
+				Quantile:    quantileValue.Quantile(),
+				Value:       quantileValue.Value(),
+			})
 		}
+		rows.datapoints.add(summaryDatapoint)
 	}
 
-	// Handle Resource attribute
-	e.logger.Debug("Resource Attributes received ->", zap.Any("Attributes", resAttr))
-
-	var resourceAttribute []GaugeResourceAttribute
-	resourceAttributes := make(map[string]ValueTypePair)
-
-	if resAttr.Len() > 0 {
-		resAttr.Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Resource attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				resourceAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid resource attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
-
-		e.logger.Debug("Resource Attributes to be added ->", zap.Any("Attributes", resourceAttributes))
-		for key := range resourceAttributes {
-			vtPair := resourceAttributes[key]
-			ga, err := e.newGaugeResourceAttributeValue(gauge.GaugeID, key, vtPair)
-
-			e.logger.Debug("New resource attribute ->", zap.Any("Attribute", ga))
-
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				resourceAttribute = append(resourceAttribute, *ga)
-			}
-		}
-
-		kiGaugeRecord.resourceAttribute = make([]GaugeResourceAttribute, len(resourceAttribute))
-		copy(kiGaugeRecord.resourceAttribute, resourceAttribute)
-		e.logger.Debug("Resource Attributes actually added ->", zap.Any("Attributes", kiGaugeRecord.resourceAttribute))
+	shared := &batch.shared
+	for _, attribute := range shared.resource {
+		rows.resourceAttributes.add(SummaryResourceAttribute{summaryID, attribute.key, schemaURL, shared.resourceDropped, attribute.value})
 	}
 
-	// Handle Scope attribute
-	e.logger.Debug("Scope Attributes received ->", zap.Any("Attributes", scopeInstr.Attributes()))
-
-	var scopeAttribute []GaugeScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
 	scopeName := scopeInstr.Name()
 	scopeVersion := scopeInstr.Version()
-
-	if scopeInstr.Attributes().Len() > 0 {
-		scopeInstr.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Scope attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err == nil {
-				scopeAttributes[k] = v
-			} else {
-				e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-				errs = append(errs, err)
-			}
-			return true
-		})
-
-		e.logger.Debug("Scope Attributes to be added ->", zap.Any("Attributes", scopeAttributes))
-		for key := range scopeAttributes {
-			vtPair := scopeAttributes[key]
-			ga, err := e.newGaugeScopeAttributeValue(gauge.GaugeID, key, scopeName, scopeVersion, vtPair)
-
-			e.logger.Debug("New scope attribute ->", zap.Any("Attribute", ga))
-
-			if err != nil {
-				e.logger.Error(err.Error())
-			} else {
-				scopeAttribute = append(scopeAttribute, *ga)
-			}
-		}
-
-		kiGaugeRecord.scopeAttribute = make([]GaugeScopeAttribute, len(scopeAttribute))
-		copy(kiGaugeRecord.scopeAttribute, scopeAttribute)
-		e.logger.Debug("Scope Attributes actually added ->", zap.Any("Attributes", kiGaugeRecord.scopeAttribute))
-	} else {
+	for _, attribute := range shared.scope {
+		rows.scopeAttributes.add(SummaryScopeAttribute{summaryID, scopeName, scopeVersion, attribute.key, scopeURL, shared.scopeDropped, attribute.value})
+	}
+	if len(shared.scope) == 0 {
 		// No attributes found - just basic scope
-		kiGaugeRecord.scopeAttribute = append(kiGaugeRecord.scopeAttribute, GaugeScopeAttribute{
-			GaugeID:        gauge.GaugeID,
-			ScopeName:      scopeName,
-			ScopeVersion:   scopeVersion,
-			Key:            "",
-			AttributeValue: AttributeValue{},
-		})
+		rows.scopeAttributes.add(SummaryScopeAttribute{summaryID, scopeName, scopeVersion, "", scopeURL, shared.scopeDropped, AttributeValue{}})
 	}
 
-	return kiGaugeRecord, multierr.Combine(errs...)
+	return errs
 }
 
 // Utility functions
-func (e *kineticaMetricsExporter) newGaugeResourceAttributeValue(GaugeID string, key string, vtPair ValueTypePair) (*GaugeResourceAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ra := &GaugeResourceAttribute{GaugeID, key, *av}
-	return ra, nil
-}
 
-func (e *kineticaMetricsExporter) newGaugeDatapointAttributeValue(GaugeID string, DatapointID string, key string, vtPair ValueTypePair) (*GaugeDatapointAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ga := &GaugeDatapointAttribute{GaugeID, DatapointID, key, *av}
-	return ga, nil
-}
-
-func (e *kineticaMetricsExporter) newGaugeScopeAttributeValue(gaugeID string, key string, scopeName string, scopeVersion string, vtPair ValueTypePair) (*GaugeScopeAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &GaugeScopeAttribute{gaugeID, key, scopeName, scopeVersion, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newSumDatapointAttributeValue(SumID string, DatapointID string, key string, vtPair ValueTypePair) (*SumDataPointAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ga := &SumDataPointAttribute{SumID, DatapointID, key, *av}
-	return ga, nil
-}
-
-func (e *kineticaMetricsExporter) newSumResourceAttributeValue(SumID string, key string, vtPair ValueTypePair) (*SumResourceAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ra := &SumResourceAttribute{SumID, key, *av}
-	return ra, nil
-}
-
-func (e *kineticaMetricsExporter) newSumScopeAttributeValue(sumID string, key string, scopeName string, scopeVersion string, vtPair ValueTypePair) (*SumScopeAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &SumScopeAttribute{sumID, key, scopeName, scopeVersion, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newSumDatapointExemplarAttributeValue(sumID string, sumDatapointID string, sumDatapointExemplarID string, key string, vtPair ValueTypePair) (*SumDataPointExemplarAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &SumDataPointExemplarAttribute{sumID, sumDatapointID, sumDatapointExemplarID, key, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newExponentialHistogramDatapointExemplarAttributeValue(histogramID string, histogramDatapointID string, histogramDatapointExemplarID string, key string, vtPair ValueTypePair) (*ExponentialHistogramDataPointExemplarAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &ExponentialHistogramDataPointExemplarAttribute{histogramID, histogramDatapointID, histogramDatapointExemplarID, key, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newExponentialHistogramDatapointAttributeValue(HistogramID string, DatapointID string, key string, vtPair ValueTypePair) (*ExponentialHistogramDataPointAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ga := &ExponentialHistogramDataPointAttribute{HistogramID, DatapointID, key, *av}
-	return ga, nil
-}
-
-func (e *kineticaMetricsExporter) newHistogramDatapointExemplarAttributeValue(HistogramID string, DatapointID string, ExemplarID string, key string, vtPair ValueTypePair) (*HistogramDataPointExemplarAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ga := &HistogramDataPointExemplarAttribute{HistogramID, DatapointID, ExemplarID, key, *av}
-	return ga, nil
-}
-
-func (e *kineticaMetricsExporter) newGaugeDatapointExemplarAttributeValue(gaugeID string, gaugeDatapointID string, gaugeDatapointExemplarID string, key string, vtPair ValueTypePair) (*GaugeDataPointExemplarAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &GaugeDataPointExemplarAttribute{gaugeID, gaugeDatapointID, gaugeDatapointExemplarID, key, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newHistogramResourceAttributeValue(HistogramID string, key string, vtPair ValueTypePair) (*HistogramResourceAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ra := &HistogramResourceAttribute{HistogramID, key, *av}
-	return ra, nil
-}
-
-func (e *kineticaMetricsExporter) newHistogramScopeAttributeValue(histogramID string, key string, scopeName string, scopeVersion string, vtPair ValueTypePair) (*HistogramScopeAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &HistogramScopeAttribute{histogramID, key, scopeName, scopeVersion, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newExponentialHistogramResourceAttributeValue(HistogramID string, key string, vtPair ValueTypePair) (*ExponentialHistogramResourceAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ra := &ExponentialHistogramResourceAttribute{HistogramID, key, *av}
-	return ra, nil
-}
-
-func (e *kineticaMetricsExporter) newExponentialHistogramScopeAttributeValue(histogramID string, key string, scopeName string, scopeVersion string, vtPair ValueTypePair) (*ExponentialHistogramScopeAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &ExponentialHistogramScopeAttribute{histogramID, key, scopeName, scopeVersion, *av}
-	return sa, nil
-}
-
-func (e *kineticaMetricsExporter) newSummaryResourceAttributeValue(SummaryID string, key string, vtPair ValueTypePair) (*SummaryResourceAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ra := &SummaryResourceAttribute{SummaryID, key, *av}
-	return ra, nil
-}
-
-func (e *kineticaMetricsExporter) newSummaryScopeAttributeValue(summaryID string, key string, scopeName string, scopeVersion string, vtPair ValueTypePair) (*SummaryScopeAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	sa := &SummaryScopeAttribute{summaryID, key, scopeName, scopeVersion, *av}
-	return sa, nil
-
-}
-
-func (e *kineticaMetricsExporter) newSummaryDatapointAttributeValue(summaryID string, summaryDatapointID string, key string, vtPair ValueTypePair) (*SummaryDataPointAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
+// prometheusLabels - job and instance of the resource, empty unless Prometheus normalization is enabled
+//
+//	@receiver e
+//	@param resource
+//	@return string
+//	@return string
+func (e *kineticaMetricsExporter) prometheusLabels(resAttr pcommon.Map) (string, string) {
+	if !e.normalizePrometheus {
+		return "", ""
 	}
-
-	sa := &SummaryDataPointAttribute{summaryID, summaryDatapointID, key, *av}
-	return sa, nil
+	return prometheusJobInstance(resAttr)
 }
 
-func (e *kineticaMetricsExporter) newHistogramDatapointAttributeValue(HistogramID string, DatapointID string, key string, vtPair ValueTypePair) (*HistogramDataPointAttribute, error) {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-	if err != nil {
-		return nil, err
-	}
-
-	ga := &HistogramDataPointAttribute{HistogramID, DatapointID, key, *av}
-	return ga, nil
+// skipDatapointAttribute - true for the otel_scope_* labels dropped in Prometheus normalization mode
+//
+//	@receiver e
+//	@param key
+//	@return bool
+func (e *kineticaMetricsExporter) skipDatapointAttribute(key string) bool {
+	return e.normalizePrometheus && isPrometheusNoiseAttribute(key)
 }
@@ -0,0 +1,104 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SQL statement to add a column missing from a table created by an earlier version
+const AddTableColumn string = `ALTER TABLE %s ADD "%s" %s`
+
+// zeroUUID - the default of the UUID columns added to tables holding rows
+const zeroUUID = "00000000-0000-0000-0000-000000000000"
+
+// ensureColumns - adds the columns of the records of tables missing from the tables,
+// e.g. the columns added to a record since the table was created. The rows already in
+// a table get the zero value of a column that is NOT NULL and NULL otherwise. Tables
+// that cannot be shown are skipped, they may not exist yet, and so are tables without
+// a record like the rollup tables.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tables
+//	@return error
+func (kiwriter *KiWriter) ensureColumns(ctx context.Context, tables []string) error {
+	var errs error
+	for _, tableName := range tables {
+		record, ok := tableRecords[tableName]
+		if !ok {
+			continue
+		}
+		finalTable := kiwriter.finalTableName(tableName)
+		existing, err := kiwriter.tableColumnNames(ctx, finalTable)
+		if err != nil {
+			kiwriter.logger.Debug("Cannot fetch table columns", zap.String("Table", finalTable), zap.Error(err))
+			continue
+		}
+
+		for _, column := range tableColumns(record) {
+			if existing[column.name] {
+				continue
+			}
+			kiwriter.logger.Info("Adding column", zap.String("Table", finalTable), zap.String("Column", column.name))
+			statement := fmt.Sprintf(AddTableColumn, kiwriter.qualifiedTableName(tableName), column.name, addedColumnType(column))
+			if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("cannot add column %s to %s: %w", column.name, finalTable, err))
+			}
+		}
+	}
+	return errs
+}
+
+// tableColumnNames - the names of the columns of a table
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param finalTable
+//	@return map[string]bool
+//	@return error
+func (kiwriter *KiWriter) tableColumnNames(ctx context.Context, finalTable string) (map[string]bool, error) {
+	showTableResult, err := kiwriter.Db.ShowTableRaw(ctx, finalTable)
+	if err != nil {
+		return nil, err
+	}
+	if len(showTableResult.TypeSchemas) == 0 {
+		return nil, fmt.Errorf("no type schema for table %s", finalTable)
+	}
+
+	var typeSchema struct {
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(showTableResult.TypeSchemas[0]), &typeSchema); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(typeSchema.Fields))
+	for _, field := range typeSchema.Fields {
+		names[field.Name] = true
+	}
+	return names, nil
+}
+
+// addedColumnType - the type of a column added to a table holding rows, a NOT NULL
+// column defaults to the zero value of its type
+//
+//	@param column
+//	@return string
+func addedColumnType(column tableColumn) string {
+	if column.nullable {
+		return column.sqlType
+	}
+	switch column.sqlType {
+	case "TINYINT", "SMALLINT", "INTEGER", "BIGINT", "REAL", "DOUBLE":
+		return column.sqlType + " NOT NULL DEFAULT 0"
+	case "UUID":
+		return column.sqlType + " NOT NULL DEFAULT '" + zeroUUID + "'"
+	default:
+		return column.sqlType + " NOT NULL DEFAULT ''"
+	}
+}
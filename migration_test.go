@@ -0,0 +1,125 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestEnsureColumnsAddsTheMissingColumns(t *testing.T) {
+	factory := NewFactory()
+	tests := []struct {
+		name    string
+		table   string
+		removed []string
+		create  func(cfg *Config) (component.Component, error)
+		want    []string
+	}{
+		{"log", LogTable, []string{"severity_level", "flags", "dropped_attributes_count"},
+			func(cfg *Config) (component.Component, error) {
+				return factory.CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			},
+			[]string{
+				`ALTER TABLE "otel"."log" ADD "severity_level" VARCHAR`,
+				`ALTER TABLE "otel"."log" ADD "flags" INTEGER NOT NULL DEFAULT 0`,
+				`ALTER TABLE "otel"."log" ADD "dropped_attributes_count" INTEGER NOT NULL DEFAULT 0`,
+			}},
+		{"span event attribute", TraceEventAttributeTable, []string{"span_record_id", "event_index"},
+			func(cfg *Config) (component.Component, error) {
+				return factory.CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			},
+			[]string{
+				`ALTER TABLE "otel"."trace_event_attribute" ADD "span_record_id" UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000'`,
+				`ALTER TABLE "otel"."trace_event_attribute" ADD "event_index" INTEGER NOT NULL DEFAULT 0`,
+			}},
+		{"gauge", GaugeTable, []string{"job", "instance"},
+			func(cfg *Config) (component.Component, error) {
+				return factory.CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			},
+			[]string{
+				`ALTER TABLE "otel"."metric_gauge" ADD "job" VARCHAR NOT NULL DEFAULT ''`,
+				`ALTER TABLE "otel"."metric_gauge" ADD "instance" VARCHAR NOT NULL DEFAULT ''`,
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			if err := server.CreateTable("otel."+tt.table, typeSchemaWithout(t, tableRecords[tt.table], tt.removed), nil); err != nil {
+				t.Fatal(err)
+			}
+			exporter, err := tt.create(newTestConfig(server))
+			if err != nil {
+				t.Fatal(err)
+			}
+			startExporter(t, exporter)
+
+			var got []string
+			for _, statement := range server.Statements() {
+				if strings.HasPrefix(statement, "ALTER TABLE") {
+					got = append(got, statement)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureColumnsWritesToTheMigratedTable(t *testing.T) {
+	server := newTestServer(t)
+	if err := server.CreateTable("otel."+LogTable, typeSchemaWithout(t, Log{}, []string{"severity_level", "flags"}), nil); err != nil {
+		t.Fatal(err)
+	}
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), newTestConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	if err := exporter.ConsumeLogs(context.Background(), testLogs("error")); err != nil {
+		t.Fatal(err)
+	}
+	rows := server.Rows("otel." + LogTable)
+	if len(rows) != 1 || rows[0]["severity_level"] == nil {
+		t.Errorf("%s has rows %v, want the log with its severity level", LogTable, rows)
+	}
+}
+
+// typeSchemaWithout - the type schema of a record without some of its columns, the
+// schema of a table created before the columns were added to the record
+//
+//	@param t
+//	@param record
+//	@param removed
+//	@return string
+func typeSchemaWithout(t *testing.T, record any, removed []string) string {
+	t.Helper()
+	var typeSchema map[string]any
+	if err := json.Unmarshal([]byte(kineticatest.TypeSchemaOf(record)), &typeSchema); err != nil {
+		t.Fatal(err)
+	}
+	var fields []any
+	for _, field := range typeSchema["fields"].([]any) {
+		name := field.(map[string]any)["name"].(string)
+		keep := true
+		for _, column := range removed {
+			keep = keep && name != column
+		}
+		if keep {
+			fields = append(fields, field)
+		}
+	}
+	typeSchema["fields"] = fields
+	encoded, err := json.Marshal(typeSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(encoded)
+}
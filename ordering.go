@@ -0,0 +1,322 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	orderedmap "github.com/wk8/go-ordered-map"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Write ordering modes
+const (
+	// WriteOrderingConcurrent inserts the tables of a signal without regard to their relations
+	WriteOrderingConcurrent = "concurrent"
+	// WriteOrderingParentFirst inserts parent tables before their children and skips the
+	// children of parent rows that could not be inserted
+	WriteOrderingParentFirst = "parent_first"
+
+	// SQL statement to remove the rows of failed root records
+	DeleteRowsByRootID string = `DELETE FROM %s WHERE %s IN (%s)`
+
+	// maximum number of ids in one compensating delete
+	compensatingDeleteBatchSize = 1000
+)
+
+// tableOrder - the level of a table in the parent-first write order and the column
+// holding the id of the root record (log, span or metric) its rows belong to
+type tableOrder struct {
+	level      int
+	rootColumn string
+}
+
+// orderedTables - the write order of every table written by the exporters
+var orderedTables = map[string]tableOrder{
+	LogTable:                  {0, "log_id"},
+	LogAttributeTable:         {1, "log_id"},
+	LogResourceAttributeTable: {1, "log_id"},
+	LogScopeAttributeTable:    {1, "log_id"},
+
+	TraceSpanTable:              {0, "id"},
+	TraceSpanAttributeTable:     {1, "span_record_id"},
+	TraceResourceAttributeTable: {1, "span_record_id"},
+	TraceScopeAttributeTable:    {1, "span_record_id"},
+	TraceEventAttributeTable:    {1, "span_record_id"},
+	TraceLinkAttributeTable:     {1, "span_record_id"},
+
+	GaugeTable:                           {0, "gauge_id"},
+	GaugeDatapointTable:                  {1, "gauge_id"},
+	GaugeResourceAttributeTable:          {1, "gauge_id"},
+	GaugeScopeAttributeTable:             {1, "gauge_id"},
+	GaugeDatapointAttributeTable:         {2, "gauge_id"},
+	GaugeDatapointExemplarTable:          {2, "gauge_id"},
+	GaugeDatapointExemplarAttributeTable: {3, "gauge_id"},
+
+	SumTable:                           {0, "sum_id"},
+	SumDatapointTable:                  {1, "sum_id"},
+	SumResourceAttributeTable:          {1, "sum_id"},
+	SumScopeAttributeTable:             {1, "sum_id"},
+	SumDatapointAttributeTable:         {2, "sum_id"},
+	SumDatapointExemplarTable:          {2, "sum_id"},
+	SumDataPointExemplarAttributeTable: {3, "sum_id"},
+
+	HistogramTable:                           {0, "histogram_id"},
+	HistogramDatapointTable:                  {1, "histogram_id"},
+	HistogramResourceAttributeTable:          {1, "histogram_id"},
+	HistogramScopeAttributeTable:             {1, "histogram_id"},
+	HistogramDatapointAttributeTable:         {2, "histogram_id"},
+	HistogramBucketCountsTable:               {2, "histogram_id"},
+	HistogramExplicitBoundsTable:             {2, "histogram_id"},
+	HistogramDatapointExemplarTable:          {2, "histogram_id"},
+	HistogramDataPointExemplarAttributeTable: {3, "histogram_id"},
+
+	ExpHistogramTable:                           {0, "histogram_id"},
+	ExpHistogramDatapointTable:                  {1, "histogram_id"},
+	ExpHistogramResourceAttributeTable:          {1, "histogram_id"},
+	ExpHistogramScopeAttributeTable:             {1, "histogram_id"},
+	ExpHistogramDatapointAttributeTable:         {2, "histogram_id"},
+	ExpHistogramPositiveBucketCountsTable:       {2, "histogram_id"},
+	ExpHistogramNegativeBucketCountsTable:       {2, "histogram_id"},
+	ExpHistogramDatapointExemplarTable:          {2, "histogram_id"},
+	ExpHistogramDataPointExemplarAttributeTable: {3, "histogram_id"},
+
+	SummaryTable:                       {0, "summary_id"},
+	SummaryDatapointTable:              {1, "summary_id"},
+	SummaryResourceAttributeTable:      {1, "summary_id"},
+	SummaryScopeAttributeTable:         {1, "summary_id"},
+	SummaryDatapointAttributeTable:     {2, "summary_id"},
+	SummaryDatapointQuantileValueTable: {2, "summary_id"},
+}
+
+// parentFirst - whether the tables are written in parent-first order
+//
+//	@receiver kiwriter
+//	@return bool
+func (kiwriter *KiWriter) parentFirst() bool {
+	return kiwriter.cfg.WriteOrdering.Mode == WriteOrderingParentFirst
+}
+
+// writeOrdered - writes the tables level by level, the tables of one level concurrently.
+// Rows whose root record failed at an earlier level are skipped, or spilled when the
+// root record was spilled, so they are replayed after it. With compensating_delete the
+// rows already written for the failed root records are deleted afterwards.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableDataMap
+//	@return error
+func (kiwriter *KiWriter) writeOrdered(ctx context.Context, tableDataMap *orderedmap.OrderedMap) error {
+	levels := make(map[int][]string)
+	for pair := tableDataMap.Oldest(); pair != nil; pair = pair.Next() {
+		tableName := pair.Key.(string)
+		order, ok := orderedTables[tableName]
+		if !ok {
+			order.level = 1
+		}
+		levels[order.level] = append(levels[order.level], tableName)
+	}
+	levelNumbers := make([]int, 0, len(levels))
+	for level := range levels {
+		levelNumbers = append(levelNumbers, level)
+	}
+	sort.Ints(levelNumbers)
+
+	var (
+		mu     sync.Mutex
+		errs   error
+		failed = make(map[string]bool)
+		spill  = make(map[string]bool)
+	)
+
+	for _, level := range levelNumbers {
+		newFailed := make(map[string]bool)
+		newSpilled := make(map[string]bool)
+
+		wg := &sync.WaitGroup{}
+		for _, tableName := range levels[level] {
+			value, _ := tableDataMap.Get(tableName)
+			data, _ := value.([]any)
+
+			wg.Add(1)
+			go func(tableName string, data []any) {
+				defer wg.Done()
+
+				rootColumn := orderedTables[tableName].rootColumn
+				var toInsert, toSpill []any
+				skipped := 0
+				for _, record := range data {
+					root, ok := recordColumnValue(record, rootColumn)
+					switch {
+					case ok && failed[root]:
+						skipped++
+					case ok && spill[root]:
+						toSpill = append(toSpill, record)
+					default:
+						toInsert = append(toInsert, record)
+					}
+				}
+
+				var tableErrs error
+				if skipped > 0 {
+					kiwriter.logger.Warn("Skipped rows of failed parent records", zap.String("Table", tableName), zap.Int("Record count", skipped))
+				}
+				if len(toSpill) > 0 {
+					if err := kiwriter.spill.write(kiwriter.cfg.Schema, kiwriter.tablePrefix, tableName, toSpill); err != nil {
+						tableErrs = multierr.Append(tableErrs, err)
+					}
+				}
+
+				results := kiwriter.insertChunks(ctx, tableName, toInsert)
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, result := range results {
+					tableErrs = multierr.Append(tableErrs, result.err)
+					if !result.failed && !result.spilled {
+						continue
+					}
+					for _, record := range result.records {
+						root, ok := recordColumnValue(record, rootColumn)
+						if !ok {
+							continue
+						}
+						if result.spilled {
+							newSpilled[root] = true
+						} else {
+							newFailed[root] = true
+						}
+					}
+				}
+				errs = multierr.Append(errs, tableErrs)
+			}(tableName, data)
+		}
+		wg.Wait()
+
+		for root := range newFailed {
+			failed[root] = true
+		}
+		for root := range newSpilled {
+			spill[root] = true
+		}
+	}
+
+	if len(failed) > 0 && kiwriter.cfg.WriteOrdering.CompensatingDelete {
+		errs = multierr.Append(errs, kiwriter.compensatingDelete(ctx, tableDataMap, failed))
+	}
+	return errs
+}
+
+// compensatingDelete - deletes the rows of the failed root records from every table
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableDataMap
+//	@param failed
+//	@return error
+func (kiwriter *KiWriter) compensatingDelete(ctx context.Context, tableDataMap *orderedmap.OrderedMap, failed map[string]bool) error {
+	ids := make([]string, 0, len(failed))
+	for root := range failed {
+		ids = append(ids, "'"+strings.ReplaceAll(root, "'", "''")+"'")
+	}
+	sort.Strings(ids)
+
+	var errs error
+	for pair := tableDataMap.Newest(); pair != nil; pair = pair.Prev() {
+		tableName := pair.Key.(string)
+		rootColumn := orderedTables[tableName].rootColumn
+		if rootColumn == "" {
+			continue
+		}
+		for _, batch := range ChunkBySize(ids, compensatingDeleteBatchSize) {
+			statement := fmt.Sprintf(DeleteRowsByRootID, kiwriter.qualifiedTableName(tableName), rootColumn, strings.Join(batch, ", "))
+			if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+				errs = multierr.Append(errs, err)
+			}
+		}
+	}
+	if errs == nil {
+		kiwriter.logger.Warn("Deleted the rows of failed records", zap.Int("Record count", len(failed)))
+	}
+	return errs
+}
+
+// recordFieldIndexes - the struct field index of a column per record type and column
+var recordFieldIndexes sync.Map
+
+type recordFieldKey struct {
+	recordType reflect.Type
+	column     string
+}
+
+// recordColumnValue - the string value of a column of a record, found through the
+// avro tags of struct records
+//
+//	@param record
+//	@param column
+//	@return string
+//	@return bool
+func recordColumnValue(record any, column string) (string, bool) {
+	if columns, ok := record.(map[string]any); ok {
+		value, ok := columns[column].(string)
+		return value, ok
+	}
+
+	value := reflect.ValueOf(record)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	key := recordFieldKey{value.Type(), column}
+	index, ok := recordFieldIndexes.Load(key)
+	if !ok {
+		index = avroFieldIndex(value.Type(), column)
+		recordFieldIndexes.Store(key, index)
+	}
+	if index == nil || len(index.([]int)) == 0 {
+		return "", false
+	}
+
+	field := value.FieldByIndex(index.([]int))
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", false
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.String {
+		return "", false
+	}
+	return field.String(), true
+}
+
+// avroFieldIndex - the index of the field tagged with a column, searching embedded structs
+//
+//	@param recordType
+//	@param column
+//	@return []int
+func avroFieldIndex(recordType reflect.Type, column string) []int {
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if index := avroFieldIndex(field.Type, column); index != nil {
+				return append([]int{i}, index...)
+			}
+			continue
+		}
+		if field.Tag.Get("avro") == column {
+			return []int{i}
+		}
+	}
+	return nil
+}
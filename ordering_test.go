@@ -0,0 +1,70 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// appendTestSpan appends a span with an attribute, an event and a link
+//
+//	@param spans
+//	@param id
+//	@param name
+func appendTestSpan(spans ptrace.SpanSlice, id byte, name string) {
+	span := spans.AppendEmpty()
+	span.SetTraceID(pcommon.TraceID{id, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	span.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	span.SetName(name)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000001, 0)))
+	span.Attributes().PutStr("span.name", name)
+	event := span.Events().AppendEmpty()
+	event.SetName("exception")
+	event.Attributes().PutStr("span.name", name)
+	link := span.Links().AppendEmpty()
+	link.SetTraceID(pcommon.TraceID{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+	link.SetSpanID(pcommon.SpanID{8, 7, 6, 5, 4, 3, 2, 1})
+	link.Attributes().PutStr("span.name", name)
+}
+
+func TestParentFirstSkipsChildrenOfFailedSpans(t *testing.T) {
+	server := newTestServer(t)
+	server.RejectRows(func(table string, row map[string]any) bool {
+		return table == "otel."+TraceSpanTable && row["name"] == "rejected"
+	})
+	cfg := newTestConfig(server)
+	cfg.WriteOrdering.Mode = WriteOrderingParentFirst
+	exporter, err := NewFactory().CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	// the spans share their span id, only the trace ids differ
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("service.name", "checkout")
+	spans := resourceSpans.ScopeSpans().AppendEmpty().Spans()
+	appendTestSpan(spans, 1, "inserted")
+	appendTestSpan(spans, 2, "rejected")
+
+	if err := exporter.ConsumeTraces(context.Background(), traces); err == nil {
+		t.Error("ConsumeTraces() of a rejected span succeeded")
+	}
+
+	if got := len(server.Rows("otel." + TraceSpanTable)); got != 1 {
+		t.Fatalf("%s has %d rows, want 1", TraceSpanTable, got)
+	}
+	for _, table := range []string{TraceSpanAttributeTable, TraceResourceAttributeTable, TraceScopeAttributeTable, TraceEventAttributeTable, TraceLinkAttributeTable} {
+		if got := len(server.Rows("otel." + table)); got != 1 {
+			t.Errorf("%s has %d rows, want the one of the inserted span", table, got)
+		}
+		column := orderedTables[table].rootColumn
+		assertReferences(t, server, table, column, TraceSpanTable, "id")
+	}
+}
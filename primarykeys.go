@@ -0,0 +1,162 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/am-kinetica/gpudb-api-go/gpudb"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// primaryKeyProperty - the column property Kinetica reports for primary key columns
+const primaryKeyProperty = "primary_key"
+
+// primaryKeyColumns - the primary key of every table written by the exporters. The ids
+// are derived from the content of the records, so with these keys a retried batch
+// updates or skips the rows already written instead of duplicating them.
+var primaryKeyColumns = map[string][]string{
+	LogTable:                  {"log_id"},
+	LogAttributeTable:         {"log_id", "key"},
+	LogResourceAttributeTable: {"log_id", "key"},
+	LogScopeAttributeTable:    {"log_id", "key"},
+
+	TraceSpanTable:              {"id"},
+	TraceSpanAttributeTable:     {"span_record_id", "key"},
+	TraceResourceAttributeTable: {"span_record_id", "key"},
+	TraceScopeAttributeTable:    {"span_record_id", "key"},
+	TraceEventAttributeTable:    {"span_record_id", "event_index", "key"},
+	TraceLinkAttributeTable:     {"span_record_id", "link_index", "key"},
+
+	GaugeTable:                           {"gauge_id"},
+	GaugeDatapointTable:                  {"id"},
+	GaugeResourceAttributeTable:          {"gauge_id", "key"},
+	GaugeScopeAttributeTable:             {"gauge_id", "key"},
+	GaugeDatapointAttributeTable:         {"datapoint_id", "key"},
+	GaugeDatapointExemplarTable:          {"exemplar_id"},
+	GaugeDatapointExemplarAttributeTable: {"exemplar_id", "key"},
+
+	SumTable:                           {"sum_id"},
+	SumDatapointTable:                  {"id"},
+	SumResourceAttributeTable:          {"sum_id", "key"},
+	SumScopeAttributeTable:             {"sum_id", "key"},
+	SumDatapointAttributeTable:         {"datapoint_id", "key"},
+	SumDatapointExemplarTable:          {"exemplar_id"},
+	SumDataPointExemplarAttributeTable: {"exemplar_id", "key"},
+
+	HistogramTable:                           {"histogram_id"},
+	HistogramDatapointTable:                  {"id"},
+	HistogramResourceAttributeTable:          {"histogram_id", "key"},
+	HistogramScopeAttributeTable:             {"histogram_id", "key"},
+	HistogramDatapointAttributeTable:         {"datapoint_id", "key"},
+	HistogramBucketCountsTable:               {"count_id"},
+	HistogramExplicitBoundsTable:             {"bound_id"},
+	HistogramDatapointExemplarTable:          {"exemplar_id"},
+	HistogramDataPointExemplarAttributeTable: {"exemplar_id", "key"},
+
+	ExpHistogramTable:                           {"histogram_id"},
+	ExpHistogramDatapointTable:                  {"id"},
+	ExpHistogramResourceAttributeTable:          {"histogram_id", "key"},
+	ExpHistogramScopeAttributeTable:             {"histogram_id", "key"},
+	ExpHistogramDatapointAttributeTable:         {"datapoint_id", "key"},
+	ExpHistogramPositiveBucketCountsTable:       {"count_id"},
+	ExpHistogramNegativeBucketCountsTable:       {"count_id"},
+	ExpHistogramDatapointExemplarTable:          {"exemplar_id"},
+	ExpHistogramDataPointExemplarAttributeTable: {"exemplar_id", "key"},
+
+	SummaryTable:                       {"summary_id"},
+	SummaryDatapointTable:              {"id"},
+	SummaryResourceAttributeTable:      {"summary_id", "key"},
+	SummaryScopeAttributeTable:         {"summary_id", "key"},
+	SummaryDatapointAttributeTable:     {"datapoint_id", "key"},
+	SummaryDatapointQuantileValueTable: {"quantile_id"},
+}
+
+// signalTables - the tables written by the exporter of every signal
+var signalTables = map[string][]string{
+	SignalLogs: {LogTable, LogAttributeTable, LogResourceAttributeTable, LogScopeAttributeTable},
+	SignalTraces: {TraceSpanTable, TraceSpanAttributeTable, TraceResourceAttributeTable, TraceScopeAttributeTable,
+		TraceEventAttributeTable, TraceLinkAttributeTable},
+	SignalMetrics: {GaugeTable, GaugeDatapointTable, GaugeResourceAttributeTable, GaugeScopeAttributeTable,
+		GaugeDatapointAttributeTable, GaugeDatapointExemplarTable, GaugeDatapointExemplarAttributeTable,
+		SumTable, SumDatapointTable, SumResourceAttributeTable, SumScopeAttributeTable,
+		SumDatapointAttributeTable, SumDatapointExemplarTable, SumDataPointExemplarAttributeTable,
+		HistogramTable, HistogramDatapointTable, HistogramResourceAttributeTable, HistogramScopeAttributeTable,
+		HistogramDatapointAttributeTable, HistogramBucketCountsTable, HistogramExplicitBoundsTable,
+		HistogramDatapointExemplarTable, HistogramDataPointExemplarAttributeTable,
+		ExpHistogramTable, ExpHistogramDatapointTable, ExpHistogramResourceAttributeTable, ExpHistogramScopeAttributeTable,
+		ExpHistogramDatapointAttributeTable, ExpHistogramPositiveBucketCountsTable, ExpHistogramNegativeBucketCountsTable,
+		ExpHistogramDatapointExemplarTable, ExpHistogramDataPointExemplarAttributeTable,
+		SummaryTable, SummaryDatapointTable, SummaryResourceAttributeTable, SummaryScopeAttributeTable,
+		SummaryDatapointAttributeTable, SummaryDatapointQuantileValueTable},
+}
+
+// insertOptions - the insert options of a table, update_on_existing_pk or
+// ignore_existing_pk as configured. A rollup row always replaces the row of its bucket.
+//
+//	@receiver kiwriter
+//	@param tableName
+//	@return *gpudb.InsertRecordsOptions
+func (kiwriter *KiWriter) insertOptions(tableName string) *gpudb.InsertRecordsOptions {
+	options := gpudb.NewDefaultInsertRecordsOptions()
+	if kiwriter.cfg.isRollupTable(tableName) {
+		options.UpdateOnExistingPk = true
+		return options
+	}
+	options.UpdateOnExistingPk = kiwriter.cfg.UpdateOnExistingPk
+	options.IgnoreExistingPk = kiwriter.cfg.IgnoreExistingPk
+	return options
+}
+
+// checkPrimaryKeys - fails when update_on_existing_pk or ignore_existing_pk is set and
+// a table of the signal lacks the expected primary key, retried rows would be duplicated
+// in the table. Tables that cannot be shown are skipped, they may not exist yet.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param signal
+//	@return error
+func (kiwriter *KiWriter) checkPrimaryKeys(ctx context.Context, signal string) error {
+	if !kiwriter.cfg.UpdateOnExistingPk && !kiwriter.cfg.IgnoreExistingPk {
+		return nil
+	}
+
+	var errs error
+
+	for _, tableName := range signalTables[signal] {
+		finalTable := kiwriter.finalTableName(tableName)
+		showTableResult, err := kiwriter.Db.ShowTableRaw(ctx, finalTable)
+		if err != nil || len(showTableResult.Properties) == 0 {
+			kiwriter.logger.Debug("Cannot fetch table properties", zap.String("Table", finalTable), zap.Error(err))
+			continue
+		}
+
+		var missing []string
+		for _, column := range primaryKeyColumns[tableName] {
+			if !hasColumnProperty(showTableResult.Properties[0], column, primaryKeyProperty) {
+				missing = append(missing, column)
+			}
+		}
+		if len(missing) > 0 {
+			errs = multierr.Append(errs, fmt.Errorf("table %s lacks the primary key columns %s of PRIMARY KEY (%s), create it with the key or disable update_on_existing_pk and ignore_existing_pk",
+				finalTable, strings.Join(missing, ", "), strings.Join(primaryKeyColumns[tableName], ", ")))
+		}
+	}
+	return errs
+}
+
+// hasColumnProperty - whether a column of a table has a property
+//
+//	@param properties
+//	@param column
+//	@param property
+//	@return bool
+func hasColumnProperty(properties map[string][]string, column string, property string) bool {
+	for _, p := range properties[column] {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
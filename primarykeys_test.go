@@ -0,0 +1,53 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestPrimaryKeysRequiredByTheInsertOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       bool
+		updatePk   bool
+		wantFailed bool
+	}{
+		{"keys", true, true, false},
+		{"missing keys", false, true, true},
+		{"missing keys without the options", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			if !tt.keys {
+				server = kineticatest.NewServer(t)
+				for _, table := range signalTables[SignalLogs] {
+					if err := server.CreateTable("otel."+table, kineticatest.TypeSchemaOf(tableRecords[table]), nil); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			cfg := newTestConfig(server)
+			cfg.UpdateOnExistingPk = tt.updatePk
+			exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = exporter.Start(context.Background(), nil)
+			if (err != nil) != tt.wantFailed {
+				t.Fatalf("Start() = %v, want failed %v", err, tt.wantFailed)
+			}
+			if err != nil && !strings.Contains(err.Error(), "otel."+LogTable) {
+				t.Errorf("Start() = %v, want the table lacking the primary key", err)
+			}
+			if err := exporter.Shutdown(context.Background()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package kineticaotelexporter
+
+import (
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	semconv "go.opentelemetry.io/collector/semconv/v1.16.0"
+)
+
+const (
+	// Label names used by Prometheus for the scrape target
+	PrometheusJobLabel      = "job"
+	PrometheusInstanceLabel = "instance"
+
+	// Datapoint labels added by the prometheus receiver for the instrumentation scope
+	otelScopeLabelPrefix = "otel_scope_"
+
+	prometheusCounterSuffix = "_total"
+	prometheusBucketSuffix  = "_bucket"
+)
+
+// normalizePrometheusMetricName - maps a metric name to a Prometheus-compatible name
+// so it matches the series name users query with PromQL
+//
+//	@param metric
+//	@return string
+func normalizePrometheusMetricName(metric pmetric.Metric) string {
+	name := sanitizePrometheusName(metric.Name())
+
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		if metric.Sum().IsMonotonic() && !strings.HasSuffix(name, prometheusCounterSuffix) {
+			name += prometheusCounterSuffix
+		}
+	case pmetric.MetricTypeHistogram, pmetric.MetricTypeExponentialHistogram:
+		// buckets are kept in their own tables, the metric row carries the family name
+		name = strings.TrimSuffix(name, prometheusBucketSuffix)
+	}
+
+	return name
+}
+
+// sanitizePrometheusName - replaces every character that is not valid in a
+// Prometheus metric name with an underscore
+//
+//	@param name
+//	@return string
+func sanitizePrometheusName(name string) string {
+	if name == "" {
+		return name
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(name) + 1)
+	for i, r := range name {
+		if i == 0 && unicode.IsDigit(r) {
+			sb.WriteRune('_')
+		}
+		if r == '_' || r == ':' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// prometheusJobInstance - the job and instance labels of a resource, read from
+// the job/instance attributes or derived from the service attributes the same
+// way the Prometheus exporters do
+//
+//	@param resAttr
+//	@return job
+//	@return instance
+func prometheusJobInstance(resAttr pcommon.Map) (job string, instance string) {
+	if v, ok := resAttr.Get(PrometheusJobLabel); ok {
+		job = v.AsString()
+	} else if serviceName, ok := resAttr.Get(semconv.AttributeServiceName); ok {
+		job = serviceName.AsString()
+		if serviceNamespace, ok := resAttr.Get(semconv.AttributeServiceNamespace); ok && serviceNamespace.AsString() != "" {
+			job = serviceNamespace.AsString() + "/" + job
+		}
+	}
+
+	if v, ok := resAttr.Get(PrometheusInstanceLabel); ok {
+		instance = v.AsString()
+	} else if instanceID, ok := resAttr.Get(semconv.AttributeServiceInstanceID); ok {
+		instance = instanceID.AsString()
+	}
+
+	return job, instance
+}
+
+// isPrometheusNoiseAttribute - datapoint attributes dropped in Prometheus normalization mode
+//
+//	@param key
+//	@return bool
+func isPrometheusNoiseAttribute(key string) bool {
+	return strings.HasPrefix(key, otelScopeLabelPrefix)
+}
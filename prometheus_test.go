@@ -0,0 +1,92 @@
+package kineticaotelexporter
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestNormalizePrometheusMetricName(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric func(metric pmetric.Metric)
+		want   string
+	}{
+		{"monotonic sum gets _total", func(metric pmetric.Metric) {
+			metric.SetName("http.server.requests")
+			metric.SetEmptySum().SetIsMonotonic(true)
+		}, "http_server_requests_total"},
+		{"monotonic sum keeps its _total", func(metric pmetric.Metric) {
+			metric.SetName("requests_total")
+			metric.SetEmptySum().SetIsMonotonic(true)
+		}, "requests_total"},
+		{"non-monotonic sum", func(metric pmetric.Metric) {
+			metric.SetName("queue.size")
+			metric.SetEmptySum().SetIsMonotonic(false)
+		}, "queue_size"},
+		{"gauge", func(metric pmetric.Metric) {
+			metric.SetName("cpu.temperature")
+			metric.SetEmptyGauge()
+		}, "cpu_temperature"},
+		{"histogram loses _bucket", func(metric pmetric.Metric) {
+			metric.SetName("latency_bucket")
+			metric.SetEmptyHistogram()
+		}, "latency"},
+		{"exponential histogram loses _bucket", func(metric pmetric.Metric) {
+			metric.SetName("latency_bucket")
+			metric.SetEmptyExponentialHistogram()
+		}, "latency"},
+		{"gauge keeps _bucket", func(metric pmetric.Metric) {
+			metric.SetName("latency_bucket")
+			metric.SetEmptyGauge()
+		}, "latency_bucket"},
+		{"leading digit", func(metric pmetric.Metric) {
+			metric.SetName("2xx.responses")
+			metric.SetEmptyGauge()
+		}, "_2xx_responses"},
+		{"colons are kept, other runes replaced", func(metric pmetric.Metric) {
+			metric.SetName("job:rate-5m/é")
+			metric.SetEmptyGauge()
+		}, "job:rate_5m__"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := pmetric.NewMetric()
+			tt.metric(metric)
+			if got := normalizePrometheusMetricName(metric); got != tt.want {
+				t.Errorf("normalizePrometheusMetricName(%q) = %q, want %q", metric.Name(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrometheusJobInstance(t *testing.T) {
+	tests := []struct {
+		name         string
+		attributes   map[string]any
+		wantJob      string
+		wantInstance string
+	}{
+		{"service name", map[string]any{"service.name": "checkout"}, "checkout", ""},
+		{"service namespace and name", map[string]any{"service.namespace": "shop", "service.name": "checkout"}, "shop/checkout", ""},
+		{"empty service namespace", map[string]any{"service.namespace": "", "service.name": "checkout"}, "checkout", ""},
+		{"service namespace without name", map[string]any{"service.namespace": "shop"}, "", ""},
+		{"service instance id", map[string]any{"service.name": "checkout", "service.instance.id": "checkout-7d9f8"}, "checkout", "checkout-7d9f8"},
+		{"job and instance attributes win", map[string]any{"job": "scrape", "instance": "10.0.0.1:9090",
+			"service.name": "checkout", "service.instance.id": "checkout-7d9f8"}, "scrape", "10.0.0.1:9090"},
+		{"no attributes", map[string]any{}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes := pcommon.NewMap()
+			if err := attributes.FromRaw(tt.attributes); err != nil {
+				t.Fatal(err)
+			}
+			job, instance := prometheusJobInstance(attributes)
+			if job != tt.wantJob || instance != tt.wantInstance {
+				t.Errorf("prometheusJobInstance(%v) = %q, %q, want %q, %q", tt.attributes, job, instance, tt.wantJob, tt.wantInstance)
+			}
+		})
+	}
+}
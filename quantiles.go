@@ -0,0 +1,126 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SQL statement to add a pivoted quantile column to the summary datapoint table
+const AddSummaryQuantileColumn string = `ALTER TABLE %s ADD %s DOUBLE`
+
+// quantileColumnName - column name for a quantile, 0.5 -> p50, 0.99 -> p99, 0.999 -> p999
+//
+//	@param quantile
+//	@return string
+//	@return error
+func quantileColumnName(quantile float64) (string, error) {
+	if math.IsNaN(quantile) || quantile < 0 || quantile > 1 {
+		return "", fmt.Errorf("invalid summary quantile %v, must be between 0 and 1", quantile)
+	}
+	if quantile == 1 {
+		return "p100", nil
+	}
+
+	digits := strings.TrimPrefix(strconv.FormatFloat(quantile, 'f', -1, 64), "0")
+	digits = strings.TrimPrefix(digits, ".")
+	for len(digits) < 2 {
+		digits += "0"
+	}
+	return "p" + digits, nil
+}
+
+// summaryQuantileColumns - maps each configured quantile to its column name
+//
+//	@param quantiles
+//	@return map[float64]string
+//	@return error
+func summaryQuantileColumns(quantiles []float64) (map[float64]string, error) {
+	columns := make(map[float64]string, len(quantiles))
+	names := make(map[string]bool, len(quantiles))
+	for _, quantile := range quantiles {
+		name, err := quantileColumnName(quantile)
+		if err != nil {
+			return nil, err
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate summary quantile %v", quantile)
+		}
+		names[name] = true
+		columns[quantile] = name
+	}
+	return columns, nil
+}
+
+// quantileRecord - the datapoint as a record map with a column for every configured quantile,
+// quantiles missing from the datapoint are stored as null
+//
+//	@receiver datapoint
+//	@param columns
+//	@return map[string]any
+func (datapoint SummaryDatapoint) quantileRecord(columns []string) map[string]any {
+	record := map[string]any{
+		"summary_id":               datapoint.SummaryID,
+		"id":                       datapoint.ID,
+		"start_time_unix":          datapoint.StartTimeUnix,
+		"time_unix":                datapoint.TimeUnix,
+		"count":                    datapoint.Count,
+		"data_sum":                 datapoint.Sum,
+		"flags":                    datapoint.Flags,
+		"dropped_attributes_count": datapoint.DroppedAttributesCount,
+	}
+	for _, column := range columns {
+		if value, ok := datapoint.Quantiles[column]; ok {
+			record[column] = value
+		} else {
+			record[column] = nil
+		}
+	}
+	return record
+}
+
+// ensureSummaryQuantileColumns adds the configured quantile columns missing from the summary datapoint table
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param columns
+//	@return error
+func (kiwriter *KiWriter) ensureSummaryQuantileColumns(ctx context.Context, columns []string) error {
+	table := kiwriter.finalTableName(SummaryDatapointTable)
+	existing, err := kiwriter.tableColumnNames(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, column := range columns {
+		if existing[column] {
+			continue
+		}
+		kiwriter.logger.Info("Adding summary quantile column", zap.String("Table", table), zap.String("Column", column))
+		statement := fmt.Sprintf(AddSummaryQuantileColumn, kiwriter.qualifiedTableName(SummaryDatapointTable), column)
+		if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+// summaryQuantileColumnNames - the configured quantile column names in config order
+//
+//	@receiver kiwriter
+//	@return []string
+func (kiwriter *KiWriter) summaryQuantileColumnNames() []string {
+	columns := make([]string, 0, len(kiwriter.cfg.SummaryQuantiles))
+	for _, quantile := range kiwriter.cfg.SummaryQuantiles {
+		if name, err := quantileColumnName(quantile); err == nil {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
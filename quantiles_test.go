@@ -0,0 +1,87 @@
+package kineticaotelexporter
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestQuantileColumnName(t *testing.T) {
+	tests := []struct {
+		quantile float64
+		want     string
+		wantErr  bool
+	}{
+		{quantile: 0.5, want: "p50"},
+		{quantile: 0.9, want: "p90"},
+		{quantile: 0.95, want: "p95"},
+		{quantile: 0.99, want: "p99"},
+		{quantile: 0.999, want: "p999"},
+		{quantile: 0.05, want: "p05"},
+		{quantile: 0.001, want: "p001"},
+		{quantile: 0, want: "p00"},
+		{quantile: 1, want: "p100"},
+		{quantile: -0.1, wantErr: true},
+		{quantile: 1.5, wantErr: true},
+		{quantile: math.NaN(), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := quantileColumnName(tt.quantile)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("quantileColumnName(%v) = %q, %v, want %q and an error %v", tt.quantile, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSummaryQuantileColumnsRejectsDuplicates(t *testing.T) {
+	if _, err := summaryQuantileColumns([]float64{0.5, 0.99, 0.50}); err == nil {
+		t.Error("summaryQuantileColumns() of a quantile configured twice succeeded")
+	}
+}
+
+func TestQuantileRecord(t *testing.T) {
+	datapoint := SummaryDatapoint{
+		SummaryID:              "summary",
+		ID:                     "datapoint",
+		StartTimeUnix:          1,
+		TimeUnix:               2,
+		Count:                  3,
+		Sum:                    4.5,
+		Flags:                  1,
+		DroppedAttributesCount: 2,
+		Quantiles:              map[string]float64{"p50": 1.5, "p999": 9.5},
+	}
+	base := map[string]any{
+		"summary_id":               "summary",
+		"id":                       "datapoint",
+		"start_time_unix":          int64(1),
+		"time_unix":                int64(2),
+		"count":                    int64(3),
+		"data_sum":                 4.5,
+		"flags":                    1,
+		"dropped_attributes_count": 2,
+	}
+	tests := []struct {
+		name      string
+		columns   []string
+		quantiles map[string]any
+	}{
+		{"no quantile columns", nil, map[string]any{}},
+		{"configured quantiles", []string{"p50", "p999"}, map[string]any{"p50": 1.5, "p999": 9.5}},
+		{"quantiles missing from the datapoint are null", []string{"p50", "p99"}, map[string]any{"p50": 1.5, "p99": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make(map[string]any, len(base)+len(tt.quantiles))
+			for column, value := range base {
+				want[column] = value
+			}
+			for column, value := range tt.quantiles {
+				want[column] = value
+			}
+			if got := datapoint.quantileRecord(tt.columns); !reflect.DeepEqual(got, want) {
+				t.Errorf("quantileRecord(%v) = %v, want %v", tt.columns, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package kineticaotelexporter
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// recordIDNamespace - the name space of the name based (version 5) UUIDs used as record IDs
+var recordIDNamespace = uuid.MustParse("5d0e4e0c-6d1b-4a53-9a53-6b696e657469")
+
+// recordIDBuilder - builds a record ID from the content of a record, so the same
+// record sent again, e.g. when a batch is retried, gets the same ID
+type recordIDBuilder struct {
+	buf []byte
+}
+
+// newRecordIDBuilder
+//
+//	@param kind
+//	@return *recordIDBuilder
+func newRecordIDBuilder(kind string) *recordIDBuilder {
+	return (&recordIDBuilder{}).str(kind)
+}
+
+// clone - a copy of the builder to extend without changing the original
+//
+//	@receiver b
+//	@return *recordIDBuilder
+func (b *recordIDBuilder) clone() *recordIDBuilder {
+	return &recordIDBuilder{buf: append(make([]byte, 0, len(b.buf)+64), b.buf...)}
+}
+
+// str adds a length prefixed string
+//
+//	@receiver b
+//	@param s
+//	@return *recordIDBuilder
+func (b *recordIDBuilder) str(s string) *recordIDBuilder {
+	b.buf = binary.AppendUvarint(b.buf, uint64(len(s)))
+	b.buf = append(b.buf, s...)
+	return b
+}
+
+// int adds an integer
+//
+//	@receiver b
+//	@param i
+//	@return *recordIDBuilder
+func (b *recordIDBuilder) int(i int64) *recordIDBuilder {
+	b.buf = binary.AppendVarint(b.buf, i)
+	return b
+}
+
+// value adds the type and string form of an attribute value
+//
+//	@receiver b
+//	@param v
+//	@return *recordIDBuilder
+func (b *recordIDBuilder) value(v pcommon.Value) *recordIDBuilder {
+	return b.int(int64(v.Type())).str(v.AsString())
+}
+
+// attributes adds the attributes sorted by key
+//
+//	@receiver b
+//	@param attributes
+//	@return *recordIDBuilder
+func (b *recordIDBuilder) attributes(attributes pcommon.Map) *recordIDBuilder {
+	keys := make([]string, 0, attributes.Len())
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+
+	b.int(int64(len(keys)))
+	for _, key := range keys {
+		value, _ := attributes.Get(key)
+		b.str(key).value(value)
+	}
+	return b
+}
+
+// id - the record ID as a UUID string
+//
+//	@receiver b
+//	@return string
+func (b *recordIDBuilder) id() string {
+	return uuid.NewSHA1(recordIDNamespace, b.buf).String()
+}
+
+// logRecordID - the ID of a log record, derived from its resource, scope, timestamps,
+// severity, body, attributes and trace context
+//
+//	@param resource
+//	@param scope
+//	@param logRecord
+//	@return string
+func logRecordID(resource pcommon.Resource, scope pcommon.InstrumentationScope, logRecord plog.LogRecord) string {
+	traceID := logRecord.TraceID()
+	spanID := logRecord.SpanID()
+	return newRecordIDBuilder(LogTable).
+		attributes(resource.Attributes()).
+		str(scope.Name()).
+		str(scope.Version()).
+		int(int64(logRecord.Timestamp())).
+		int(int64(logRecord.ObservedTimestamp())).
+		int(int64(logRecord.SeverityNumber())).
+		str(logRecord.SeverityText()).
+		value(logRecord.Body()).
+		attributes(logRecord.Attributes()).
+		str(string(traceID[:])).
+		str(string(spanID[:])).
+		id()
+}
+
+// spanRecordID - the ID of a span, derived from its trace and span ID. Spans without
+// either get a random ID.
+//
+//	@param traceID
+//	@param spanID
+//	@return string
+func spanRecordID(traceID string, spanID string) string {
+	if traceID == "" && spanID == "" {
+		return uuid.New().String()
+	}
+	return newRecordIDBuilder(TraceSpanTable).str(traceID).str(spanID).id()
+}
+
+// seriesIDBuilder - the builder of the datapoint IDs of one metric, identifying the series
+// by metric type, name, resource and scope
+//
+//	@param metricType
+//	@param name
+//	@param resource
+//	@param scope
+//	@return *recordIDBuilder
+func seriesIDBuilder(metricType string, name string, resource pcommon.Resource, scope pcommon.InstrumentationScope) *recordIDBuilder {
+	return newRecordIDBuilder(metricType).
+		str(name).
+		attributes(resource.Attributes()).
+		str(scope.Name()).
+		str(scope.Version())
+}
+
+// datapoint is implemented by the datapoints of every metric type
+type datapoint interface {
+	Attributes() pcommon.Map
+	StartTimestamp() pcommon.Timestamp
+	Timestamp() pcommon.Timestamp
+}
+
+// datapointSlice is implemented by the datapoint slices of every metric type
+type datapointSlice[T datapoint] interface {
+	Len() int
+	At(i int) T
+}
+
+// datapointRecordIDs - the ID of every datapoint, derived from the series, the datapoint
+// attributes and timestamps
+//
+//	@param series
+//	@param datapoints
+//	@return []string
+func datapointRecordIDs[T datapoint](series *recordIDBuilder, datapoints datapointSlice[T]) []string {
+	ids := make([]string, datapoints.Len())
+	for i := range ids {
+		datapoint := datapoints.At(i)
+		ids[i] = series.clone().
+			attributes(datapoint.Attributes()).
+			int(int64(datapoint.StartTimestamp())).
+			int(int64(datapoint.Timestamp())).
+			id()
+	}
+	return ids
+}
+
+// metricRecordID - the ID of the metric row, derived from the IDs of its datapoints
+//
+//	@param series
+//	@param datapointIDs
+//	@return string
+func metricRecordID(series *recordIDBuilder, datapointIDs []string) string {
+	b := series.clone().str("metric").int(int64(len(datapointIDs)))
+	for _, id := range datapointIDs {
+		b.str(id)
+	}
+	return b.id()
+}
+
+// childRecordID - the ID of the n-th child row of a kind, e.g. the exemplars or bucket
+// counts of a datapoint
+//
+//	@param parentID
+//	@param kind
+//	@param n
+//	@return string
+func childRecordID(parentID string, kind string, n int) string {
+	return newRecordIDBuilder(kind).str(parentID).int(int64(n)).id()
+}
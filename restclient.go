@@ -0,0 +1,167 @@
+package kineticaotelexporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hamba/avro"
+)
+
+// restResponse - the JSON response wrapper of the Kinetica endpoints
+type restResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	DataType string `json:"data_type"`
+}
+
+// errUnknownStatus - a response whose status is neither OK nor ERROR
+var errUnknownStatus = errors.New("unknown response status")
+
+// responseError - a response that is not a Kinetica response, e.g. the error page of a
+// proxy in front of Kinetica
+type responseError struct {
+	endpoint   string
+	statusCode int
+	status     string
+	err        error
+}
+
+// Error
+//
+//	@receiver e
+//	@return string
+func (e *responseError) Error() string {
+	return fmt.Sprintf("cannot decode %s response with HTTP status %s: %v", e.endpoint, e.status, e.err)
+}
+
+// Unwrap
+//
+//	@receiver e
+//	@return error
+func (e *responseError) Unwrap() error {
+	return e.err
+}
+
+// restClient - posts requests to the Kinetica endpoints, used for the binary record
+// inserts and the insert modes gpudb-api-go has no support for
+type restClient struct {
+	host     string
+	username string
+	password string
+	client   *http.Client
+}
+
+// newRestClient - the client of the Kinetica endpoints
+//
+//	@param cfg
+//	@return *restClient
+func newRestClient(cfg Config) *restClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.BypassSslCertCheck {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &restClient{
+		host:     strings.TrimSuffix(cfg.Host, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Transport: transport},
+	}
+}
+
+// post sends a JSON body to an endpoint and fails when Kinetica does not answer OK
+//
+//	@receiver c
+//	@param ctx
+//	@param endpoint
+//	@param query
+//	@param body
+//	@return error
+func (c *restClient) post(ctx context.Context, endpoint string, query url.Values, body []byte) error {
+	target := c.host + endpoint
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	var result restResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return &responseError{endpoint: endpoint, statusCode: response.StatusCode, status: response.Status, err: err}
+	}
+	if result.Status != "OK" && result.Status != "ERROR" {
+		return &responseError{endpoint: endpoint, statusCode: response.StatusCode, status: response.Status, err: errUnknownStatus}
+	}
+	if result.Status != "OK" {
+		if result.Message == "" {
+			result.Message = response.Status
+		}
+		return errors.New(result.Message)
+	}
+	return nil
+}
+
+// postAvro sends an Avro encoded request to an endpoint and fails when Kinetica answers
+// with an error. The response wrapper starts with the status and the message.
+//
+//	@receiver c
+//	@param ctx
+//	@param endpoint
+//	@param body
+//	@return error
+func (c *restClient) postAvro(ctx context.Context, endpoint string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	if c.username != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	reader := avro.NewReader(nil, 0).Reset(responseBody)
+	status := reader.ReadString()
+	message := reader.ReadString()
+	switch {
+	case reader.Error != nil:
+		return &responseError{endpoint: endpoint, statusCode: response.StatusCode, status: response.Status, err: reader.Error}
+	case status == "ERROR":
+		if message == "" {
+			message = response.Status
+		}
+		return errors.New(message)
+	case status != "OK":
+		return &responseError{endpoint: endpoint, statusCode: response.StatusCode, status: response.Status, err: errUnknownStatus}
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SQL statements to delete expired rows
+const (
+	DeleteExpiredRows string = `DELETE FROM %s WHERE %s < %d`
+
+	DeleteExpiredChildRows string = `DELETE FROM %s WHERE %s IN (SELECT %s FROM %s WHERE %s < %d)`
+
+	DeleteExpiredParentRows string = `DELETE FROM %s WHERE %s IN (SELECT %s FROM %s WHERE %s < %d) AND %s NOT IN (SELECT %s FROM %s WHERE %s >= %d)`
+
+	// SQL statements to manage the partitions and the TTL of the tables
+	AddTimePartition    string = `ALTER TABLE %s ADD PARTITION "%s" MIN(%d) MAX(%d)`
+	DeleteTimePartition string = `ALTER TABLE %s DELETE PARTITION "%s"`
+	SetTableTTL         string = `ALTER TABLE %s SET TTL %d`
+)
+
+// retentionColumn - a table and the column referencing the expiring rows
+type retentionColumn struct {
+	table  string
+	column string
+}
+
+// retentionTable - how the rows of a log, span or metric expire. Rows of timeTable
+// older than the retention are deleted together with the child rows referencing
+// them. For metrics the metric, resource and scope rows of expired datapoints are
+// deleted when none of their datapoints is live. Rows without any datapoint, e.g. of a
+// metric whose datapoints are still being inserted, are never deleted.
+type retentionTable struct {
+	signal     string
+	timeTable  string
+	timeColumn string
+	timeUnit   time.Duration
+	idColumn   string
+	children   []retentionColumn
+	roots      []retentionColumn
+}
+
+// retentionTables - the expiring records of every signal, by the table used to
+// override their retention
+var retentionTables = map[string]retentionTable{
+	// the exporter stores log timestamps in seconds
+	LogTable: {SignalLogs, LogTable, "time_unix_nano", time.Second, "log_id",
+		[]retentionColumn{{LogAttributeTable, "log_id"}, {LogResourceAttributeTable, "log_id"}, {LogScopeAttributeTable, "log_id"}},
+		nil},
+
+	TraceSpanTable: {SignalTraces, TraceSpanTable, "start_time_unix_nano", time.Nanosecond, "id",
+		[]retentionColumn{{TraceSpanAttributeTable, "span_record_id"}, {TraceResourceAttributeTable, "span_record_id"}, {TraceScopeAttributeTable, "span_record_id"},
+			{TraceEventAttributeTable, "span_record_id"}, {TraceLinkAttributeTable, "span_record_id"}},
+		nil},
+
+	GaugeTable: {SignalMetrics, GaugeDatapointTable, "time_unix", time.Millisecond, "id",
+		[]retentionColumn{{GaugeDatapointAttributeTable, "datapoint_id"}, {GaugeDatapointExemplarTable, "datapoint_id"},
+			{GaugeDatapointExemplarAttributeTable, "datapoint_id"}},
+		[]retentionColumn{{GaugeResourceAttributeTable, "gauge_id"}, {GaugeScopeAttributeTable, "gauge_id"}, {GaugeTable, "gauge_id"}}},
+
+	SumTable: {SignalMetrics, SumDatapointTable, "time_unix", time.Millisecond, "id",
+		[]retentionColumn{{SumDatapointAttributeTable, "datapoint_id"}, {SumDatapointExemplarTable, "datapoint_id"},
+			{SumDataPointExemplarAttributeTable, "datapoint_id"}},
+		[]retentionColumn{{SumResourceAttributeTable, "sum_id"}, {SumScopeAttributeTable, "sum_id"}, {SumTable, "sum_id"}}},
+
+	HistogramTable: {SignalMetrics, HistogramDatapointTable, "time_unix", time.Millisecond, "id",
+		[]retentionColumn{{HistogramDatapointAttributeTable, "datapoint_id"}, {HistogramBucketCountsTable, "datapoint_id"},
+			{HistogramExplicitBoundsTable, "datapoint_id"}, {HistogramDatapointExemplarTable, "datapoint_id"},
+			{HistogramDataPointExemplarAttributeTable, "datapoint_id"}},
+		[]retentionColumn{{HistogramResourceAttributeTable, "histogram_id"}, {HistogramScopeAttributeTable, "histogram_id"}, {HistogramTable, "histogram_id"}}},
+
+	ExpHistogramTable: {SignalMetrics, ExpHistogramDatapointTable, "time_unix", time.Millisecond, "id",
+		[]retentionColumn{{ExpHistogramDatapointAttributeTable, "datapoint_id"}, {ExpHistogramPositiveBucketCountsTable, "datapoint_id"},
+			{ExpHistogramNegativeBucketCountsTable, "datapoint_id"}, {ExpHistogramDatapointExemplarTable, "datapoint_id"},
+			{ExpHistogramDataPointExemplarAttributeTable, "datapoint_id"}},
+		[]retentionColumn{{ExpHistogramResourceAttributeTable, "histogram_id"}, {ExpHistogramScopeAttributeTable, "histogram_id"}, {ExpHistogramTable, "histogram_id"}}},
+
+	SummaryTable: {SignalMetrics, SummaryDatapointTable, "time_unix", time.Millisecond, "id",
+		[]retentionColumn{{SummaryDatapointAttributeTable, "datapoint_id"}, {SummaryDatapointQuantileValueTable, "datapoint_id"}},
+		[]retentionColumn{{SummaryResourceAttributeTable, "summary_id"}, {SummaryScopeAttributeTable, "summary_id"}, {SummaryTable, "summary_id"}}},
+}
+
+// retentionJob periodically deletes the rows of one signal older than their retention
+type retentionJob struct {
+	cfg    RetentionConfig
+	signal string
+	writer *KiWriter
+	logger *zap.Logger
+
+	// partitions holds the start of the partitions of every time table the job added or
+	// tried to add, adding fails for the partitions added before a restart
+	partitions map[string]map[int64]bool
+	// ttls holds the tables whose TTL is set
+	ttls map[string]bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newRetentionJob
+//
+//	@param cfg
+//	@param signal
+//	@param writer
+//	@param logger
+//	@return *retentionJob
+func newRetentionJob(cfg RetentionConfig, signal string, writer *KiWriter, logger *zap.Logger) *retentionJob {
+	return &retentionJob{
+		cfg:        cfg,
+		signal:     signal,
+		writer:     writer,
+		logger:     logger,
+		partitions: make(map[string]map[int64]bool),
+		ttls:       make(map[string]bool),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// start the periodic deletion of expired rows
+//
+//	@receiver r
+//	@param ctx
+//	@return error
+func (r *retentionJob) start(ctx context.Context) error {
+	if err := r.prepare(ctx, time.Now()); err != nil {
+		r.logger.Error("Retention failed to prepare the tables", zap.Error(err))
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case now := <-ticker.C:
+				if err := r.apply(context.Background(), now); err != nil {
+					r.logger.Error("Retention failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown stops the periodic deletion
+//
+//	@receiver r
+//	@param ctx
+//	@return error
+func (r *retentionJob) shutdown(ctx context.Context) error {
+	close(r.stopCh)
+	r.wg.Wait()
+	return nil
+}
+
+// prepare sets the TTL of the tables and adds the partitions of the time tables
+// holding the rows written until the next run, in the configured schema and the routed
+// destinations
+//
+//	@receiver r
+//	@param ctx
+//	@param now
+//	@return error
+func (r *retentionJob) prepare(ctx context.Context, now time.Time) error {
+	writers, err := r.writer.retainedWriters(ctx)
+	return multierr.Append(err, r.prepareWriters(ctx, now, writers))
+}
+
+// prepareWriters sets the TTL of the tables and adds the partitions of the time tables
+// of the writers
+//
+//	@receiver r
+//	@param ctx
+//	@param now
+//	@param writers
+//	@return error
+func (r *retentionJob) prepareWriters(ctx context.Context, now time.Time, writers []*KiWriter) error {
+	var errs error
+	for name, table := range retentionTables {
+		if table.signal != r.signal {
+			continue
+		}
+		retention := r.cfg.retention(name, table.signal)
+		if retention <= 0 {
+			continue
+		}
+		for _, writer := range writers {
+			if r.cfg.SetTTL {
+				errs = multierr.Append(errs, r.setTTL(ctx, writer, table, retention))
+			}
+			if r.cfg.partitionColumn(table.timeTable) != "" {
+				errs = multierr.Append(errs, r.addPartitions(ctx, writer, table, now))
+			}
+		}
+	}
+	return errs
+}
+
+// apply deletes the rows of the signal that expired at now, child rows first, in the
+// configured schema and the routed destinations. The expired partitions of a time
+// table are dropped before its remaining expired rows are deleted.
+//
+//	@receiver r
+//	@param ctx
+//	@param now
+//	@return error
+func (r *retentionJob) apply(ctx context.Context, now time.Time) error {
+	writers, errs := r.writer.retainedWriters(ctx)
+	errs = multierr.Append(errs, r.prepareWriters(ctx, now, writers))
+	for name, table := range retentionTables {
+		if table.signal != r.signal {
+			continue
+		}
+		retention := r.cfg.retention(name, table.signal)
+		if retention <= 0 {
+			continue
+		}
+		cutoff := now.Add(-retention)
+		for _, writer := range writers {
+			statements := table.deleteStatements(writer.qualifiedTableName, cutoff)
+			errs = multierr.Append(errs, r.execute(ctx, writer, statements[:len(statements)-1]))
+			if r.cfg.partitionColumn(table.timeTable) != "" {
+				errs = multierr.Append(errs, r.dropPartitions(ctx, writer, table, cutoff))
+			}
+			errs = multierr.Append(errs, r.execute(ctx, writer, statements[len(statements)-1:]))
+		}
+		r.logger.Debug("Deleted expired rows", zap.String("Table", table.timeTable), zap.Duration("Retention", retention))
+	}
+	return errs
+}
+
+// execute runs the statements of a writer, all of them even when one fails
+//
+//	@receiver r
+//	@param ctx
+//	@param writer
+//	@param statements
+//	@return error
+func (r *retentionJob) execute(ctx context.Context, writer *KiWriter, statements []string) error {
+	var errs error
+	for _, statement := range statements {
+		if _, err := writer.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", statement, err))
+		}
+	}
+	return errs
+}
+
+// setTTL sets the TTL of the time table, its child and its metric tables to the
+// retention rounded up to whole minutes, once per table
+//
+//	@receiver r
+//	@param ctx
+//	@param writer
+//	@param table
+//	@param retention
+//	@return error
+func (r *retentionJob) setTTL(ctx context.Context, writer *KiWriter, table retentionTable, retention time.Duration) error {
+	minutes := int64((retention + time.Minute - 1) / time.Minute)
+	tables := []string{table.timeTable}
+	for _, column := range append(append([]retentionColumn(nil), table.children...), table.roots...) {
+		tables = append(tables, column.table)
+	}
+
+	var statements []string
+	for _, tableName := range tables {
+		finalTable := writer.finalTableName(tableName)
+		if r.ttls[finalTable] {
+			continue
+		}
+		r.ttls[finalTable] = true
+		statements = append(statements, fmt.Sprintf(SetTableTTL, writer.qualifiedTableName(tableName), minutes))
+	}
+	return r.execute(ctx, writer, statements)
+}
+
+// partitionWidth - the width of the partitions of a time table in the time unit of its time column
+//
+//	@receiver r
+//	@param table
+//	@return int64
+func (r *retentionJob) partitionWidth(table retentionTable) int64 {
+	return int64(r.cfg.PartitionInterval / table.timeUnit)
+}
+
+// addPartitions adds the partitions of the current and the next interval to a time
+// table, once per partition
+//
+//	@receiver r
+//	@param ctx
+//	@param writer
+//	@param table
+//	@param now
+//	@return error
+func (r *retentionJob) addPartitions(ctx context.Context, writer *KiWriter, table retentionTable, now time.Time) error {
+	finalTable := writer.finalTableName(table.timeTable)
+	partitions, ok := r.partitions[finalTable]
+	if !ok {
+		partitions = make(map[int64]bool)
+		r.partitions[finalTable] = partitions
+	}
+
+	var errs error
+	width := r.partitionWidth(table)
+	current := now.UnixNano() / int64(table.timeUnit) / width * width
+	for _, start := range []int64{current, current + width} {
+		if _, ok := partitions[start]; ok {
+			continue
+		}
+		statement := fmt.Sprintf(AddTimePartition, writer.qualifiedTableName(table.timeTable), partitionName(start), start, start+width)
+		_, err := writer.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", statement, err))
+		}
+		partitions[start] = true
+	}
+	return errs
+}
+
+// dropPartitions drops the partitions of a time table added by the job whose rows
+// are all older than cutoff, together with their rows. A partition is dropped once,
+// the rows of a partition that could not be dropped are deleted by the DELETE statement
+// of the time table.
+//
+//	@receiver r
+//	@param ctx
+//	@param writer
+//	@param table
+//	@param cutoff
+//	@return error
+func (r *retentionJob) dropPartitions(ctx context.Context, writer *KiWriter, table retentionTable, cutoff time.Time) error {
+	partitions := r.partitions[writer.finalTableName(table.timeTable)]
+	cutoffValue := cutoff.UnixNano() / int64(table.timeUnit)
+	width := r.partitionWidth(table)
+
+	starts := make([]int64, 0, len(partitions))
+	for start := range partitions {
+		if start+width <= cutoffValue {
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	var errs error
+	for _, start := range starts {
+		delete(partitions, start)
+		statement := fmt.Sprintf(DeleteTimePartition, writer.qualifiedTableName(table.timeTable), partitionName(start))
+		if _, err := writer.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", statement, err))
+		}
+	}
+	return errs
+}
+
+// partitionName - the name of the partition of a time table starting at start
+//
+//	@param start
+//	@return string
+func partitionName(start int64) string {
+	return fmt.Sprintf("p%d", start)
+}
+
+// deleteStatements - the statements deleting the rows older than cutoff in the order
+// they have to run: child rows, the metric rows of expiring datapoints only, then the
+// expiring rows
+//
+//	@receiver t
+//	@param qualifiedTableName
+//	@param cutoff
+//	@return []string
+func (t retentionTable) deleteStatements(qualifiedTableName func(string) string, cutoff time.Time) []string {
+	cutoffValue := cutoff.UnixNano() / int64(t.timeUnit)
+	timeTable := qualifiedTableName(t.timeTable)
+
+	statements := make([]string, 0, len(t.children)+len(t.roots)+1)
+	for _, child := range t.children {
+		statements = append(statements, fmt.Sprintf(DeleteExpiredChildRows, qualifiedTableName(child.table), child.column,
+			t.idColumn, timeTable, t.timeColumn, cutoffValue))
+	}
+	for _, root := range t.roots {
+		statements = append(statements, fmt.Sprintf(DeleteExpiredParentRows, qualifiedTableName(root.table), root.column,
+			root.column, timeTable, t.timeColumn, cutoffValue, root.column, root.column, timeTable, t.timeColumn, cutoffValue))
+	}
+	statements = append(statements, fmt.Sprintf(DeleteExpiredRows, timeTable, t.timeColumn, cutoffValue))
+	return statements
+}
+
+// retention - the retention of a table, its override when set and otherwise the
+// retention of its signal
+//
+//	@receiver rc
+//	@param table
+//	@param signal
+//	@return time.Duration
+func (rc *RetentionConfig) retention(table string, signal string) time.Duration {
+	if retention, ok := rc.Tables[table]; ok {
+		return retention
+	}
+	switch signal {
+	case SignalLogs:
+		return rc.Logs
+	case SignalTraces:
+		return rc.Traces
+	case SignalMetrics:
+		return rc.Metrics
+	}
+	return 0
+}
+
+// partitionColumn - the column a table created by create_tables is range partitioned
+// on, the time column of the log, trace_span and metric datapoint tables when
+// retention is enabled with a partition interval, otherwise none
+//
+//	@receiver rc
+//	@param tableName
+//	@return string
+func (rc *RetentionConfig) partitionColumn(tableName string) string {
+	if !rc.Enabled || rc.PartitionInterval <= 0 {
+		return ""
+	}
+	for _, table := range retentionTables {
+		if table.timeTable == tableName {
+			return table.timeColumn
+		}
+	}
+	return ""
+}
+
+// enableRetention - creates the retention job of a signal when retention is configured
+//
+//	@receiver kiwriter
+//	@param signal
+func (kiwriter *KiWriter) enableRetention(signal string) {
+	if !kiwriter.cfg.Retention.Enabled {
+		return
+	}
+	kiwriter.retention = newRetentionJob(kiwriter.cfg.Retention, signal, kiwriter, kiwriter.logger)
+}
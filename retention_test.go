@@ -0,0 +1,162 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+func TestRetentionDeletesExpiredSpans(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newTestConfig(server)
+	exporter, err := NewFactory().CreateTracesExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	now := time.Now()
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("service.name", "checkout")
+	spans := resourceSpans.ScopeSpans().AppendEmpty().Spans()
+	appendTestSpan(spans, 1, "expired")
+	appendTestSpan(spans, 2, "live")
+	spans.At(1).SetStartTimestamp(pcommon.NewTimestampFromTime(now.Add(-time.Minute)))
+	if err := exporter.ConsumeTraces(context.Background(), traces); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Retention.Traces = time.Hour
+	job := newRetentionJob(cfg.Retention, SignalTraces, NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil), zap.NewNop())
+	if err := job.apply(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := server.Rows("otel." + TraceSpanTable)
+	if len(rows) != 1 || rows[0]["name"] != "live" {
+		t.Fatalf("%s has rows %v, want the live span", TraceSpanTable, rows)
+	}
+	for _, table := range []string{TraceSpanAttributeTable, TraceResourceAttributeTable, TraceScopeAttributeTable, TraceEventAttributeTable, TraceLinkAttributeTable} {
+		if got := len(server.Rows("otel." + table)); got != 1 {
+			t.Errorf("%s has %d rows, want the one of the live span", table, got)
+		}
+		assertReferences(t, server, table, orderedTables[table].rootColumn, TraceSpanTable, "id")
+	}
+}
+
+func TestRetentionKeepsMetricsWithLiveDatapoints(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newTestConfig(server)
+	exporter, err := NewFactory().CreateMetricsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	now := time.Now()
+	expired := pcommon.NewTimestampFromTime(now.Add(-2 * time.Hour))
+	live := pcommon.NewTimestampFromTime(now.Add(-time.Minute))
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	resourceMetrics.Resource().Attributes().PutStr("service.name", "checkout")
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+	for name, timestamps := range map[string][]pcommon.Timestamp{"expired": {expired}, "mixed": {expired, live}, "pending": nil} {
+		metric := scopeMetrics.AppendEmpty()
+		metric.SetName(name)
+		datapoints := metric.SetEmptyGauge().DataPoints()
+		for _, timestamp := range timestamps {
+			datapoint := datapoints.AppendEmpty()
+			datapoint.SetTimestamp(timestamp)
+			datapoint.SetDoubleValue(1)
+		}
+	}
+	if err := exporter.ConsumeMetrics(context.Background(), metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Retention.Metrics = time.Hour
+	job := newRetentionJob(cfg.Retention, SignalMetrics, NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil), zap.NewNop())
+	if err := job.apply(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, row := range server.Rows("otel." + GaugeTable) {
+		names = append(names, row["metric_name"].(string))
+	}
+	sort.Strings(names)
+	if want := []string{"mixed", "pending"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("%s has metrics %v, want %v", GaugeTable, names, want)
+	}
+	if got := len(server.Rows("otel." + GaugeDatapointTable)); got != 1 {
+		t.Errorf("%s has %d rows, want the live datapoint", GaugeDatapointTable, got)
+	}
+	for _, table := range []string{GaugeResourceAttributeTable, GaugeScopeAttributeTable} {
+		assertReferences(t, server, table, "gauge_id", GaugeTable, "gauge_id")
+	}
+}
+
+func TestRetentionDropsExpiredPartitions(t *testing.T) {
+	server := kineticatest.NewServer(t)
+	cfg := newTestConfig(server)
+	cfg.CreateTables = true
+	cfg.Retention.Enabled = true
+	cfg.Retention.Logs = 48 * time.Hour
+	cfg.Retention.SetTTL = true
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	expired := time.Unix(1700000000, 0)
+	job := newRetentionJob(cfg.Retention, SignalLogs, NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil), zap.NewNop())
+	if err := job.prepare(context.Background(), expired); err != nil {
+		t.Fatal(err)
+	}
+	logs := testLogs("expired", "live")
+	logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(1).SetTimestamp(pcommon.NewTimestampFromTime(expired.Add(96 * time.Hour)))
+	if err := exporter.ConsumeLogs(context.Background(), logs); err != nil {
+		t.Fatal(err)
+	}
+	if err := job.apply(context.Background(), expired.Add(96*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := server.Rows("otel." + LogTable)
+	if len(rows) != 1 || rows[0]["severity_text"] != "live" {
+		t.Fatalf("%s has rows %v, want the live log", LogTable, rows)
+	}
+	start := expired.Unix() / 86400 * 86400
+	statements := make(map[string]bool)
+	for _, statement := range server.Statements() {
+		statements[statement] = true
+	}
+	for _, want := range []string{
+		fmt.Sprintf(`ALTER TABLE "otel"."log" ADD PARTITION "p%d" MIN(%d) MAX(%d)`, start, start, start+86400),
+		fmt.Sprintf(`ALTER TABLE "otel"."log" DELETE PARTITION "p%d"`, start),
+		fmt.Sprintf(`ALTER TABLE "otel"."log" DELETE PARTITION "p%d"`, start+86400),
+		`ALTER TABLE "otel"."log" SET TTL 2880`,
+		`ALTER TABLE "otel"."log_attribute" SET TTL 2880`,
+	} {
+		if !statements[want] {
+			t.Errorf("%s was not executed", want)
+		}
+	}
+	if want := createTableSQL(qualifiedName("otel", LogTable), LogTable, "time_unix_nano"); !statements[want] ||
+		!strings.HasSuffix(want, "PARTITION BY RANGE (\"time_unix_nano\")") {
+		t.Errorf("%s was not created partitioned by its time column", LogTable)
+	}
+}
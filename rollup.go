@@ -0,0 +1,490 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SQL statements to manage the rollup tables
+const (
+	CreateDatapointRollupTable string = `CREATE TABLE IF NOT EXISTS %s
+	(
+		series_id UUID NOT NULL,
+		metric_name VARCHAR(256) NOT NULL,
+		attributes VARCHAR NOT NULL,
+		resource_attributes VARCHAR NOT NULL,
+		bucket_start_unix TIMESTAMP NOT NULL,
+		data_min DOUBLE NOT NULL,
+		data_max DOUBLE NOT NULL,
+		data_sum DOUBLE NOT NULL,
+		"count" BIGINT NOT NULL,
+		data_last DOUBLE NOT NULL,
+		PRIMARY KEY (series_id, bucket_start_unix)
+	)`
+
+	DeleteExpiredDatapointRollups string = `DELETE FROM %s WHERE bucket_start_unix < TIMESTAMPADD(SECOND, -%d, NOW())`
+)
+
+// DatapointRollup - one aggregated bucket of gauge or sum datapoints for a single series,
+// SeriesID identifies the metric name, attributes and resource attributes
+type DatapointRollup struct {
+	SeriesID           string  `avro:"series_id"`
+	MetricName         string  `avro:"metric_name"`
+	Attributes         string  `avro:"attributes"`
+	ResourceAttributes string  `avro:"resource_attributes"`
+	BucketStartUnix    int64   `avro:"bucket_start_unix"`
+	Min                float64 `avro:"data_min"`
+	Max                float64 `avro:"data_max"`
+	Sum                float64 `avro:"data_sum"`
+	Count              int64   `avro:"count"`
+	Last               float64 `avro:"data_last"`
+}
+
+// rollupSeries identifies a series within a datapoint table
+type rollupSeries struct {
+	table              string
+	metricName         string
+	attributes         string
+	resourceAttributes string
+}
+
+// rollupDatapoint - a gauge or sum datapoint of a series, recorded once its metric is written
+type rollupDatapoint struct {
+	series        rollupSeries
+	timeUnixMilli int64
+	value         float64
+}
+
+// rollupKey - a bucket of a series, written by the writer of the destination of the series
+type rollupKey struct {
+	writer      *KiWriter
+	series      rollupSeries
+	resolution  time.Duration
+	bucketStart int64
+}
+
+// rollupSeriesKey - the buckets of a series at a resolution
+type rollupSeriesKey struct {
+	writer     *KiWriter
+	series     rollupSeries
+	resolution time.Duration
+}
+
+// rollupEviction - the start of the latest bucket of a series removed after its flush
+// and when it was removed
+type rollupEviction struct {
+	bucketStart int64
+	evictedAt   int64
+}
+
+// rollupBucket - the aggregate of a bucket, dirty until it is written. times holds the
+// times of the datapoints in the aggregate, a datapoint recorded again when its push is
+// retried is left out.
+type rollupBucket struct {
+	min      float64
+	max      float64
+	sum      float64
+	count    int64
+	last     float64
+	lastTime int64
+	times    map[int64]bool
+	dirty    bool
+}
+
+// rollupFlush - a bucket being written and its aggregate at the time of the flush
+type rollupFlush struct {
+	key    rollupKey
+	bucket rollupBucket
+}
+
+// metricRollup keeps in-memory accumulators per series and rollup level and
+// periodically flushes the closed buckets into the rollup tables. A closed bucket is
+// kept for one more resolution, so datapoints arriving late update its row, after that
+// the datapoints of the bucket are left out of the rollups. The latest evicted bucket is
+// tracked per series, so a series lagging behind the others keeps its rollups, and is
+// forgotten when the series has no bucket evicted for two resolutions.
+type metricRollup struct {
+	cfg    RollupConfig
+	writer *KiWriter
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	buckets map[rollupKey]*rollupBucket
+	evicted map[rollupSeriesKey]rollupEviction
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// rollupTables - the datapoint tables that get rollups
+var rollupTables = []string{GaugeDatapointTable, SumDatapointTable}
+
+// rollupRootTables - the root table of the metrics of a datapoint table getting rollups
+var rollupRootTables = map[string]string{GaugeDatapointTable: GaugeTable, SumDatapointTable: SumTable}
+
+// writtenRollups - the rollup datapoints of the metrics whose rows were written. failed
+// holds per root table the number of rollups converted when a write of its rows last
+// failed, the datapoints before it may be missing from the tables.
+//
+//	@param rollups
+//	@param failed
+//	@return []rollupDatapoint
+func writtenRollups(rollups []rollupDatapoint, failed map[string]int) []rollupDatapoint {
+	if len(failed) == 0 {
+		return rollups
+	}
+	written := make([]rollupDatapoint, 0, len(rollups))
+	for i, datapoint := range rollups {
+		if i >= failed[rollupRootTables[datapoint.series.table]] {
+			written = append(written, datapoint)
+		}
+	}
+	return written
+}
+
+// newMetricRollup
+//
+//	@param cfg
+//	@param writer
+//	@param logger
+//	@return *metricRollup
+func newMetricRollup(cfg RollupConfig, writer *KiWriter, logger *zap.Logger) *metricRollup {
+	return &metricRollup{
+		cfg:     cfg,
+		writer:  writer,
+		logger:  logger,
+		buckets: make(map[rollupKey]*rollupBucket),
+		evicted: make(map[rollupSeriesKey]rollupEviction),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// rollupSuffix - table name suffix for a rollup resolution, e.g. 1m, 5m, 1h
+//
+//	@param resolution
+//	@return string
+func rollupSuffix(resolution time.Duration) string {
+	switch {
+	case resolution%time.Hour == 0:
+		return fmt.Sprintf("%dh", resolution/time.Hour)
+	case resolution%time.Minute == 0:
+		return fmt.Sprintf("%dm", resolution/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", resolution/time.Second)
+	}
+}
+
+// rollupTableName
+//
+//	@param table
+//	@param resolution
+//	@return string
+func rollupTableName(table string, resolution time.Duration) string {
+	return fmt.Sprintf("%s_rollup_%s", table, rollupSuffix(resolution))
+}
+
+// isRollupTable - whether a logical table is the rollup table of a configured level
+//
+//	@receiver cfg
+//	@param tableName
+//	@return bool
+func (cfg *Config) isRollupTable(tableName string) bool {
+	if !cfg.Rollup.Enabled {
+		return false
+	}
+	for _, table := range rollupTables {
+		for _, level := range cfg.Rollup.Levels {
+			if rollupTableName(table, level.Resolution) == tableName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rollupDatapointOf - a datapoint of a series to record once its metric is written
+//
+//	@param table
+//	@param metricName
+//	@param attributes
+//	@param resourceAttributes
+//	@param timeUnixMilli
+//	@param value
+//	@return rollupDatapoint
+func rollupDatapointOf(table string, metricName string, attributes pcommon.Map, resourceAttributes pcommon.Map, timeUnixMilli int64, value float64) rollupDatapoint {
+	return rollupDatapoint{
+		series: rollupSeries{
+			table:              table,
+			metricName:         metricName,
+			attributes:         rollupAttributesKey(attributes),
+			resourceAttributes: rollupAttributesKey(resourceAttributes),
+		},
+		timeUnixMilli: timeUnixMilli,
+		value:         value,
+	}
+}
+
+// seriesKey - the series and resolution of a bucket
+//
+//	@receiver k
+//	@return rollupSeriesKey
+func (k rollupKey) seriesKey() rollupSeriesKey {
+	return rollupSeriesKey{writer: k.writer, series: k.series, resolution: k.resolution}
+}
+
+// record adds the datapoints written by a writer to the accumulators of every rollup level
+//
+//	@receiver r
+//	@param writer
+//	@param datapoints
+func (r *metricRollup) record(writer *KiWriter, datapoints []rollupDatapoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	late := 0
+	for _, datapoint := range datapoints {
+		if math.IsNaN(datapoint.value) {
+			continue
+		}
+		value, timeUnixMilli := datapoint.value, datapoint.timeUnixMilli
+
+		for _, level := range r.cfg.Levels {
+			resolution := level.Resolution.Milliseconds()
+			key := rollupKey{
+				writer:      writer,
+				series:      datapoint.series,
+				resolution:  level.Resolution,
+				bucketStart: timeUnixMilli - timeUnixMilli%resolution,
+			}
+
+			bucket, ok := r.buckets[key]
+			if !ok {
+				if eviction, ok := r.evicted[key.seriesKey()]; ok && key.bucketStart <= eviction.bucketStart {
+					late++
+					continue
+				}
+				r.buckets[key] = &rollupBucket{min: value, max: value, sum: value, count: 1, last: value, lastTime: timeUnixMilli,
+					times: map[int64]bool{timeUnixMilli: true}, dirty: true}
+				continue
+			}
+			if bucket.times[timeUnixMilli] {
+				continue
+			}
+			bucket.times[timeUnixMilli] = true
+
+			bucket.min = math.Min(bucket.min, value)
+			bucket.max = math.Max(bucket.max, value)
+			bucket.sum += value
+			bucket.count++
+			if timeUnixMilli >= bucket.lastTime {
+				bucket.last = value
+				bucket.lastTime = timeUnixMilli
+			}
+			bucket.dirty = true
+		}
+	}
+	if late > 0 {
+		r.logger.Debug("Left late datapoints out of the rollups", zap.Int("Count", late))
+	}
+}
+
+// rollupAttributesKey - attributes as JSON, map keys are sorted so equal sets give equal keys
+//
+//	@param attributes
+//	@return string
+func rollupAttributesKey(attributes pcommon.Map) string {
+	if attributes.Len() == 0 {
+		return "{}"
+	}
+	jsonBytes, err := json.Marshal(attributes.AsRaw())
+	if err != nil {
+		return "{}"
+	}
+	return string(jsonBytes)
+}
+
+// start creates the rollup tables and the background flush loop
+//
+//	@receiver r
+//	@param ctx
+//	@return error
+func (r *metricRollup) start(ctx context.Context) error {
+	var errs []error
+	for _, table := range rollupTables {
+		for _, level := range r.cfg.Levels {
+			statement := fmt.Sprintf(CreateDatapointRollupTable, r.writer.qualifiedTableName(rollupTableName(table, level.Resolution)))
+			if _, err := r.writer.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := multierr.Combine(errs...); err != nil {
+		return err
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case now := <-ticker.C:
+				if err := r.flush(context.Background(), now, false); err != nil {
+					r.logger.Error("Rollup flush failed", zap.Error(err))
+				}
+				if err := r.applyRetention(context.Background()); err != nil {
+					r.logger.Error("Rollup retention failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// shutdown stops the flush loop and flushes every open bucket
+//
+//	@receiver r
+//	@param ctx
+//	@return error
+func (r *metricRollup) shutdown(ctx context.Context) error {
+	close(r.stopCh)
+	r.wg.Wait()
+	return r.flush(ctx, time.Now(), true)
+}
+
+// flush writes the changed buckets that closed before now, or all changed buckets when
+// force is set. The buckets that could not be written are written again by the next flush.
+//
+//	@receiver r
+//	@param ctx
+//	@param now
+//	@param force
+//	@return error
+func (r *metricRollup) flush(ctx context.Context, now time.Time, force bool) error {
+	type rollupTarget struct {
+		writer *KiWriter
+		table  string
+	}
+	nowMilli := now.UnixMilli()
+	flushes := make(map[rollupTarget][]rollupFlush)
+
+	r.mu.Lock()
+	for key, bucket := range r.buckets {
+		resolution := key.resolution.Milliseconds()
+		if !force && key.bucketStart+resolution > nowMilli {
+			continue
+		}
+		if bucket.dirty {
+			target := rollupTarget{key.writer, rollupTableName(key.series.table, key.resolution)}
+			flushes[target] = append(flushes[target], rollupFlush{key, *bucket})
+			bucket.dirty = false
+		}
+		if force || key.bucketStart+2*resolution <= nowMilli {
+			delete(r.buckets, key)
+			eviction := r.evicted[key.seriesKey()]
+			if key.bucketStart > eviction.bucketStart {
+				eviction.bucketStart = key.bucketStart
+			}
+			eviction.evictedAt = nowMilli
+			r.evicted[key.seriesKey()] = eviction
+		}
+	}
+	for seriesKey, eviction := range r.evicted {
+		if eviction.evictedAt+2*seriesKey.resolution.Milliseconds() <= nowMilli {
+			delete(r.evicted, seriesKey)
+		}
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for target, flushed := range flushes {
+		data := make([]any, 0, len(flushed))
+		for _, f := range flushed {
+			data = append(data, f.row())
+		}
+		r.logger.Debug("Flushing rollups", zap.String("Table", target.table), zap.Int("Record count", len(data)))
+		if err := target.writer.doChunkedInsert(ctx, target.table, data); err != nil {
+			errs = append(errs, err)
+			r.requeue(flushed)
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+// requeue marks the buckets that could not be written as changed, putting back the ones
+// removed by the flush
+//
+//	@receiver r
+//	@param flushed
+func (r *metricRollup) requeue(flushed []rollupFlush) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range flushed {
+		if bucket, ok := r.buckets[f.key]; ok {
+			bucket.dirty = true
+			continue
+		}
+		bucket := f.bucket
+		bucket.dirty = true
+		r.buckets[f.key] = &bucket
+	}
+}
+
+// row - the rollup row of a flushed bucket
+//
+//	@receiver f
+//	@return DatapointRollup
+func (f rollupFlush) row() DatapointRollup {
+	series := f.key.series
+	return DatapointRollup{
+		SeriesID:           newRecordIDBuilder("rollup").str(series.table).str(series.metricName).str(series.attributes).str(series.resourceAttributes).id(),
+		MetricName:         series.metricName,
+		Attributes:         series.attributes,
+		ResourceAttributes: series.resourceAttributes,
+		BucketStartUnix:    f.key.bucketStart,
+		Min:                f.bucket.min,
+		Max:                f.bucket.max,
+		Sum:                f.bucket.sum,
+		Count:              f.bucket.count,
+		Last:               f.bucket.last,
+	}
+}
+
+// applyRetention deletes the rollup rows older than the retention of their level, in the
+// configured schema and the routed destinations
+//
+//	@receiver r
+//	@param ctx
+//	@return error
+func (r *metricRollup) applyRetention(ctx context.Context) error {
+	writers, err := r.writer.retainedWriters(ctx)
+	errs := []error{err}
+	for _, level := range r.cfg.Levels {
+		if level.Retention <= 0 {
+			continue
+		}
+		for _, writer := range writers {
+			for _, table := range rollupTables {
+				statement := fmt.Sprintf(DeleteExpiredDatapointRollups, writer.qualifiedTableName(rollupTableName(table, level.Resolution)), int64(level.Retention/time.Second))
+				if _, err := writer.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return multierr.Combine(errs...)
+}
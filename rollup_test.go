@@ -0,0 +1,241 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// rollupBucketStart - the start of a one minute bucket
+var rollupBucketStart = time.Unix(1700000040, 0)
+
+// newRollupConfig - a config writing one minute rollups to a fake Kinetica
+//
+//	@param server
+//	@return *Config
+func newRollupConfig(server *kineticatest.Server) *Config {
+	cfg := newTestConfig(server)
+	cfg.Rollup.Enabled = true
+	cfg.Rollup.Levels = []RollupLevel{{Resolution: time.Minute}}
+	return cfg
+}
+
+// createRollupTables creates the one minute rollup tables in a schema
+//
+//	@param t
+//	@param server
+//	@param schema
+func createRollupTables(t *testing.T, server *kineticatest.Server, schema string) {
+	t.Helper()
+	properties := map[string][]string{"series_id": {primaryKeyProperty}, "bucket_start_unix": {primaryKeyProperty}}
+	for _, table := range rollupTables {
+		name := schema + "." + rollupTableName(table, time.Minute)
+		if err := server.CreateTable(name, kineticatest.TypeSchemaOf(DatapointRollup{}), properties); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// rollupDatapoints - a datapoint of a gauge series at an offset into the bucket
+//
+//	@param offset
+//	@param value
+//	@return []rollupDatapoint
+func rollupDatapoints(offset time.Duration, value float64) []rollupDatapoint {
+	return []rollupDatapoint{rollupDatapointOf(GaugeDatapointTable, "queue.depth", pcommon.NewMap(), pcommon.NewMap(),
+		rollupBucketStart.Add(offset).UnixMilli(), value)}
+}
+
+// assertRollup checks the single rollup row of a table
+//
+//	@param t
+//	@param server
+//	@param table
+//	@param count
+//	@param sum
+func assertRollup(t *testing.T, server *kineticatest.Server, table string, count int64, sum float64) {
+	t.Helper()
+	rows := server.Rows(table)
+	if len(rows) != 1 {
+		t.Fatalf("%s has %d rows, want the row of the bucket", table, len(rows))
+	}
+	if rows[0]["count"] != count || rows[0]["data_sum"] != sum {
+		t.Errorf("%s row = %v, want count %d and sum %v", table, rows[0], count, sum)
+	}
+}
+
+func TestRollupReplacesTheRowOfABucket(t *testing.T) {
+	server := newTestServer(t)
+	createRollupTables(t, server, "otel")
+	cfg := newRollupConfig(server)
+	writer := NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil)
+	rollup := newMetricRollup(cfg.Rollup, writer, zap.NewNop())
+	table := "otel." + rollupTableName(GaugeDatapointTable, time.Minute)
+
+	rollup.record(writer, rollupDatapoints(time.Second, 1))
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(61*time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	assertRollup(t, server, table, 1, 1)
+
+	// a datapoint arriving within one resolution after the bucket closed updates its row
+	rollup.record(writer, rollupDatapoints(2*time.Second, 3))
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(62*time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	assertRollup(t, server, table, 2, 4)
+
+	// later datapoints are left out once the bucket is evicted
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(121*time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	rollup.record(writer, rollupDatapoints(3*time.Second, 5))
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(122*time.Second), true); err != nil {
+		t.Fatal(err)
+	}
+	assertRollup(t, server, table, 2, 4)
+}
+
+func TestRollupWritesFailedBucketsAgain(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newRollupConfig(server)
+	writer := NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil)
+	rollup := newMetricRollup(cfg.Rollup, writer, zap.NewNop())
+
+	rollup.record(writer, rollupDatapoints(time.Second, 1))
+	// the bucket is evicted by the failed flush and put back
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(3*time.Minute), false); err == nil {
+		t.Fatal("flush() to a missing rollup table succeeded")
+	}
+
+	createRollupTables(t, server, "otel")
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(3*time.Minute), false); err != nil {
+		t.Fatal(err)
+	}
+	assertRollup(t, server, "otel."+rollupTableName(GaugeDatapointTable, time.Minute), 1, 1)
+}
+
+func TestRollupRecordsWrittenMetricsOnly(t *testing.T) {
+	server := newTestServer(t)
+	server.RejectRows(func(table string, row map[string]any) bool {
+		return table == "otel."+GaugeTable && row["metric_name"] == "rejected"
+	})
+	exporter, err := newMetricsExporter(zap.NewNop(), nil, newRollupConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, names := range [][]string{{"written"}, {"rejected"}, {"converted", "unsupported"}} {
+		metrics := pmetric.NewMetrics()
+		scopeMetrics := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+		for _, name := range names {
+			metric := scopeMetrics.AppendEmpty()
+			metric.SetName(name)
+			if name == "unsupported" {
+				continue
+			}
+			datapoint := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+			datapoint.SetTimestamp(pcommon.NewTimestampFromTime(rollupBucketStart))
+			datapoint.SetDoubleValue(1)
+		}
+		err := exporter.pushMetricsData(context.Background(), metrics)
+		if (err == nil) != (names[0] == "written") {
+			t.Errorf("push of %v = %v", names, err)
+		}
+	}
+
+	// the metric written with a metric that cannot be converted is recorded
+	var recorded []string
+	for key := range exporter.rollup.buckets {
+		recorded = append(recorded, key.series.metricName)
+	}
+	sort.Strings(recorded)
+	if !reflect.DeepEqual(recorded, []string{"converted", "written"}) {
+		t.Errorf("rollups of %v recorded, want the written metrics only", recorded)
+	}
+}
+
+func TestRollupLeavesOutTheDatapointsOfARetriedPush(t *testing.T) {
+	server := newTestServer(t)
+	createRollupTables(t, server, "otel")
+	cfg := newRollupConfig(server)
+	writer := NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil)
+	rollup := newMetricRollup(cfg.Rollup, writer, zap.NewNop())
+
+	rollup.record(writer, rollupDatapoints(time.Second, 1))
+	rollup.record(writer, append(rollupDatapoints(time.Second, 1), rollupDatapoints(2*time.Second, 3)...))
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(61*time.Second), false); err != nil {
+		t.Fatal(err)
+	}
+	assertRollup(t, server, "otel."+rollupTableName(GaugeDatapointTable, time.Minute), 2, 4)
+}
+
+func TestRollupEvictsTheBucketsOfEverySeries(t *testing.T) {
+	server := newTestServer(t)
+	createRollupTables(t, server, "otel")
+	cfg := newRollupConfig(server)
+	writer := NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil)
+	rollup := newMetricRollup(cfg.Rollup, writer, zap.NewNop())
+	lagging := func(offset time.Duration, value float64) []rollupDatapoint {
+		return []rollupDatapoint{rollupDatapointOf(GaugeDatapointTable, "lagging", pcommon.NewMap(), pcommon.NewMap(),
+			rollupBucketStart.Add(offset).UnixMilli(), value)}
+	}
+
+	// the bucket of the other series is evicted while the lagging series writes the bucket before
+	rollup.record(writer, rollupDatapoints(2*time.Minute, 1))
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(5*time.Minute), false); err != nil {
+		t.Fatal(err)
+	}
+	rollup.record(writer, lagging(time.Minute, 1))
+	rollup.record(writer, rollupDatapoints(2*time.Minute, 1))
+	if len(rollup.buckets) != 1 {
+		t.Errorf("%d buckets, want the bucket of the lagging series only", len(rollup.buckets))
+	}
+
+	// the evicted buckets are forgotten two resolutions after the series was last evicted
+	if err := rollup.flush(context.Background(), rollupBucketStart.Add(7*time.Minute), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(rollup.evicted) != 1 {
+		t.Errorf("%d evicted series, want the lagging series only", len(rollup.evicted))
+	}
+}
+
+func TestRollupWritesToTheRoutedDestination(t *testing.T) {
+	server := newTestServer(t)
+	createRollupTables(t, server, "otel")
+	cfg := newRollupConfig(server)
+	cfg.Routing.Enabled = true
+	cfg.Routing.Attributes = []string{"tenant.id"}
+	exporter, err := newMetricsExporter(zap.NewNop(), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := pmetric.NewMetrics()
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	resourceMetrics.Resource().Attributes().PutStr("tenant.id", "acme")
+	metric := resourceMetrics.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("queue.depth")
+	datapoint := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+	datapoint.SetTimestamp(pcommon.NewTimestampFromTime(rollupBucketStart))
+	datapoint.SetDoubleValue(1)
+	if err := exporter.pushMetricsData(context.Background(), metrics); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.rollup.flush(context.Background(), time.Now(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRollup(t, server, "otel_acme."+rollupTableName(GaugeDatapointTable, time.Minute), 1, 1)
+	if got := len(server.Rows("otel." + rollupTableName(GaugeDatapointTable, time.Minute))); got != 0 {
+		t.Errorf("the configured schema has %d rollup rows of the routed destination", got)
+	}
+}
@@ -0,0 +1,538 @@
+package kineticaotelexporter
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/am-kinetica/gpudb-api-go/gpudb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Routing targets
+const (
+	// RoutingTargetSchema writes the tables of a destination to a schema of its own
+	RoutingTargetSchema = "schema"
+	// RoutingTargetTablePrefix writes the tables of a destination with a prefix to the configured schema
+	RoutingTargetTablePrefix = "table_prefix"
+
+	// SQL statements to bootstrap the tables of a destination
+	CreateRoutedSchema string = `CREATE SCHEMA IF NOT EXISTS "%s"`
+	CreateRoutedTable  string = `CREATE TABLE %s LIKE %s`
+)
+
+// routeEntry - a destination and the writer of its tables
+type routeEntry struct {
+	destination string
+	writer      *KiWriter
+}
+
+// routeBootstrap - the bootstrap of a destination in progress, done is closed when it ends
+type routeBootstrap struct {
+	done   chan struct{}
+	writer *KiWriter
+	err    error
+}
+
+// routeCache - the destinations of one signal whose tables have been bootstrapped,
+// the least recently used destination is forgotten beyond the configured maximum.
+// Retention reads the destinations from the catalog instead.
+type routeCache struct {
+	cfg    RoutingConfig
+	signal string
+	// allowed holds the allowed destination names, any name when empty, reserved the
+	// names a destination must not have, the configured schemas for the schema target
+	allowed  map[string]bool
+	reserved map[string]bool
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	pending map[string]*routeBootstrap
+}
+
+// enableRouting - routes the records of a signal by resource attribute when routing is configured
+//
+//	@receiver kiwriter
+//	@param signal
+func (kiwriter *KiWriter) enableRouting(signal string) {
+	if !kiwriter.cfg.Routing.Enabled {
+		return
+	}
+	allowed := make(map[string]bool, len(kiwriter.cfg.Routing.Allowed))
+	for _, name := range kiwriter.cfg.Routing.Allowed {
+		allowed[name] = true
+	}
+	reserved := make(map[string]bool)
+	if kiwriter.cfg.Routing.Target == RoutingTargetSchema {
+		for _, schema := range []string{kiwriter.cfg.Schema, kiwriter.cfg.Schemas.Logs, kiwriter.cfg.Schemas.Traces, kiwriter.cfg.Schemas.Metrics} {
+			if schema != "" {
+				reserved[schema] = true
+			}
+		}
+	}
+	kiwriter.routes = &routeCache{
+		cfg:      kiwriter.cfg.Routing,
+		signal:   signal,
+		allowed:  allowed,
+		reserved: reserved,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		pending:  make(map[string]*routeBootstrap),
+	}
+}
+
+// destinationName - the destination of a resource, of the value of the first routing
+// attribute it has, otherwise of the configured default. An empty name is the configured schema.
+//
+//	@receiver cache
+//	@param resource
+//	@return string
+func (cache *routeCache) destinationName(resource pcommon.Resource) string {
+	for _, attribute := range cache.cfg.Attributes {
+		if value, ok := resource.Attributes().Get(attribute); ok {
+			if name := cache.routedName(value.AsString()); name != "" {
+				return name
+			}
+		}
+	}
+	return cache.routedName(cache.cfg.Default)
+}
+
+// routedName - the destination of a value, the configured prefix followed by the
+// sanitized value. Empty when the sanitized value is empty or not allowed, or when the
+// destination would be one of the configured schemas.
+//
+//	@receiver cache
+//	@param value
+//	@return string
+func (cache *routeCache) routedName(value string) string {
+	name := sanitizeDestination(value)
+	if name == "" || (len(cache.allowed) > 0 && !cache.allowed[name]) {
+		return ""
+	}
+	name = cache.cfg.Prefix + name
+	if cache.reserved[name] {
+		return ""
+	}
+	return name
+}
+
+// sanitizeDestination - lower case letters, digits and underscores only, so the
+// destination is usable as a schema name or table prefix. A value that is changed gets
+// the hash of the value appended, so e.g. team-a, team.a and Team_A do not share the
+// destination team_a.
+//
+//	@param value
+//	@return string
+func sanitizeDestination(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if validDestinationRune(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	if name == "" || name == value {
+		return name
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(value))
+	return fmt.Sprintf("%s_%08x", name, hash.Sum32())
+}
+
+// validDestination - whether a name has lower case letters, digits and underscores only
+//
+//	@param name
+//	@return bool
+func validDestination(name string) bool {
+	for _, r := range name {
+		if !validDestinationRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validDestinationRune
+//
+//	@param r
+//	@return bool
+func validDestinationRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_'
+}
+
+// route - the writer of the destination of a resource, bootstrapping the tables of
+// the destination the first time it is seen. A destination is bootstrapped once at a
+// time without blocking the other destinations, a destination whose bootstrap failed
+// is recorded in failed and not bootstrapped again for the same push.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param resource
+//	@param failed
+//	@return *KiWriter
+//	@return error
+func (kiwriter *KiWriter) route(ctx context.Context, resource pcommon.Resource, failed map[string]error) (*KiWriter, error) {
+	if kiwriter.routes == nil {
+		return kiwriter, nil
+	}
+	destination := kiwriter.routes.destinationName(resource)
+	if destination == "" {
+		return kiwriter, nil
+	}
+	if err, ok := failed[destination]; ok {
+		return nil, err
+	}
+
+	cache := kiwriter.routes
+	cache.mu.Lock()
+	if element, ok := cache.entries[destination]; ok {
+		cache.lru.MoveToFront(element)
+		cache.mu.Unlock()
+		return element.Value.(*routeEntry).writer, nil
+	}
+	if bootstrap, ok := cache.pending[destination]; ok {
+		cache.mu.Unlock()
+		select {
+		case <-bootstrap.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if bootstrap.err != nil {
+			failed[destination] = bootstrap.err
+		}
+		return bootstrap.writer, bootstrap.err
+	}
+	bootstrap := &routeBootstrap{done: make(chan struct{})}
+	cache.pending[destination] = bootstrap
+	cache.mu.Unlock()
+
+	writer := kiwriter.destinationWriter(destination)
+	err := writer.bootstrapDestination(ctx, kiwriter, kiwriter.cfg.destinationTables(cache.signal))
+
+	cache.mu.Lock()
+	delete(cache.pending, destination)
+	if err == nil {
+		cache.entries[destination] = cache.lru.PushFront(&routeEntry{destination, writer})
+		for cache.lru.Len() > cache.cfg.MaxDestinations {
+			oldest := cache.lru.Back()
+			cache.lru.Remove(oldest)
+			delete(cache.entries, oldest.Value.(*routeEntry).destination)
+		}
+	}
+	cache.mu.Unlock()
+
+	if err != nil {
+		bootstrap.err = fmt.Errorf("cannot bootstrap destination %s: %w", destination, err)
+		failed[destination] = bootstrap.err
+		close(bootstrap.done)
+		return nil, bootstrap.err
+	}
+	kiwriter.logger.Info("Bootstrapped routing destination", zap.String("Destination", destination))
+	bootstrap.writer = writer
+	close(bootstrap.done)
+	return writer, nil
+}
+
+// routeErrors - the errors of the destinations that could not be bootstrapped
+//
+//	@param failed
+//	@return error
+func routeErrors(failed map[string]error) error {
+	destinations := make([]string, 0, len(failed))
+	for destination := range failed {
+		destinations = append(destinations, destination)
+	}
+	sort.Strings(destinations)
+
+	var errs error
+	for _, destination := range destinations {
+		errs = multierr.Append(errs, failed[destination])
+	}
+	return errs
+}
+
+// retainedWriters - the writer and the writers of the destinations of the signal
+// found in Kinetica, whose rows expire with the retention. The destinations are read
+// from the catalog on every call, so retention covers the destinations bootstrapped
+// before a restart or forgotten by the routing cache. The writer alone is returned
+// when they cannot be read.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@return []*KiWriter
+//	@return error
+func (kiwriter *KiWriter) retainedWriters(ctx context.Context) ([]*KiWriter, error) {
+	writers := []*KiWriter{kiwriter}
+	if kiwriter.routes == nil {
+		return writers, nil
+	}
+
+	destinations, err := kiwriter.catalogDestinations(ctx)
+	if err != nil {
+		return writers, fmt.Errorf("cannot read the routing destinations: %w", err)
+	}
+	for _, destination := range destinations {
+		writers = append(writers, kiwriter.destinationWriter(destination))
+	}
+	return writers, nil
+}
+
+// catalogDestinations - the destinations having the first table of the signal, the
+// schemas named like a destination for the schema target and the table prefixes of
+// the tables in the configured schema for the table_prefix target, sorted
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@return []string
+//	@return error
+func (kiwriter *KiWriter) catalogDestinations(ctx context.Context) ([]string, error) {
+	cache := kiwriter.routes
+	table := kiwriter.tableName(signalTables[cache.signal][0])
+
+	var destinations []string
+	if cache.cfg.Target == RoutingTargetTablePrefix {
+		showTableResult, err := kiwriter.Db.ShowTableRawWithOpts(ctx, kiwriter.cfg.Schema, &gpudb.ShowTableOptions{ForceSynchronous: true, ShowChildren: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range showTableResult.TableNames {
+			name = strings.TrimPrefix(name, kiwriter.cfg.Schema+".")
+			if destination := strings.TrimSuffix(name, "_"+table); destination != name && cache.isDestination(destination) {
+				destinations = append(destinations, destination)
+			}
+		}
+	} else {
+		schemas, err := kiwriter.Db.ShowTableSchemas(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, schema := range *schemas {
+			if !cache.isDestination(schema) {
+				continue
+			}
+			if _, err := kiwriter.Db.ShowTableRaw(ctx, schema+"."+table); err == nil {
+				destinations = append(destinations, schema)
+			}
+		}
+	}
+	sort.Strings(destinations)
+	return destinations, nil
+}
+
+// isDestination - whether a name is one routedName returns for some value
+//
+//	@receiver cache
+//	@param name
+//	@return bool
+func (cache *routeCache) isDestination(name string) bool {
+	value := strings.TrimPrefix(name, cache.cfg.Prefix)
+	if (value == name && cache.cfg.Prefix != "") || value == "" || !validDestination(value) || cache.reserved[name] {
+		return false
+	}
+	return len(cache.allowed) == 0 || cache.allowed[value]
+}
+
+// destinationWriter - a copy of the writer writing to the schema or with the table
+// prefix of a destination, sharing the connection, spill and dead letter queue
+//
+//	@receiver kiwriter
+//	@param destination
+//	@return *KiWriter
+func (kiwriter *KiWriter) destinationWriter(destination string) *KiWriter {
+	writer := *kiwriter
+	writer.destination = destination
+	writer.routes = nil
+	writer.retention = nil
+	if kiwriter.cfg.Routing.Target == RoutingTargetTablePrefix {
+		writer.tablePrefix = destination + "_"
+	} else {
+		writer.cfg.Schema = destination
+	}
+	writer.logger = kiwriter.logger.With(zap.String("destination", destination))
+	return &writer
+}
+
+// replayWriter - a copy of the writer writing to a schema with a table prefix, used to
+// replay batches spilled by the writer of a destination
+//
+//	@receiver kiwriter
+//	@param schema
+//	@param tablePrefix
+//	@return *KiWriter
+func (kiwriter *KiWriter) replayWriter(schema string, tablePrefix string) *KiWriter {
+	if (schema == "" || schema == kiwriter.cfg.Schema) && tablePrefix == kiwriter.tablePrefix {
+		return kiwriter
+	}
+	writer := *kiwriter
+	if schema != "" {
+		writer.cfg.Schema = schema
+	}
+	writer.tablePrefix = tablePrefix
+	writer.destination = writer.cfg.Schema + "." + tablePrefix
+	return &writer
+}
+
+// bootstrapDestination - creates the schema and the tables of a destination that do
+// not exist yet, like the tables of the configured schema, and adds the missing columns
+// to the tables that exist
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param source
+//	@param tables
+//	@return error
+func (kiwriter *KiWriter) bootstrapDestination(ctx context.Context, source *KiWriter, tables []string) error {
+	if kiwriter.cfg.Schema != source.cfg.Schema {
+		statement := fmt.Sprintf(CreateRoutedSchema, kiwriter.cfg.Schema)
+		if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+			return err
+		}
+	}
+	var existing []string
+	for _, tableName := range tables {
+		if _, err := kiwriter.Db.ShowTableRaw(ctx, kiwriter.finalTableName(tableName)); err == nil {
+			existing = append(existing, tableName)
+			continue
+		}
+		statement := fmt.Sprintf(CreateRoutedTable, kiwriter.qualifiedTableName(tableName), source.qualifiedTableName(tableName))
+		if _, err := kiwriter.Db.ExecuteSqlRaw(ctx, statement, 0, 0, "", nil); err != nil {
+			return err
+		}
+	}
+	return kiwriter.ensureColumns(ctx, existing)
+}
+
+// destinationTables - the tables of a signal created for a destination, the metric
+// tables include the rollup tables when rollups are enabled
+//
+//	@receiver cfg
+//	@param signal
+//	@return []string
+func (cfg *Config) destinationTables(signal string) []string {
+	if signal != SignalMetrics || !cfg.Rollup.Enabled {
+		return signalTables[signal]
+	}
+	tables := append([]string(nil), signalTables[signal]...)
+	for _, table := range rollupTables {
+		for _, level := range cfg.Rollup.Levels {
+			tables = append(tables, rollupTableName(table, level.Resolution))
+		}
+	}
+	return tables
+}
+
+// routedTableName - the table name as recorded for a destination, qualified with its
+// schema when the writer belongs to a destination
+//
+//	@receiver kiwriter
+//	@param tableName
+//	@return string
+func (kiwriter *KiWriter) routedTableName(tableName string) string {
+	if kiwriter.destination == "" {
+		return tableName
+	}
+	return kiwriter.finalTableName(tableName)
+}
+
+// routeLogs - splits the logs by the writer of their destination, the logs of the
+// destinations that cannot be bootstrapped are left out and fail with the error
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param logData
+//	@return map[*KiWriter]plog.Logs
+//	@return error
+func (kiwriter *KiWriter) routeLogs(ctx context.Context, logData plog.Logs) (map[*KiWriter]plog.Logs, error) {
+	if kiwriter.routes == nil {
+		return map[*KiWriter]plog.Logs{kiwriter: logData}, nil
+	}
+	routed := make(map[*KiWriter]plog.Logs)
+	resourceLogs := logData.ResourceLogs()
+	failed := make(map[string]error)
+	for i := 0; i < resourceLogs.Len(); i++ {
+		writer, err := kiwriter.route(ctx, resourceLogs.At(i).Resource(), failed)
+		if err != nil {
+			continue
+		}
+		logs, ok := routed[writer]
+		if !ok {
+			logs = plog.NewLogs()
+			routed[writer] = logs
+		}
+		resourceLogs.At(i).CopyTo(logs.ResourceLogs().AppendEmpty())
+	}
+	return routed, routeErrors(failed)
+}
+
+// routeTraces - splits the traces by the writer of their destination, the traces of
+// the destinations that cannot be bootstrapped are left out and fail with the error
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param traceData
+//	@return map[*KiWriter]ptrace.Traces
+//	@return error
+func (kiwriter *KiWriter) routeTraces(ctx context.Context, traceData ptrace.Traces) (map[*KiWriter]ptrace.Traces, error) {
+	if kiwriter.routes == nil {
+		return map[*KiWriter]ptrace.Traces{kiwriter: traceData}, nil
+	}
+	routed := make(map[*KiWriter]ptrace.Traces)
+	resourceSpans := traceData.ResourceSpans()
+	failed := make(map[string]error)
+	for i := 0; i < resourceSpans.Len(); i++ {
+		writer, err := kiwriter.route(ctx, resourceSpans.At(i).Resource(), failed)
+		if err != nil {
+			continue
+		}
+		traces, ok := routed[writer]
+		if !ok {
+			traces = ptrace.NewTraces()
+			routed[writer] = traces
+		}
+		resourceSpans.At(i).CopyTo(traces.ResourceSpans().AppendEmpty())
+	}
+	return routed, routeErrors(failed)
+}
+
+// routeMetrics - splits the metrics by the writer of their destination, the metrics
+// of the destinations that cannot be bootstrapped are left out and fail with the error
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param metricData
+//	@return map[*KiWriter]pmetric.Metrics
+//	@return error
+func (kiwriter *KiWriter) routeMetrics(ctx context.Context, metricData pmetric.Metrics) (map[*KiWriter]pmetric.Metrics, error) {
+	if kiwriter.routes == nil {
+		return map[*KiWriter]pmetric.Metrics{kiwriter: metricData}, nil
+	}
+	routed := make(map[*KiWriter]pmetric.Metrics)
+	resourceMetrics := metricData.ResourceMetrics()
+	failed := make(map[string]error)
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		writer, err := kiwriter.route(ctx, resourceMetrics.At(i).Resource(), failed)
+		if err != nil {
+			continue
+		}
+		metrics, ok := routed[writer]
+		if !ok {
+			metrics = pmetric.NewMetrics()
+			routed[writer] = metrics
+		}
+		resourceMetrics.At(i).CopyTo(metrics.ResourceMetrics().AppendEmpty())
+	}
+	return routed, routeErrors(failed)
+}
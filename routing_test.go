@@ -0,0 +1,253 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// newRoutingConfig - a config routing the records of a fake Kinetica by tenant.id
+//
+//	@param server
+//	@return *Config
+func newRoutingConfig(server *kineticatest.Server) *Config {
+	cfg := newTestConfig(server)
+	cfg.Routing.Enabled = true
+	cfg.Routing.Attributes = []string{"tenant.id"}
+	return cfg
+}
+
+// tenantLogs - a log of every tenant
+//
+//	@param tenants
+//	@return plog.Logs
+func tenantLogs(tenants ...string) plog.Logs {
+	logs := plog.NewLogs()
+	for _, tenant := range tenants {
+		resourceLogs := logs.ResourceLogs().AppendEmpty()
+		resourceLogs.Resource().Attributes().PutStr("tenant.id", tenant)
+		logRecord := resourceLogs.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+		logRecord.SetSeverityText(tenant)
+	}
+	return logs
+}
+
+func TestRoutingFailsTheDestinationThatCannotBeBootstrapped(t *testing.T) {
+	server := newTestServer(t)
+	server.RejectStatements(func(statement string) bool {
+		return strings.Contains(statement, `"otel_broken"`)
+	})
+	exporter, err := newLogsExporter(zap.NewNop(), nil, newRoutingConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = exporter.pushLogsData(context.Background(), tenantLogs("acme", "broken", "broken"))
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("pushLogsData() = %v, want the error of the broken destination", err)
+	}
+
+	if got := len(server.Rows("otel_acme." + LogTable)); got != 1 {
+		t.Errorf("otel_acme.%s has %d rows, want the log of acme", LogTable, got)
+	}
+	var bootstraps int
+	for _, statement := range server.Statements() {
+		if strings.HasPrefix(statement, `CREATE SCHEMA IF NOT EXISTS "otel_broken"`) {
+			bootstraps++
+		}
+	}
+	if bootstraps != 1 {
+		t.Errorf("the broken destination was bootstrapped %d times by one push, want once", bootstraps)
+	}
+}
+
+func TestRoutingBootstrapDoesNotBlockOtherDestinations(t *testing.T) {
+	server := newTestServer(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server.RejectStatements(func(statement string) bool {
+		if strings.HasPrefix(statement, `CREATE SCHEMA IF NOT EXISTS "otel_slow"`) {
+			close(started)
+			<-release
+		}
+		return false
+	})
+	exporter, err := newLogsExporter(zap.NewNop(), nil, newRoutingConfig(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slow := make(chan error, 1)
+	go func() {
+		slow <- exporter.pushLogsData(context.Background(), tenantLogs("slow"))
+	}()
+	<-started
+
+	fast := make(chan error, 1)
+	go func() {
+		fast <- exporter.pushLogsData(context.Background(), tenantLogs("fast"))
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the bootstrap of the slow destination blocked the fast destination")
+	}
+	close(release)
+
+	if err := <-slow; err != nil {
+		t.Error(err)
+	}
+	for _, table := range []string{"otel_slow." + LogTable, "otel_fast." + LogTable} {
+		if got := len(server.Rows(table)); got != 1 {
+			t.Errorf("%s has %d rows, want 1", table, got)
+		}
+	}
+}
+
+func TestRoutingRetentionDeletesRoutedRows(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newRoutingConfig(server)
+	exporter, err := newTracesExporter(zap.NewNop(), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("tenant.id", "acme")
+	spans := resourceSpans.ScopeSpans().AppendEmpty().Spans()
+	appendTestSpan(spans, 1, "expired")
+	appendTestSpan(spans, 2, "live")
+	spans.At(1).SetStartTimestamp(pcommon.NewTimestampFromTime(now.Add(-time.Minute)))
+	if err := exporter.pushTraceData(context.Background(), traces); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Retention.Traces = time.Hour
+	if err := newRetentionJob(cfg.Retention, SignalTraces, exporter.writer, zap.NewNop()).apply(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := server.Rows("otel_acme." + TraceSpanTable)
+	if len(rows) != 1 || rows[0]["name"] != "live" {
+		t.Errorf("otel_acme.%s has rows %v, want the live span", TraceSpanTable, rows)
+	}
+}
+
+func TestRoutingDestinationNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		allowed []string
+		values  []string
+		want    []string
+	}{
+		{"prefixed", "otel_", nil, []string{"acme", "team_a", "---"}, []string{"otel_acme", "otel_team_a", ""}},
+		{"changed values hashed", "otel_", nil, []string{"team-a", "team.a", "Team_A"},
+			[]string{"otel_team_a_" + fnvHex("team-a"), "otel_team_a_" + fnvHex("team.a"), "otel_team_a_" + fnvHex("Team_A")}},
+		{"allowed", "", []string{"acme"}, []string{"acme", "other"}, []string{"acme", ""}},
+		{"configured schema", "", []string{"otel", "acme"}, []string{"otel", "acme"}, []string{"", "acme"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(kineticatest.NewServer(t))
+			cfg.Routing.Enabled = true
+			cfg.Routing.Attributes = []string{"tenant.id"}
+			cfg.Routing.Prefix = tt.prefix
+			cfg.Routing.Allowed = tt.allowed
+			if err := cfg.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			writer := NewKiWriter(context.Background(), *cfg, zap.NewNop(), nil)
+			writer.enableRouting(SignalLogs)
+
+			for i, value := range tt.values {
+				resource := pcommon.NewResource()
+				resource.Attributes().PutStr("tenant.id", value)
+				if got := writer.routes.destinationName(resource); got != tt.want[i] {
+					t.Errorf("destinationName(%q) = %q, want %q", value, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRoutingToSchemasRequiresPrefixOrAllowed(t *testing.T) {
+	cfg := newRoutingConfig(kineticatest.NewServer(t))
+	cfg.Routing.Prefix = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() of routing to any schema succeeded")
+	}
+}
+
+// fnvHex - the hash appended to a changed destination value
+//
+//	@param value
+//	@return string
+func fnvHex(value string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(value))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+func TestRoutingRetentionReadsTheDestinationsFromTheCatalog(t *testing.T) {
+	tests := []struct {
+		target  string
+		routed  string
+		foreign string
+	}{
+		{RoutingTargetSchema, "otel_acme." + LogTable, "foreign." + LogTable},
+		{RoutingTargetTablePrefix, "otel.otel_acme_" + LogTable, "otel.foreign_" + LogTable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			server := newTestServer(t)
+			if err := server.CreateTable(tt.foreign, kineticatest.TypeSchemaOf(Log{}), nil); err != nil {
+				t.Fatal(err)
+			}
+			cfg := newRoutingConfig(server)
+			cfg.Routing.Target = tt.target
+			exporter, err := newLogsExporter(zap.NewNop(), nil, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exporter.pushLogsData(context.Background(), tenantLogs("acme")); err != nil {
+				t.Fatal(err)
+			}
+
+			// a restarted exporter has not bootstrapped the destination
+			restarted, err := newLogsExporter(zap.NewNop(), nil, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.Retention.Logs = time.Hour
+			if err := newRetentionJob(cfg.Retention, SignalLogs, restarted.writer, zap.NewNop()).apply(context.Background(), time.Now()); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(server.Rows(tt.routed)); got != 0 {
+				t.Errorf("%s has %d expired rows", tt.routed, got)
+			}
+			foreignTable := strings.ReplaceAll(tt.foreign, ".", `"."`)
+			for _, statement := range server.Statements() {
+				if strings.Contains(statement, foreignTable) {
+					t.Errorf("retention ran %s on a table the exporter does not own", statement)
+				}
+			}
+		})
+	}
+}
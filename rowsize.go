@@ -0,0 +1,488 @@
+package kineticaotelexporter
+
+// encodedSizer - a row computing the approximate size of its Avro binary encoding from
+// its fields, so adding a row to a batch does not walk it by reflection
+type encodedSizer interface {
+	encodedSize() int
+}
+
+// stringSize - the Avro binary size of a string
+//
+//	@param s
+//	@return int
+func stringSize(s string) int {
+	return varintSize(int64(len(s))) + len(s)
+}
+
+// nullableStringSize - the Avro binary size of a nullable string, a union
+//
+//	@param s
+//	@return int
+func nullableStringSize(s *string) int {
+	if s == nil {
+		return 1
+	}
+	return 1 + stringSize(*s)
+}
+
+// encodedSize
+//
+//	@receiver attributevalue
+//	@return int
+func (attributevalue *AttributeValue) encodedSize() int {
+	return varintSize(int64(attributevalue.IntValue)) + stringSize(attributevalue.StringValue) +
+		varintSize(int64(attributevalue.BoolValue)) + 8 + varintSize(int64(len(attributevalue.BytesValue))) +
+		len(attributevalue.BytesValue)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *Log) encodedSize() int {
+	return stringSize(row.LogID) + nullableStringSize(row.TraceID) + nullableStringSize(row.SpanID) +
+		varintSize(row.TimeUnixNano) + varintSize(row.ObservedTimeUnixNano) + varintSize(int64(row.SeverityID)) +
+		stringSize(row.SeverityText) + nullableStringSize(row.SeverityLevel) + stringSize(row.Body) +
+		varintSize(int64(row.Flags)) + varintSize(int64(row.DroppedAttributesCount))
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *LogAttribute) encodedSize() int {
+	return stringSize(row.LogID) + stringSize(row.Key) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ResourceAttribute) encodedSize() int {
+	return stringSize(row.LogID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ScopeAttribute) encodedSize() int {
+	return stringSize(row.LogID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *Span) encodedSize() int {
+	return stringSize(row.ID) + stringSize(row.TraceID) + stringSize(row.SpanID) +
+		nullableStringSize(row.ParentSpanID) + stringSize(row.TraceState) + stringSize(row.Name) +
+		varintSize(int64(row.SpanKind)) + varintSize(row.StartTimeUnixNano) + varintSize(row.EndTimeUnixNano) +
+		varintSize(int64(row.DroppedAttributesCount)) + varintSize(int64(row.DroppedEventsCount)) +
+		varintSize(int64(row.DroppedLinksCount)) + stringSize(row.Message) + varintSize(int64(row.StatusCode))
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SpanAttribute) encodedSize() int {
+	return stringSize(row.SpanID) + stringSize(row.SpanRecordID) + stringSize(row.Key) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *TraceResourceAttribute) encodedSize() int {
+	return stringSize(row.SpanID) + stringSize(row.SpanRecordID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *TraceScopeAttribute) encodedSize() int {
+	return stringSize(row.SpanID) + stringSize(row.SpanRecordID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *EventAttribute) encodedSize() int {
+	return stringSize(row.SpanID) + stringSize(row.SpanRecordID) + varintSize(int64(row.EventIndex)) + stringSize(row.EventName) + stringSize(row.Key) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *LinkAttribute) encodedSize() int {
+	return stringSize(row.LinkSpanID) + stringSize(row.SpanRecordID) + varintSize(int64(row.LinkIndex)) + stringSize(row.TraceID) + stringSize(row.SpanID) + stringSize(row.Key) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *Gauge) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.MetricName) + stringSize(row.Description) +
+		stringSize(row.Unit) + stringSize(row.Job) + stringSize(row.Instance)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *GaugeDatapoint) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.ID) + varintSize(row.StartTimeUnix) +
+		varintSize(row.TimeUnix) + varintSize(int64(row.Flags)) + varintSize(int64(row.DroppedAttributesCount)) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *GaugeDatapointAttribute) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.DatapointID) + stringSize(row.Key) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *GaugeResourceAttribute) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *GaugeScopeAttribute) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *GaugeDatapointExemplar) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		varintSize(row.TimeUnix) + nullableStringSize(row.TraceID) + nullableStringSize(row.SpanID) +
+		varintSize(int64(row.DroppedAttributesCount)) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *GaugeDataPointExemplarAttribute) encodedSize() int {
+	return stringSize(row.GaugeID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		stringSize(row.Key) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *Sum) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.MetricName) + stringSize(row.Description) +
+		stringSize(row.Unit) + varintSize(int64(row.AggregationTemporality)) + varintSize(int64(row.IsMonotonic)) +
+		stringSize(row.Job) + stringSize(row.Instance)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SumDatapoint) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.ID) + varintSize(row.StartTimeUnix) +
+		varintSize(row.TimeUnix) + varintSize(int64(row.Flags)) + varintSize(int64(row.DroppedAttributesCount)) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SumDataPointAttribute) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.DatapointID) + stringSize(row.Key) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SumResourceAttribute) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SumScopeAttribute) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SumDatapointExemplar) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		varintSize(row.TimeUnix) + nullableStringSize(row.TraceID) + nullableStringSize(row.SpanID) +
+		varintSize(int64(row.DroppedAttributesCount)) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SumDataPointExemplarAttribute) encodedSize() int {
+	return stringSize(row.SumID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		stringSize(row.Key) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *Histogram) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.MetricName) + stringSize(row.Description) +
+		stringSize(row.Unit) + varintSize(int64(row.AggregationTemporality)) + stringSize(row.Job) +
+		stringSize(row.Instance)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramDatapoint) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.ID) + varintSize(row.StartTimeUnix) +
+		varintSize(row.TimeUnix) + varintSize(row.Count) + varintSize(int64(row.Flags)) +
+		varintSize(int64(row.DroppedAttributesCount)) + 24
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramDataPointAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.Key) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramDatapointBucketCount) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.CountID) +
+		varintSize(row.Count)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramDatapointExplicitBound) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.BoundID) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramResourceAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramScopeAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramDatapointExemplar) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		varintSize(row.TimeUnix) + nullableStringSize(row.TraceID) + nullableStringSize(row.SpanID) +
+		varintSize(int64(row.DroppedAttributesCount)) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *HistogramDataPointExemplarAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		stringSize(row.Key) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogram) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.MetricName) + stringSize(row.Description) +
+		stringSize(row.Unit) + varintSize(int64(row.AggregationTemporality)) + stringSize(row.Job) +
+		stringSize(row.Instance)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramDatapoint) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.ID) + varintSize(row.StartTimeUnix) +
+		varintSize(row.TimeUnix) + varintSize(row.Count) + varintSize(int64(row.Flags)) +
+		varintSize(int64(row.Scale)) + varintSize(row.ZeroCount) + varintSize(int64(row.BucketsPositiveOffset)) +
+		varintSize(int64(row.BucketsNegativeOffset)) + varintSize(int64(row.DroppedAttributesCount)) + 24
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramDataPointAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.Key) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramBucketPositiveCount) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.CountID) +
+		varintSize(row.Count)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramBucketNegativeCount) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.CountID) +
+		varintSize(row.Count)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramResourceAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramScopeAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramDatapointExemplar) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		varintSize(row.TimeUnix) + nullableStringSize(row.TraceID) + nullableStringSize(row.SpanID) +
+		varintSize(int64(row.DroppedAttributesCount)) + 8
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *ExponentialHistogramDataPointExemplarAttribute) encodedSize() int {
+	return stringSize(row.HistogramID) + stringSize(row.DatapointID) + stringSize(row.ExemplarID) +
+		stringSize(row.Key) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *Summary) encodedSize() int {
+	return stringSize(row.SummaryID) + stringSize(row.MetricName) + stringSize(row.Description) +
+		stringSize(row.Unit) + stringSize(row.Job) + stringSize(row.Instance)
+}
+
+// encodedSize - the size includes the nullable doubles of the quantile columns the
+// datapoint has values for
+//
+//	@receiver row
+//	@return int
+func (row *SummaryDatapoint) encodedSize() int {
+	return stringSize(row.SummaryID) + stringSize(row.ID) + varintSize(row.StartTimeUnix) +
+		varintSize(row.TimeUnix) + varintSize(row.Count) + varintSize(int64(row.Flags)) +
+		varintSize(int64(row.DroppedAttributesCount)) + 8 + 9*len(row.Quantiles)
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SummaryDataPointAttribute) encodedSize() int {
+	return stringSize(row.SummaryID) + stringSize(row.DatapointID) + stringSize(row.Key) +
+		row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SummaryDatapointQuantileValues) encodedSize() int {
+	return stringSize(row.SummaryID) + stringSize(row.DatapointID) + stringSize(row.QuantileID) + 16
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SummaryResourceAttribute) encodedSize() int {
+	return stringSize(row.SummaryID) + stringSize(row.Key) + stringSize(row.SchemaURL) +
+		varintSize(int64(row.DroppedAttributesCount)) + row.AttributeValue.encodedSize()
+}
+
+// encodedSize
+//
+//	@receiver row
+//	@return int
+func (row *SummaryScopeAttribute) encodedSize() int {
+	return stringSize(row.SummaryID) + stringSize(row.ScopeName) + stringSize(row.ScopeVersion) +
+		stringSize(row.Key) + stringSize(row.SchemaURL) + varintSize(int64(row.DroppedAttributesCount)) +
+		row.AttributeValue.encodedSize()
+}
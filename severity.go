@@ -0,0 +1,111 @@
+package kineticaotelexporter
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Normalized severity levels, one per range of four OTLP severity numbers
+const (
+	SeverityLevelTrace = "TRACE"
+	SeverityLevelDebug = "DEBUG"
+	SeverityLevelInfo  = "INFO"
+	SeverityLevelWarn  = "WARN"
+	SeverityLevelError = "ERROR"
+	SeverityLevelFatal = "FATAL"
+)
+
+// severityLevels - the levels in severity number order, level i covers numbers 4i+1 to 4i+4
+var severityLevels = []string{
+	SeverityLevelTrace,
+	SeverityLevelDebug,
+	SeverityLevelInfo,
+	SeverityLevelWarn,
+	SeverityLevelError,
+	SeverityLevelFatal,
+}
+
+// defaultSeverityTextMapping - severity texts commonly sent without a severity number,
+// keys are lower case
+var defaultSeverityTextMapping = map[string]string{
+	"trace":       SeverityLevelTrace,
+	"debug":       SeverityLevelDebug,
+	"info":        SeverityLevelInfo,
+	"information": SeverityLevelInfo,
+	"notice":      SeverityLevelInfo,
+	"warn":        SeverityLevelWarn,
+	"warning":     SeverityLevelWarn,
+	"error":       SeverityLevelError,
+	"err":         SeverityLevelError,
+	"critical":    SeverityLevelFatal,
+	"crit":        SeverityLevelFatal,
+	"alert":       SeverityLevelFatal,
+	"emergency":   SeverityLevelFatal,
+	"fatal":       SeverityLevelFatal,
+	"panic":       SeverityLevelFatal,
+}
+
+// severityMapper - derives the severity number and level of a log record
+type severityMapper struct {
+	textMapping map[string]string
+}
+
+// newSeverityMapper - the default text mapping extended with the configured one
+//
+//	@param textMapping
+//	@return *severityMapper
+//	@return error
+func newSeverityMapper(textMapping map[string]string) (*severityMapper, error) {
+	mapping := make(map[string]string, len(defaultSeverityTextMapping)+len(textMapping))
+	for text, level := range defaultSeverityTextMapping {
+		mapping[text] = level
+	}
+	for text, level := range textMapping {
+		normalized := strings.ToUpper(level)
+		if severityLevelNumber(normalized) == plog.SeverityNumberUnspecified {
+			return nil, fmt.Errorf("invalid severity level %q for severity text %q, must be one of %s", level, text, strings.Join(severityLevels, ", "))
+		}
+		mapping[strings.ToLower(text)] = normalized
+	}
+	return &severityMapper{textMapping: mapping}, nil
+}
+
+// severity - the severity number and normalized level of a log record, the number is
+// derived from the severity text when the record carries none. The level is nil when
+// neither the number nor the text is known.
+//
+//	@receiver m
+//	@param severityNumber
+//	@param severityText
+//	@return plog.SeverityNumber
+//	@return *string
+func (m *severityMapper) severity(severityNumber plog.SeverityNumber, severityText string) (plog.SeverityNumber, *string) {
+	if severityNumber == plog.SeverityNumberUnspecified {
+		level, ok := m.textMapping[strings.ToLower(strings.TrimSpace(severityText))]
+		if !ok {
+			return severityNumber, nil
+		}
+		severityNumber = severityLevelNumber(level)
+	}
+
+	if severityNumber < plog.SeverityNumberTrace || severityNumber > plog.SeverityNumberFatal4 {
+		return severityNumber, nil
+	}
+	level := severityLevels[(severityNumber-plog.SeverityNumberTrace)/4]
+	return severityNumber, &level
+}
+
+// severityLevelNumber - the lowest severity number of a level, e.g. WARN -> 13
+//
+//	@param level
+//	@return plog.SeverityNumber
+func severityLevelNumber(level string) plog.SeverityNumber {
+	for i, l := range severityLevels {
+		if l == level {
+			return plog.SeverityNumber(4*i + 1)
+		}
+	}
+	return plog.SeverityNumberUnspecified
+}
@@ -0,0 +1,52 @@
+package kineticaotelexporter
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestSeverityMapper(t *testing.T) {
+	mapper, err := newSeverityMapper(map[string]string{"Verbose": "debug", "warning": "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		number     plog.SeverityNumber
+		text       string
+		wantNumber plog.SeverityNumber
+		wantLevel  string
+	}{
+		{"number", plog.SeverityNumberWarn2, "", plog.SeverityNumberWarn2, SeverityLevelWarn},
+		{"number wins over text", plog.SeverityNumberError, "info", plog.SeverityNumberError, SeverityLevelError},
+		{"lowest number", plog.SeverityNumberTrace, "", plog.SeverityNumberTrace, SeverityLevelTrace},
+		{"highest number", plog.SeverityNumberFatal4, "", plog.SeverityNumberFatal4, SeverityLevelFatal},
+		{"text only", plog.SeverityNumberUnspecified, "information", plog.SeverityNumberInfo, SeverityLevelInfo},
+		{"text only, case and spaces ignored", plog.SeverityNumberUnspecified, "  CRIT ", plog.SeverityNumberFatal, SeverityLevelFatal},
+		{"custom text", plog.SeverityNumberUnspecified, "verbose", plog.SeverityNumberDebug, SeverityLevelDebug},
+		{"custom mapping overrides the default", plog.SeverityNumberUnspecified, "Warning", plog.SeverityNumberError, SeverityLevelError},
+		{"unknown text", plog.SeverityNumberUnspecified, "chatty", plog.SeverityNumberUnspecified, ""},
+		{"neither number nor text", plog.SeverityNumberUnspecified, "", plog.SeverityNumberUnspecified, ""},
+		{"number above the range", plog.SeverityNumber(25), "error", plog.SeverityNumber(25), ""},
+		{"negative number", plog.SeverityNumber(-1), "", plog.SeverityNumber(-1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, level := mapper.severity(tt.number, tt.text)
+			gotLevel := ""
+			if level != nil {
+				gotLevel = *level
+			}
+			if number != tt.wantNumber || gotLevel != tt.wantLevel {
+				t.Errorf("severity(%d, %q) = %d, %q, want %d, %q", tt.number, tt.text, number, gotLevel, tt.wantNumber, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestNewSeverityMapperRejectsUnknownLevels(t *testing.T) {
+	if _, err := newSeverityMapper(map[string]string{"verbose": "chatty"}); err == nil {
+		t.Error("newSeverityMapper() of an unknown level succeeded")
+	}
+}
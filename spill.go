@@ -0,0 +1,407 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reasons a spilled batch is dropped without being replayed
+const (
+	SpillDropReasonMaxSize        = "max_size"
+	SpillDropReasonMaxAge         = "max_age"
+	SpillDropReasonPermanentError = "permanent_error"
+	SpillDropReasonCorrupt        = "corrupt"
+
+	spillFileExtension = ".spill"
+)
+
+// spillBatch - one chunk of records that could not be inserted, as stored on disk.
+// Records are kept as column name to value maps so they can be decoded without
+// knowing the record type they were created from. Schema and TablePrefix are the
+// ones of the writer that spilled the batch, Table is the logical table name.
+type spillBatch struct {
+	Schema      string
+	TablePrefix string
+	Table       string
+	Created     time.Time
+	Records     []map[string]any
+}
+
+// spillFile - a batch file in the spill directory
+type spillFile struct {
+	name    string
+	size    int64
+	created time.Time
+}
+
+// spillBuffer writes the chunks that failed with a retryable error to a local
+// directory and periodically replays them, oldest first, once Kinetica is reachable.
+type spillBuffer struct {
+	cfg       SpillConfig
+	dir       string
+	writer    *KiWriter
+	logger    *zap.Logger
+	telemetry *exporterTelemetry
+
+	mu    sync.Mutex
+	files []spillFile
+	size  int64
+	seq   uint64
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newSpillBuffer - the spill buffer of one signal, batches already on disk from a
+// previous run are picked up for replay
+//
+//	@param cfg
+//	@param signal
+//	@param writer
+//	@param logger
+//	@param telemetry
+//	@return *spillBuffer
+//	@return error
+func newSpillBuffer(cfg SpillConfig, signal string, writer *KiWriter, logger *zap.Logger, telemetry *exporterTelemetry) (*spillBuffer, error) {
+	dir := filepath.Join(cfg.Directory, signal)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create spill directory %s: %w", dir, err)
+	}
+
+	s := &spillBuffer{
+		cfg:       cfg,
+		dir:       dir,
+		writer:    writer,
+		logger:    logger,
+		telemetry: telemetry,
+		stopCh:    make(chan struct{}),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read spill directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spillFileExtension) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		s.files = append(s.files, spillFile{entry.Name(), info.Size(), info.ModTime()})
+		s.size += info.Size()
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].name < s.files[j].name })
+	s.telemetry.recordSpillBacklog(int64(len(s.files)), s.size)
+
+	if len(s.files) > 0 {
+		logger.Info("Found spilled batches to replay", zap.String("Directory", dir), zap.Int("Batch count", len(s.files)), zap.Int64("Bytes", s.size))
+	}
+	return s, nil
+}
+
+// start the periodic replay of spilled batches
+//
+//	@receiver s
+//	@param ctx
+//	@return error
+func (s *spillBuffer) start(ctx context.Context) error {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.ReplayInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				s.replay(context.Background())
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown stops the replay, batches not yet replayed stay on disk for the next run
+//
+//	@receiver s
+//	@param ctx
+//	@return error
+func (s *spillBuffer) shutdown(ctx context.Context) error {
+	close(s.stopCh)
+	s.wg.Wait()
+	return nil
+}
+
+// write stores a chunk of records of a table as a new batch file, dropping the
+// oldest batches when the directory would grow beyond the configured size
+//
+//	@receiver s
+//	@param schema
+//	@param tablePrefix
+//	@param table
+//	@param records
+//	@return error
+func (s *spillBuffer) write(schema string, tablePrefix string, table string, records []any) error {
+	batch := spillBatch{Schema: schema, TablePrefix: tablePrefix, Table: table, Created: time.Now(), Records: make([]map[string]any, 0, len(records))}
+	for _, record := range records {
+		columns, err := recordColumns(record)
+		if err != nil {
+			return err
+		}
+		batch.Records = append(batch.Records, columns)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d%s", batch.Created.UnixNano(), s.seq%1000000, spillFileExtension)
+	path := filepath.Join(s.dir, name)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(file).Encode(&batch); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	maxSize := s.cfg.MaxSizeMiB << 20
+	if info.Size() > maxSize {
+		os.Remove(path)
+		s.telemetry.recordSpillDropped(SpillDropReasonMaxSize)
+		return fmt.Errorf("spilled batch of %d bytes exceeds the spill max_size_mib", info.Size())
+	}
+	for s.size+info.Size() > maxSize && len(s.files) > 0 {
+		s.logger.Warn("Spill directory full, dropping oldest batch", zap.String("File", s.files[0].name))
+		s.removeLocked(0, SpillDropReasonMaxSize)
+	}
+
+	s.files = append(s.files, spillFile{name, info.Size(), batch.Created})
+	s.size += info.Size()
+	s.telemetry.recordSpillBacklog(1, info.Size())
+	return nil
+}
+
+// replay inserts the spilled batches oldest first and stops at the first batch that
+// fails with a retryable error, batches older than max_age are dropped beforehand
+//
+//	@receiver s
+//	@param ctx
+func (s *spillBuffer) replay(ctx context.Context) {
+	s.dropExpired(time.Now())
+
+	for {
+		s.mu.Lock()
+		if len(s.files) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		file := s.files[0]
+		s.mu.Unlock()
+
+		batch, err := s.read(file.name)
+		if err != nil {
+			s.logger.Error("Cannot read spilled batch, dropping it", zap.String("File", file.name), zap.Error(err))
+			s.remove(file.name, SpillDropReasonCorrupt)
+			continue
+		}
+
+		records := make([]any, len(batch.Records))
+		for i := range batch.Records {
+			records[i] = batch.Records[i]
+		}
+		writer := s.writer.replayWriter(batch.Schema, batch.TablePrefix)
+		if err := writer.insertChunk(ctx, batch.Table, records); err != nil {
+			if isRetryableInsertError(err) {
+				s.logger.Debug("Kinetica still unreachable, postponing replay", zap.Error(err))
+				return
+			}
+			if err := writer.deadLetterChunk(ctx, batch.Table, records, err); err != nil {
+				s.logger.Error("Cannot replay spilled batch, dropping it", zap.String("File", file.name), zap.String("Table", batch.Table), zap.Error(err))
+			}
+			s.remove(file.name, SpillDropReasonPermanentError)
+			continue
+		}
+
+		s.logger.Debug("Replayed spilled batch", zap.String("Table", batch.Table), zap.Int("Record count", len(records)))
+		s.telemetry.recordSpillReplayed(int64(len(records)))
+		s.remove(file.name, "")
+	}
+}
+
+// dropExpired removes the batches older than max_age
+//
+//	@receiver s
+//	@param now
+func (s *spillBuffer) dropExpired(now time.Time) {
+	if s.cfg.MaxAge <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.files) > 0 && now.Sub(s.files[0].created) > s.cfg.MaxAge {
+		s.logger.Warn("Dropping expired spilled batch", zap.String("File", s.files[0].name))
+		s.removeLocked(0, SpillDropReasonMaxAge)
+	}
+}
+
+// read decodes a batch file
+//
+//	@receiver s
+//	@param name
+//	@return *spillBatch
+//	@return error
+func (s *spillBuffer) read(name string) (*spillBatch, error) {
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var batch spillBatch
+	if err := gob.NewDecoder(file).Decode(&batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// remove deletes a batch file, a non empty reason counts it as dropped
+//
+//	@receiver s
+//	@param name
+//	@param reason
+func (s *spillBuffer) remove(name string, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.files {
+		if s.files[i].name == name {
+			s.removeLocked(i, reason)
+			return
+		}
+	}
+}
+
+// removeLocked - remove with the lock held
+//
+//	@receiver s
+//	@param i
+//	@param reason
+func (s *spillBuffer) removeLocked(i int, reason string) {
+	file := s.files[i]
+	if err := os.Remove(filepath.Join(s.dir, file.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Error("Cannot remove spilled batch", zap.String("File", file.name), zap.Error(err))
+	}
+	s.files = append(s.files[:i], s.files[i+1:]...)
+	s.size -= file.size
+	s.telemetry.recordSpillBacklog(-1, -file.size)
+	if reason != "" {
+		s.telemetry.recordSpillDropped(reason)
+	}
+}
+
+// recordColumns - the column name to value map of a record, using the avro tags of
+// struct records. Pointer fields are stored as nil or the value they point to.
+//
+//	@param record
+//	@return map[string]any
+//	@return error
+func recordColumns(record any) (map[string]any, error) {
+	if columns, ok := record.(map[string]any); ok {
+		return columns, nil
+	}
+
+	value := reflect.ValueOf(record)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, errors.New("cannot spill a nil record")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot spill a record of type %T", record)
+	}
+
+	columns := make(map[string]any)
+	addStructColumns(value, columns)
+	return columns, nil
+}
+
+// addStructColumns
+//
+//	@param value
+//	@param columns
+func addStructColumns(value reflect.Value, columns map[string]any) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addStructColumns(value.Field(i), columns)
+			continue
+		}
+
+		name := field.Tag.Get("avro")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				columns[name] = nil
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		columns[name] = fieldValue.Interface()
+	}
+}
+
+// enableSpill - creates the spill buffer of a signal when spilling is configured
+//
+//	@receiver kiwriter
+//	@param signal
+//	@return error
+func (kiwriter *KiWriter) enableSpill(signal string) error {
+	if !kiwriter.cfg.Spill.Enabled {
+		return nil
+	}
+	spill, err := newSpillBuffer(kiwriter.cfg.Spill, signal, kiwriter, kiwriter.logger, kiwriter.telemetry)
+	if err != nil {
+		return err
+	}
+	kiwriter.spill = spill
+	return nil
+}
@@ -0,0 +1,114 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.uber.org/zap"
+)
+
+// newSpillExporter - a started logs exporter spilling the chunks an unavailable fake
+// Kinetica fails into a temporary directory, the spilled batches are replayed by the test
+//
+//	@param t
+//	@param cfg
+//	@return *kineticaLogsExporter
+//	@return string the spill directory of the logs
+func newSpillExporter(t *testing.T, cfg *Config) (*kineticaLogsExporter, string) {
+	t.Helper()
+	cfg.Spill.Enabled = true
+	cfg.Spill.Directory = t.TempDir()
+	cfg.Spill.ReplayInterval = time.Hour
+	exporter, err := newLogsExporter(zap.NewNop(), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.start(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := exporter.shutdown(context.Background()); err != nil {
+			t.Error(err)
+		}
+	})
+	return exporter, filepath.Join(cfg.Spill.Directory, SignalLogs)
+}
+
+func TestSpillReplaysOnceKineticaIsReachable(t *testing.T) {
+	server := newTestServer(t)
+	exporter, dir := newSpillExporter(t, newTestConfig(server))
+
+	logs := testLogs("spilled")
+	logs.ResourceLogs().At(0).Resource().Attributes().PutStr("service.name", "checkout")
+	server.Unavailable(http.StatusServiceUnavailable)
+	if err := exporter.pushLogsData(context.Background(), logs); err != nil {
+		t.Fatalf("pushLogsData() = %v, want the chunks spilled", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("no chunk was spilled")
+	}
+
+	// the batches stay spilled while Kinetica is unavailable
+	exporter.writer.spill.replay(context.Background())
+	if remaining, _ := os.ReadDir(dir); len(remaining) != len(entries) {
+		t.Errorf("%d of %d spilled batches left after a replay to an unavailable Kinetica", len(remaining), len(entries))
+	}
+
+	server.Unavailable(0)
+	for i := 0; i < 2; i++ {
+		exporter.writer.spill.replay(context.Background())
+	}
+
+	rows := server.Rows("otel." + LogTable)
+	if len(rows) != 1 || rows[0]["severity_text"] != "spilled" {
+		t.Errorf("%s has rows %v, want the spilled row once", LogTable, rows)
+	}
+	for _, table := range []string{LogResourceAttributeTable, LogScopeAttributeTable} {
+		if got := len(server.Rows("otel." + table)); got != 1 {
+			t.Errorf("%s has %d rows, want the spilled row once", table, got)
+		}
+	}
+	if remaining, _ := os.ReadDir(dir); len(remaining) != 0 {
+		t.Errorf("%d spilled batches left after the replay", len(remaining))
+	}
+}
+
+func TestSpillDeadLettersRejectedBatches(t *testing.T) {
+	server := newTestServer(t)
+	if err := server.CreateTable("otel."+DeadLetterTable, kineticatest.TypeSchemaOf(DeadLetter{}), nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg := newTestConfig(server)
+	cfg.DeadLetter.Enabled = true
+	exporter, dir := newSpillExporter(t, cfg)
+
+	server.Unavailable(http.StatusServiceUnavailable)
+	if err := exporter.pushLogsData(context.Background(), testLogs("rejected")); err != nil {
+		t.Fatalf("pushLogsData() = %v, want the chunks spilled", err)
+	}
+	server.Unavailable(0)
+	server.RejectRows(func(table string, row map[string]any) bool {
+		return table == "otel."+LogTable
+	})
+	exporter.writer.spill.replay(context.Background())
+
+	if got := len(server.Rows("otel." + LogTable)); got != 0 {
+		t.Errorf("%s has %d rows, want the rejected row left out", LogTable, got)
+	}
+	deadLetters := server.Rows("otel." + DeadLetterTable)
+	if len(deadLetters) != 1 || deadLetters[0]["table_name"] != LogTable {
+		t.Errorf("%s has rows %v, want the rejected row of %s", DeadLetterTable, deadLetters, LogTable)
+	}
+	if remaining, _ := os.ReadDir(dir); len(remaining) != 0 {
+		t.Errorf("%d spilled batches left after the replay", len(remaining))
+	}
+}
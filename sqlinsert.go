@@ -0,0 +1,201 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Insert modes
+const (
+	// InsertModeRecords inserts the rows through the /insert/records endpoint
+	InsertModeRecords = "records"
+	// InsertModeSQL inserts the rows through the /execute/sql endpoint with parameterized statements
+	InsertModeSQL = "sql"
+
+	// SQL statement to insert rows, the columns and the rows of parameter placeholders are appended
+	InsertRows string = `INSERT INTO %s (%s) VALUES %s`
+	// the placeholder of bytes that are not valid UTF-8, sent as hex text and decoded
+	// back to the bytes by the server
+	HexBytesParameter string = `UNHEX(%s)`
+
+	sqlEndpoint = "/execute/sql"
+)
+
+// sqlRequest - the JSON request of the /execute/sql endpoint, the parameters are passed
+// as query_parameters so no value is ever part of the statement
+type sqlRequest struct {
+	Statement        string            `json:"statement"`
+	Offset           int64             `json:"offset"`
+	Limit            int64             `json:"limit"`
+	Encoding         string            `json:"encoding"`
+	RequestSchemaStr string            `json:"request_schema_str"`
+	Data             []string          `json:"data"`
+	Options          map[string]string `json:"options"`
+}
+
+// executeSQL runs a statement with its parameters, returning the size of the request
+//
+//	@receiver c
+//	@param ctx
+//	@param statement
+//	@param parameters
+//	@param options
+//	@return int
+//	@return error
+func (c *restClient) executeSQL(ctx context.Context, statement string, parameters []any, options map[string]string) (int, error) {
+	queryParameters, err := json.Marshal(parameters)
+	if err != nil {
+		return 0, err
+	}
+	requestOptions := map[string]string{"query_parameters": string(queryParameters)}
+	for key, value := range options {
+		requestOptions[key] = value
+	}
+
+	body, err := json.Marshal(sqlRequest{
+		Statement: statement,
+		Limit:     -9999,
+		Encoding:  "json",
+		Data:      []string{},
+		Options:   requestOptions,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := c.post(ctx, sqlEndpoint, nil, body); err != nil {
+		return 0, err
+	}
+	return len(body), nil
+}
+
+// sqlColumns - the sorted column names of a row
+//
+//	@param row
+//	@return []string
+func sqlColumns(row map[string]any) []string {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// insertRowsStatement - the statement inserting rows of columns with a $n placeholder per
+// value, the placeholders of the hex parameters are decoded with HexBytesParameter
+//
+//	@param qualifiedTable
+//	@param columns
+//	@param rows
+//	@param hexParameters
+//	@return string
+func insertRowsStatement(qualifiedTable string, columns []string, rows int, hexParameters map[int]bool) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = `"` + column + `"`
+	}
+
+	var values strings.Builder
+	n := 1
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			values.WriteString(", ")
+		}
+		values.WriteByte('(')
+		for i := range columns {
+			if i > 0 {
+				values.WriteString(", ")
+			}
+			placeholder := fmt.Sprintf("$%d", n)
+			if hexParameters[n] {
+				placeholder = fmt.Sprintf(HexBytesParameter, placeholder)
+			}
+			values.WriteString(placeholder)
+			n++
+		}
+		values.WriteByte(')')
+	}
+	return fmt.Sprintf(InsertRows, qualifiedTable, strings.Join(quoted, ", "), values.String())
+}
+
+// restInsertOptions - the insert options of a table for the SQL insert mode and the
+// JSON encoding
+//
+//	@receiver kiwriter
+//	@param tableName
+//	@return map[string]string
+func (kiwriter *KiWriter) restInsertOptions(tableName string) map[string]string {
+	insertOptions := kiwriter.insertOptions(tableName)
+	options := make(map[string]string)
+	if insertOptions.UpdateOnExistingPk {
+		options["update_on_existing_pk"] = "true"
+	}
+	if insertOptions.IgnoreExistingPk {
+		options["ignore_existing_pk"] = "true"
+	}
+	return options
+}
+
+// insertChunkSQL - inserts a chunk of records with one multi-row parameterized INSERT
+// statement, the chunks of the sql insert mode hold at most sql_rows_per_statement rows
+// so a chunk that fails is retried without duplicating rows committed before
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param data
+//	@return int64
+//	@return error
+func (kiwriter *KiWriter) insertChunkSQL(ctx context.Context, tableName string, data []any) (int64, error) {
+	rows := make([]map[string]any, 0, len(data))
+	for _, record := range data {
+		row, err := recordColumns(record)
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	columns := sqlColumns(rows[0])
+	parameters := make([]any, 0, len(rows)*len(columns))
+	hexParameters := make(map[int]bool)
+	for _, row := range rows {
+		for _, column := range columns {
+			parameter, isHex := sqlParameter(row[column])
+			parameters = append(parameters, parameter)
+			if isHex {
+				hexParameters[len(parameters)] = true
+			}
+		}
+	}
+	statement := insertRowsStatement(kiwriter.qualifiedTableName(tableName), columns, len(rows), hexParameters)
+	size, err := kiwriter.rest.executeSQL(ctx, statement, parameters, kiwriter.restInsertOptions(tableName))
+	return int64(size), err
+}
+
+// sqlParameter - the JSON query parameter of a column value. Bytes are sent as the string
+// of their bytes, which Kinetica stores unchanged in a bytes column, instead of the base64
+// text json.Marshal makes of them. Bytes that are not valid UTF-8 cannot be sent as a JSON
+// string and are sent as hex text instead, reported by isHex so their placeholder decodes them.
+//
+//	@param value
+//	@return parameter
+//	@return isHex
+func sqlParameter(value any) (parameter any, isHex bool) {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return value, false
+	}
+	if !utf8.Valid(bytes) {
+		return hex.EncodeToString(bytes), true
+	}
+	return string(bytes), false
+}
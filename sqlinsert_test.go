@@ -0,0 +1,110 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestSQLInsertDeadLettersOnlyTheRejectedRows(t *testing.T) {
+	server := newTestServer(t)
+	server.RejectRows(func(table string, row map[string]any) bool {
+		return table == "otel."+LogTable && row["severity_text"] == "rejected"
+	})
+	if err := server.CreateTable("otel."+DeadLetterTable, kineticatest.TypeSchemaOf(DeadLetter{}), nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg := newTestConfig(server)
+	cfg.InsertMode = InsertModeSQL
+	cfg.SQLRowsPerStatement = 2
+	cfg.DeadLetter.Enabled = true
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	if err := exporter.ConsumeLogs(context.Background(), testLogs("inserted", "inserted", "rejected", "inserted")); err != nil {
+		t.Fatalf("ConsumeLogs() = %v, want the rejected row dead-lettered", err)
+	}
+
+	// the rows committed by the other statements are neither dead-lettered nor duplicated
+	rows := server.Rows("otel." + LogTable)
+	if len(rows) != 3 {
+		t.Errorf("%s has %d rows, want the 3 inserted rows once: %v", LogTable, len(rows), rows)
+	}
+	deadLetters := server.Rows("otel." + DeadLetterTable)
+	if len(deadLetters) != 1 || !strings.Contains(deadLetters[0]["record"].(string), `"severity_text":"rejected"`) {
+		t.Errorf("%s has rows %v, want the rejected row only", DeadLetterTable, deadLetters)
+	}
+}
+
+func TestSQLInsertSendsBytes(t *testing.T) {
+	server := newTestServer(t)
+	cfg := newTestConfig(server)
+	cfg.InsertMode = InsertModeSQL
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	logs := testLogs("info")
+	attributes := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	attributes.PutEmptyBytes("payload").FromRaw([]byte("abc"))
+	attributes.PutEmptyBytes("binary").FromRaw([]byte{0xff, 0xfe})
+	if err := exporter.ConsumeLogs(context.Background(), logs); err != nil {
+		t.Fatalf("ConsumeLogs() = %v, want the bytes that are not valid UTF-8 sent as hex", err)
+	}
+
+	want := map[string]string{"payload": "abc", "binary": "\xff\xfe"}
+	rows := server.Rows("otel." + LogAttributeTable)
+	if len(rows) != len(want) {
+		t.Fatalf("%s has rows %v, want a row per attribute", LogAttributeTable, rows)
+	}
+	for _, row := range rows {
+		if row["bytes_value"] != want[row["key"].(string)] {
+			t.Errorf("%s has bytes %q for %v, want %q", LogAttributeTable, row["bytes_value"], row["key"], want[row["key"].(string)])
+		}
+	}
+	var statement string
+	for _, s := range server.Statements() {
+		if strings.Contains(s, LogAttributeTable) {
+			statement = s
+		}
+	}
+	if strings.Count(statement, "UNHEX(") != 1 {
+		t.Errorf("statement %q, want UNHEX for the bytes that are not valid UTF-8 only", statement)
+	}
+}
+
+func TestSQLInsertWithoutSchema(t *testing.T) {
+	server := kineticatest.NewServer(t)
+	cfg := newTestConfig(server)
+	cfg.Schema = ""
+	cfg.CreateTables = true
+	cfg.UpdateOnExistingPk = true
+	cfg.InsertMode = InsertModeSQL
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	if err := exporter.ConsumeLogs(context.Background(), testLogs("info")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tables are named without a schema rather than in an empty one
+	for _, statement := range server.Statements() {
+		if strings.Contains(statement, `""`) {
+			t.Errorf("statement %q names an empty schema", statement)
+		}
+	}
+	if rows := server.Rows(LogTable); len(rows) != 1 {
+		t.Errorf("%s has rows %v, want the inserted row", LogTable, rows)
+	}
+}
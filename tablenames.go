@@ -0,0 +1,102 @@
+package kineticaotelexporter
+
+// forSignal - the config of the writer of a signal, Schema is the schema of the signal
+// and every signal schema is resolved so other signals' tables can be referenced
+//
+//	@receiver cfg
+//	@param signal
+//	@return Config
+func (cfg Config) forSignal(signal string) Config {
+	cfg.Schemas = SignalSchemasConfig{
+		Logs:    cfg.signalSchema(SignalLogs),
+		Traces:  cfg.signalSchema(SignalTraces),
+		Metrics: cfg.signalSchema(SignalMetrics),
+	}
+	cfg.Schema = cfg.signalSchema(signal)
+	return cfg
+}
+
+// signalSchema - the schema of the tables of a signal
+//
+//	@receiver cfg
+//	@param signal
+//	@return string
+func (cfg *Config) signalSchema(signal string) string {
+	var schema string
+	switch signal {
+	case SignalLogs:
+		schema = cfg.Schemas.Logs
+	case SignalTraces:
+		schema = cfg.Schemas.Traces
+	case SignalMetrics:
+		schema = cfg.Schemas.Metrics
+	}
+	if schema == "" {
+		return cfg.Schema
+	}
+	return schema
+}
+
+// qualifiedName - the name of a table in SQL statements, "schema"."table", or "table"
+// in the default schema of the user when schema is empty
+//
+//	@param schema
+//	@param table
+//	@return string
+func qualifiedName(schema string, table string) string {
+	if schema == "" {
+		return `"` + table + `"`
+	}
+	return `"` + schema + `"."` + table + `"`
+}
+
+// qualifiedTableName - the name of a logical table of the writer in SQL statements
+//
+//	@receiver kiwriter
+//	@param logical
+//	@return string
+func (kiwriter *KiWriter) qualifiedTableName(logical string) string {
+	return qualifiedName(kiwriter.cfg.Schema, kiwriter.tableName(logical))
+}
+
+// physical - the physical name of a logical table
+//
+//	@receiver tc
+//	@param logical
+//	@return string
+func (tc *TableNamesConfig) physical(logical string) string {
+	if physical, ok := tc.Overrides[logical]; ok {
+		return physical
+	}
+	return tc.Prefix + logical + tc.Suffix
+}
+
+// tableName - the physical name of a logical table, with the table prefix of the
+// routing destination of the writer
+//
+//	@receiver kiwriter
+//	@param logical
+//	@return string
+func (kiwriter *KiWriter) tableName(logical string) string {
+	return kiwriter.tablePrefix + kiwriter.cfg.TableNames.physical(logical)
+}
+
+// logicalTables - the logical names of the tables and views the exporter writes or
+// creates with the config
+//
+//	@receiver cfg
+//	@return map[string]bool
+func (cfg *Config) logicalTables() map[string]bool {
+	tables := make(map[string]bool, len(orderedTables)+len(rollupTables)*len(cfg.Rollup.Levels)+2)
+	for table := range orderedTables {
+		tables[table] = true
+	}
+	tables[ExemplarTraceSpanView] = true
+	tables[cfg.DeadLetter.Table] = true
+	for _, table := range rollupTables {
+		for _, level := range cfg.Rollup.Levels {
+			tables[rollupTableName(table, level.Resolution)] = true
+		}
+	}
+	return tables
+}
@@ -0,0 +1,74 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/am-kinetica/kineticaexporter/internal/kineticatest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestTableNamesMapLogicalTables(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.TableNames = TableNamesConfig{Prefix: "otel_", Suffix: "_v2", Overrides: map[string]string{LogTable: "log_records"}}
+	cfg.DeadLetter.Enabled = true
+
+	server := kineticatest.NewServer(t)
+	for table, record := range tableRecords {
+		if err := server.CreateTable("otel."+cfg.TableNames.physical(table), kineticatest.TypeSchemaOf(record), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := server.CreateTable("otel.otel_dead_letter_v2", kineticatest.TypeSchemaOf(DeadLetter{}), nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Host = server.URL
+	exporter, err := NewFactory().CreateLogsExporter(context.Background(), exportertest.NewNopCreateSettings(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startExporter(t, exporter)
+
+	if err := exporter.ConsumeLogs(context.Background(), testLogs("info")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(server.Rows("otel.log_records")); got != 1 {
+		t.Errorf("log_records has %d rows, want the overridden log table written", got)
+	}
+	if got := len(server.Rows("otel.otel_log_scope_attribute_v2")); got != 1 {
+		t.Errorf("otel_log_scope_attribute_v2 has %d rows, want 1", got)
+	}
+	var created bool
+	for _, statement := range server.Statements() {
+		created = created || strings.Contains(statement, `CREATE TABLE IF NOT EXISTS "otel"."otel_dead_letter_v2"`)
+	}
+	if !created {
+		t.Errorf("the dead letter table was not created with its physical name: %v", server.Statements())
+	}
+}
+
+func TestTableNamesValidate(t *testing.T) {
+	tests := []struct {
+		logical string
+		valid   bool
+	}{
+		{LogTable, true},
+		{ExemplarTraceSpanView, true},
+		{DeadLetterTable, true},
+		{rollupTableName(GaugeDatapointTable, time.Minute), true},
+		{"metric_gauge_datapoint_rollup_7m", false},
+		{"unknown", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.logical, func(t *testing.T) {
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.TableNames.Overrides = map[string]string{tt.logical: "physical"}
+			if err := cfg.TableNames.Validate(cfg.logicalTables()); (err == nil) != tt.valid {
+				t.Errorf("Validate() of an override of %s = %v, want valid %v", tt.logical, err, tt.valid)
+			}
+		})
+	}
+}
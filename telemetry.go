@@ -0,0 +1,375 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.uber.org/multierr"
+)
+
+const (
+	// Name of the meter the exporter self-metrics are published under
+	exporterMeterName = "github.com/am-kinetica/kineticaexporter"
+
+	// Reasons an attribute is dropped by the exporter
+	DropReasonEmptyKey        = "empty_key"
+	DropReasonConversionError = "conversion_error"
+
+	// Signals
+	SignalLogs    = "logs"
+	SignalTraces  = "traces"
+	SignalMetrics = "metrics"
+
+	// Classes of insert errors
+	ErrorClassCanceled    = "canceled"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassNetwork     = "network"
+	ErrorClassUnavailable = "unavailable"
+	ErrorClassNotFound    = "not_found"
+	ErrorClassAuth        = "auth"
+	ErrorClassRecord      = "record"
+	ErrorClassEncoding    = "encoding"
+	ErrorClassServer      = "server"
+)
+
+// exporterTelemetry - the self-metrics published by one exporter instance through
+// the collector's telemetry settings
+type exporterTelemetry struct {
+	exporterAttr attribute.KeyValue
+	signalAttr   attribute.KeyValue
+
+	droppedAttributes  instrument.Int64Counter
+	conversionFailures instrument.Int64Counter
+	recordsWritten     instrument.Int64Counter
+	insertDuration     instrument.Float64Histogram
+	chunkErrors        instrument.Int64Counter
+	bytesSent          instrument.Int64Counter
+	insertsInFlight    instrument.Int64UpDownCounter
+	chunkRecords       instrument.Int64Histogram
+	chunkBytes         instrument.Int64Histogram
+
+	spillBacklogBatches  instrument.Int64UpDownCounter
+	spillBacklogBytes    instrument.Int64UpDownCounter
+	spillReplayedRecords instrument.Int64Counter
+	spillDroppedBatches  instrument.Int64Counter
+}
+
+// newExporterTelemetry
+//
+//	@param set
+//	@param id
+//	@param signal
+//	@return *exporterTelemetry
+//	@return error
+func newExporterTelemetry(set component.TelemetrySettings, id component.ID, signal string) (*exporterTelemetry, error) {
+	meter := set.MeterProvider.Meter(exporterMeterName)
+
+	var errs, err error
+	t := &exporterTelemetry{
+		exporterAttr: attribute.String("exporter", id.String()),
+		signalAttr:   attribute.String("signal", signal),
+	}
+
+	t.droppedAttributes, err = meter.Int64Counter(
+		"kinetica_exporter_dropped_attributes",
+		instrument.WithDescription("Number of attributes dropped by the exporter because they could not be stored"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.conversionFailures, err = meter.Int64Counter(
+		"kinetica_exporter_conversion_failures",
+		instrument.WithDescription("Number of telemetry records that could not be converted to Kinetica rows"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.recordsWritten, err = meter.Int64Counter(
+		"kinetica_exporter_records_written",
+		instrument.WithDescription("Number of rows inserted into Kinetica per table"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.insertDuration, err = meter.Float64Histogram(
+		"kinetica_exporter_insert_duration",
+		instrument.WithDescription("Duration of one chunked insert into Kinetica per table"),
+		instrument.WithUnit("ms"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.chunkErrors, err = meter.Int64Counter(
+		"kinetica_exporter_chunk_errors",
+		instrument.WithDescription("Number of chunks Kinetica failed to insert per table and error class"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.bytesSent, err = meter.Int64Counter(
+		"kinetica_exporter_bytes_sent",
+		instrument.WithDescription("Size of the Avro encoded rows sent to Kinetica per table"),
+		instrument.WithUnit("By"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.insertsInFlight, err = meter.Int64UpDownCounter(
+		"kinetica_exporter_inserts_in_flight",
+		instrument.WithDescription("Number of insert goroutines currently waiting on Kinetica"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.chunkRecords, err = meter.Int64Histogram(
+		"kinetica_exporter_chunk_records",
+		instrument.WithDescription("Number of rows of one insert chunk per table"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.chunkBytes, err = meter.Int64Histogram(
+		"kinetica_exporter_chunk_bytes",
+		instrument.WithDescription("Estimated encoded size of one insert chunk per table"),
+		instrument.WithUnit("By"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.spillBacklogBatches, err = meter.Int64UpDownCounter(
+		"kinetica_exporter_spill_backlog_batches",
+		instrument.WithDescription("Number of batches waiting in the spill directory to be replayed"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.spillBacklogBytes, err = meter.Int64UpDownCounter(
+		"kinetica_exporter_spill_backlog_bytes",
+		instrument.WithDescription("Size of the batches waiting in the spill directory to be replayed"),
+		instrument.WithUnit("By"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.spillReplayedRecords, err = meter.Int64Counter(
+		"kinetica_exporter_spill_replayed_records",
+		instrument.WithDescription("Number of spilled rows replayed into Kinetica"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	t.spillDroppedBatches, err = meter.Int64Counter(
+		"kinetica_exporter_spill_dropped_batches",
+		instrument.WithDescription("Number of spilled batches dropped without being replayed"),
+		instrument.WithUnit("1"),
+	)
+	errs = multierr.Append(errs, err)
+
+	if errs != nil {
+		return nil, errs
+	}
+	return t, nil
+}
+
+// recordDroppedAttribute - counts one attribute dropped by the exporter
+//
+//	@receiver t
+//	@param reason
+func (t *exporterTelemetry) recordDroppedAttribute(reason string) {
+	if t == nil {
+		return
+	}
+	t.droppedAttributes.Add(context.Background(), 1, t.exporterAttr, t.signalAttr, attribute.String("reason", reason))
+}
+
+// recordConversionFailure - counts one log record, span or metric that could not be converted
+//
+//	@receiver t
+func (t *exporterTelemetry) recordConversionFailure() {
+	if t == nil {
+		return
+	}
+	t.conversionFailures.Add(context.Background(), 1, t.exporterAttr, t.signalAttr)
+}
+
+// startInsert - marks a chunk insert into a table as in flight, the returned func
+// records its outcome and must be called once the insert returns
+//
+//	@receiver t
+//	@param ctx
+//	@param table
+//	@param records
+//	@return func(bytes int64, err error)
+func (t *exporterTelemetry) startInsert(ctx context.Context, table string, records int) func(bytes int64, err error) {
+	if t == nil {
+		return func(int64, error) {}
+	}
+
+	tableAttr := attribute.String("table", table)
+	t.insertsInFlight.Add(ctx, 1, t.exporterAttr, t.signalAttr)
+	start := time.Now()
+
+	return func(bytes int64, err error) {
+		t.insertsInFlight.Add(ctx, -1, t.exporterAttr, t.signalAttr)
+		t.insertDuration.Record(ctx, float64(time.Since(start).Microseconds())/1000, t.exporterAttr, t.signalAttr, tableAttr)
+		if err != nil {
+			t.chunkErrors.Add(ctx, 1, t.exporterAttr, t.signalAttr, tableAttr, attribute.String("error_class", classifyInsertError(err)))
+			return
+		}
+		t.recordsWritten.Add(ctx, int64(records), t.exporterAttr, t.signalAttr, tableAttr)
+		t.bytesSent.Add(ctx, bytes, t.exporterAttr, t.signalAttr, tableAttr)
+	}
+}
+
+// recordChunk - records the number of rows and the estimated size of one insert chunk
+//
+//	@receiver t
+//	@param ctx
+//	@param table
+//	@param records
+//	@param bytes
+func (t *exporterTelemetry) recordChunk(ctx context.Context, table string, records int, bytes int) {
+	if t == nil {
+		return
+	}
+	tableAttr := attribute.String("table", table)
+	t.chunkRecords.Record(ctx, int64(records), t.exporterAttr, t.signalAttr, tableAttr)
+	t.chunkBytes.Record(ctx, int64(bytes), t.exporterAttr, t.signalAttr, tableAttr)
+}
+
+// recordSpillBacklog - adjusts the spill backlog by a number of batches and bytes
+//
+//	@receiver t
+//	@param batches
+//	@param bytes
+func (t *exporterTelemetry) recordSpillBacklog(batches int64, bytes int64) {
+	if t == nil {
+		return
+	}
+	t.spillBacklogBatches.Add(context.Background(), batches, t.exporterAttr, t.signalAttr)
+	t.spillBacklogBytes.Add(context.Background(), bytes, t.exporterAttr, t.signalAttr)
+}
+
+// recordSpillReplayed - counts the rows of a replayed spill batch
+//
+//	@receiver t
+//	@param records
+func (t *exporterTelemetry) recordSpillReplayed(records int64) {
+	if t == nil {
+		return
+	}
+	t.spillReplayedRecords.Add(context.Background(), records, t.exporterAttr, t.signalAttr)
+}
+
+// recordSpillDropped - counts one spill batch dropped without being replayed
+//
+//	@receiver t
+//	@param reason
+func (t *exporterTelemetry) recordSpillDropped(reason string) {
+	if t == nil {
+		return
+	}
+	t.spillDroppedBatches.Add(context.Background(), 1, t.exporterAttr, t.signalAttr, attribute.String("reason", reason))
+}
+
+// classifyInsertError - a coarse class of an insert error suitable as a metric attribute
+//
+//	@param err
+//	@return string
+func classifyInsertError(err error) string {
+	var netErr net.Error
+	var responseErr *responseError
+	switch {
+	case errors.As(err, &responseErr):
+		switch responseErr.statusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrorClassAuth
+		case http.StatusNotFound:
+			return ErrorClassNotFound
+		default:
+			return ErrorClassUnavailable
+		}
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case isRecordErrorMessage(message):
+		return ErrorClassRecord
+	case strings.Contains(message, "avro: read"):
+		// the response of Kinetica could not be decoded
+		return ErrorClassUnavailable
+	case strings.Contains(message, "does not exist") || strings.Contains(message, "not found"):
+		return ErrorClassNotFound
+	case strings.Contains(message, "unauthorized") || strings.Contains(message, "permission") || strings.Contains(message, "authenticat"):
+		return ErrorClassAuth
+	case strings.Contains(message, "avro") || strings.Contains(message, "encode") || strings.Contains(message, "schema"):
+		return ErrorClassEncoding
+	case strings.Contains(message, "connection refused") || strings.Contains(message, "no such host") || strings.Contains(message, "eof"):
+		return ErrorClassNetwork
+	default:
+		return ErrorClassServer
+	}
+}
+
+// recordErrorMessages - the messages Kinetica rejects the values of a record with and
+// the exporter fails to encode a record with
+var recordErrorMessages = []string{
+	"invalid value",
+	"out of range",
+	"too long",
+	"exceeds",
+	"cannot be null",
+	"not nullable",
+	"type mismatch",
+	"cannot encode a row",
+}
+
+// isRecordErrorMessage - whether a lower case error message blames the values of a record
+//
+//	@param message
+//	@return bool
+func isRecordErrorMessage(message string) bool {
+	for _, recordMessage := range recordErrorMessages {
+		if strings.Contains(message, recordMessage) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryableInsertError - whether an insert may succeed when retried later, i.e.
+// Kinetica could not be reached, did not answer in time or answered with something
+// other than a Kinetica response, e.g. the error page of a proxy
+//
+//	@param err
+//	@return bool
+func isRetryableInsertError(err error) bool {
+	switch classifyInsertError(err) {
+	case ErrorClassTimeout, ErrorClassNetwork, ErrorClassUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRecordInsertError - whether an insert failed because of some of the rows it sent,
+// i.e. Kinetica rejected their values or they could not be encoded, rather than because
+// of the table, the credentials, the connection or Kinetica itself
+//
+//	@param err
+//	@return bool
+func isRecordInsertError(err error) bool {
+	return classifyInsertError(err) == ErrorClassRecord
+}
@@ -3,9 +3,10 @@ package kineticaotelexporter
 import (
 	"context"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
+	"sync"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/multierr"
@@ -14,39 +15,79 @@ import (
 
 // kineticaTracesExporter
 type kineticaTracesExporter struct {
-	logger *zap.Logger
+	logger    *zap.Logger
+	telemetry *exporterTelemetry
 
 	writer *KiWriter
-}
-
-type kineticaTraceRecord struct {
-	span              *Span
-	spanAttribute     []SpanAttribute
-	resourceAttribute []ResourceAttribute
-	scopeAttribute    []ScopeAttribute
-	eventAttribute    []EventAttribute
-	linkAttribute     []LinkAttribute
+	// batches - the pooled trace batches the pushes are converted into
+	batches sync.Pool
 }
 
 // newTracesExporter
 //
 //	@param logger
+//	@param telemetry
 //	@param cfg
 //	@return *kineticaTracesExporter
 //	@return error
-func newTracesExporter(logger *zap.Logger, cfg *Config) (*kineticaTracesExporter, error) {
+func newTracesExporter(logger *zap.Logger, telemetry *exporterTelemetry, cfg *Config) (*kineticaTracesExporter, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
-	writer := NewKiWriter(context.TODO(), *cfg, logger)
+	writer := NewKiWriter(context.TODO(), cfg.forSignal(SignalTraces), logger, telemetry)
+	if err := writer.enableDeadLetter(SignalTraces); err != nil {
+		return nil, err
+	}
+	if err := writer.enableSpill(SignalTraces); err != nil {
+		return nil, err
+	}
+	if err := writer.enableBulk(SignalTraces); err != nil {
+		return nil, err
+	}
+	writer.enableRetention(SignalTraces)
+	writer.enableRouting(SignalTraces)
 	tracesExp := &kineticaTracesExporter{
-		logger: logger,
-		writer: writer,
+		logger:    logger,
+		telemetry: telemetry,
+		writer:    writer,
+	}
+	tracesExp.batches.New = func() any {
+		return newTraceBatch(logger, telemetry)
 	}
 	return tracesExp, nil
 }
 
+// start
+//
+//	@receiver e
+//	@param ctx
+//	@param host
+//	@return error
+func (e *kineticaTracesExporter) start(ctx context.Context, host component.Host) error {
+	if e.writer.cfg.CreateTables {
+		if err := e.writer.createTables(ctx, SignalTraces); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.ensureColumns(ctx, signalTables[SignalTraces]); err != nil {
+		return err
+	}
+	if err := e.writer.checkPrimaryKeys(ctx, SignalTraces); err != nil {
+		return err
+	}
+	return e.writer.start(ctx)
+}
+
+// shutdown
+//
+//	@receiver e
+//	@param ctx
+//	@return error
+func (e *kineticaTracesExporter) shutdown(ctx context.Context) error {
+	return e.writer.shutdown(ctx)
+}
+
 // pushTraceData
 //
 //	@receiver e
@@ -54,283 +95,173 @@ func newTracesExporter(logger *zap.Logger, cfg *Config) (*kineticaTracesExporter
 //	@param td
 //	@return error
 func (e *kineticaTracesExporter) pushTraceData(ctx context.Context, td ptrace.Traces) error {
+	// the data of a destination that cannot be bootstrapped fails, the other
+	// destinations are still written
+	routed, err := e.writer.routeTraces(ctx, td)
+	errs := []error{err}
+	for writer, data := range routed {
+		if err := e.pushTraces(ctx, writer, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+// pushTraces - converts and writes the traces of one destination
+//
+//	@receiver e
+//	@param ctx
+//	@param writer
+//	@param td
+//	@return error
+func (e *kineticaTracesExporter) pushTraces(ctx context.Context, writer *KiWriter, td ptrace.Traces) error {
+	batch := e.batches.Get().(*traceBatch)
+	defer func() {
+		batch.reset()
+		e.batches.Put(batch)
+	}()
+
 	var errs []error
-	var traceRecords []kineticaTraceRecord
 	resourceSpans := td.ResourceSpans()
 	for i := 0; i < resourceSpans.Len(); i++ {
 		resourceSpan := resourceSpans.At(i)
 		resource := resourceSpan.Resource()
+		batch.shared.setResource(batch.converter, resource)
 		scopeSpans := resourceSpan.ScopeSpans()
 		for j := 0; j < scopeSpans.Len(); j++ {
 			spans := scopeSpans.At(j).Spans()
 			scope := scopeSpans.At(j).Scope()
+			scopeURL := scopeSpans.At(j).SchemaUrl()
+			batch.shared.setScope(batch.converter, scope)
 			for k := 0; k < spans.Len(); k++ {
-				if kiTraceRecord, err := e.createTraceRecord(ctx, resource, scope, spans.At(k)); err != nil {
+				if err := e.appendSpan(batch, resourceSpan.SchemaUrl(), scope, scopeURL, spans.At(k)); err != nil {
 					if cerr := ctx.Err(); cerr != nil {
 						return cerr
 					}
+					e.telemetry.recordConversionFailure()
+					span := spans.At(k)
+					if err := writer.deadLetterRecord(ctx, TraceSpanTable, err, func() ([]byte, error) {
+						return otlpSpanJSON(resource, resourceSpan.SchemaUrl(), scope, scopeURL, span)
+					}); err != nil {
+						errs = append(errs, err)
+					}
+				}
+
+				if err := writer.flushFullRows(ctx, batch.tables); err != nil {
 					errs = append(errs, err)
-				} else {
-					traceRecords = append(traceRecords, *kiTraceRecord)
 				}
 			}
 		}
 	}
 
-	if err := e.writer.persistTraceRecord(traceRecords); err != nil {
+	// the rows left after the last full chunks
+	if err := writer.writeRows(ctx, batch.tables); err != nil {
 		errs = append(errs, err)
 	}
 
 	return multierr.Combine(errs...)
 }
 
-// createTraceRecord //
+// appendSpan - appends the rows of a span to the batch, the resource and scope attributes
+// are the ones converted for its scope. Every child row holds the hex span id of the span
+// in span_id and its record id, which is unique across traces unlike the span id, in
+// span_record_id. A span without trace ID, span ID or start time is not appended.
 //
 //	@receiver e
-//	@param ctx
-//	@param resource
+//	@param batch
+//	@param schemaURL
 //	@param scope
-//	@param span
+//	@param scopeURL
+//	@param spanRecord
 //	@return error
-func (e *kineticaTracesExporter) createTraceRecord(ctx context.Context, resource pcommon.Resource, scope pcommon.InstrumentationScope, spanRecord ptrace.Span) (*kineticaTraceRecord, error) {
-	var errs []error
-
-	tags := make(map[string]string)
-	fields := make(map[string]interface{})
-
+func (e *kineticaTracesExporter) appendSpan(batch *traceBatch, schemaURL string, scope pcommon.InstrumentationScope, scopeURL string, spanRecord ptrace.Span) error {
 	traceID := spanRecord.TraceID()
 	if traceID.IsEmpty() {
-		return nil, errors.New("span has no trace ID")
+		return errors.New("span has no trace ID")
 	}
 	spanID := spanRecord.SpanID()
 	if spanID.IsEmpty() {
-		return nil, errors.New("span has no span ID")
-	}
-
-	tags[AttributeTraceID] = hex.EncodeToString(traceID[:])
-	tags[AttributeSpanID] = hex.EncodeToString(spanID[:])
-
-	if traceState := spanRecord.TraceState().AsRaw(); traceState != "" {
-		fields[AttributeTraceState] = traceState
-	}
-	if parentSpanID := spanRecord.ParentSpanID(); !parentSpanID.IsEmpty() {
-		fields[AttributeParentSpanID] = hex.EncodeToString(parentSpanID[:])
-	}
-	if name := spanRecord.Name(); name != "" {
-		fields[AttributeName] = name
+		return errors.New("span has no span ID")
 	}
-	if kind := spanRecord.Kind(); kind != ptrace.SpanKindUnspecified {
-		fields[AttributeSpanKind] = kind.String()
-	}
-
 	ts := spanRecord.StartTimestamp().AsTime().UnixNano()
 	if ts == 0 {
-		return nil, errors.New("span has no timestamp")
+		return errors.New("span has no timestamp")
 	}
 
-	endTime := spanRecord.EndTimestamp().AsTime().UnixNano()
-	if endTime == 0 {
-		fields[AttributeEndTimeUnixNano] = endTime
-		fields[AttributeDurationNano] = endTime - ts
+	var droppedAttributesCount int
+	batch.attributes, droppedAttributesCount, _ = batch.converter.convert(batch.attributes[:0], spanRecord.Attributes(), "span", nil)
+	droppedAttributesCount += int(spanRecord.DroppedAttributesCount())
+
+	traceHex := hex.EncodeToString(traceID[:])
+	spanHex := hex.EncodeToString(spanID[:])
+	span := Span{
+		ID:                     spanRecordID(traceHex, spanHex),
+		TraceID:                traceHex,
+		SpanID:                 spanHex,
+		ParentSpanID:           spanIDToHex(spanRecord.ParentSpanID()),
+		TraceState:             spanRecord.TraceState().AsRaw(),
+		Name:                   spanRecord.Name(),
+		SpanKind:               int8(spanRecord.Kind()),
+		StartTimeUnixNano:      ts,
+		EndTimeUnixNano:        spanRecord.EndTimestamp().AsTime().UnixNano(),
+		DroppedAttributesCount: droppedAttributesCount,
+		DroppedEventsCount:     int(spanRecord.DroppedEventsCount()),
+		DroppedLinksCount:      int(spanRecord.DroppedLinksCount()),
+		Message:                spanRecord.Status().Message(),
+		StatusCode:             int8(spanRecord.Status().Code()),
 	}
+	batch.spans.add(span)
 
-	droppedAttributesCount := uint64(spanRecord.DroppedAttributesCount())
-	if spanRecord.Attributes().Len() > 0 {
-		marshalledAttributes, err := json.Marshal(spanRecord.Attributes().AsRaw())
-		if err != nil {
-			e.logger.Debug("failed to marshal attributes to JSON %s", zap.String("", err.Error()))
-			droppedAttributesCount += uint64(spanRecord.Attributes().Len())
-		} else {
-			fields[AttributeAttributes] = string(marshalledAttributes)
-		}
+	for _, attribute := range batch.attributes {
+		batch.spanAttributes.add(SpanAttribute{span.SpanID, span.ID, attribute.key, attribute.value})
 	}
-	if droppedAttributesCount > 0 {
-		fields[AttributeDroppedAttributesCount] = droppedAttributesCount
-	}
-
-	droppedEventsCount := spanRecord.DroppedEventsCount()
 
-	kiTraceRecord := new(kineticaTraceRecord)
-	span := NewSpan(tags[AttributeTraceID], tags[AttributeSpanID], fields[AttributeParentSpanID].(string), fields[AttributeTraceState].(string), fields[AttributeName].(string), fields[AttributeSpanKind].(int8), ts, endTime, int(droppedAttributesCount), int(droppedEventsCount), 0, "", 0)
-	kiTraceRecord.span = span
-
-	var spanAttribute []SpanAttribute
-	spanAttributes := make(map[string]ValueTypePair)
-	spanRecord.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			droppedAttributesCount++
-			e.logger.Debug("log record attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-			droppedAttributesCount++
-			e.logger.Debug("invalid log record attribute value", zap.String("Error", err.Error()))
-		} else {
-			spanAttributes[k] = v
-		}
-		return true
-	})
-
-	for key := range spanAttributes {
-		vtPair := spanAttributes[key]
-		sa := newSpanAttributeValue(span.ID, key, vtPair)
-		spanAttribute = append(spanAttribute, *sa)
+	for _, attribute := range batch.shared.resource {
+		batch.resourceAttributes.add(TraceResourceAttribute{span.SpanID, span.ID, attribute.key, schemaURL, batch.shared.resourceDropped, attribute.value})
 	}
 
-	copy(kiTraceRecord.spanAttribute, spanAttribute)
-
-	var resourceAttribute []ResourceAttribute
-	resourceAttributes := make(map[string]ValueTypePair)
-	resource.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("resource attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-			e.logger.Debug("Invalid resource attribute value", zap.String("Error", err.Error()))
-		} else {
-			resourceAttributes[k] = v
-		}
-		return true
-	})
-
-	for key := range resourceAttributes {
-		vtPair := resourceAttributes[key]
-		ra := newResourceAttributeValue(span.SpanID, key, vtPair)
-		resourceAttribute = append(resourceAttribute, *ra)
-	}
-
-	copy(kiTraceRecord.resourceAttribute, resourceAttribute)
-
-	// Insert scope attributes
-	var scopeAttribute []ScopeAttribute
-	scopeAttributes := make(map[string]ValueTypePair)
 	scopeName := scope.Name()
 	scopeVersion := scope.Version()
-	scope.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if k == "" {
-			e.logger.Debug("scope attribute key is empty")
-		} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-			e.logger.Debug("invalid scope attribute value", zap.String("Error", err.Error()))
-		} else {
-			scopeAttributes[k] = v
-		}
-		return true
-	})
-
-	for key := range scopeAttributes {
-		vtPair := scopeAttributes[key]
-		sa := newScopeAttributeValue(span.SpanID, key, scopeName, scopeVersion, vtPair)
-		scopeAttribute = append(scopeAttribute, *sa)
-
+	for _, attribute := range batch.shared.scope {
+		batch.scopeAttributes.add(TraceScopeAttribute{span.SpanID, span.ID, scopeName, scopeVersion, attribute.key, scopeURL, batch.shared.scopeDropped, attribute.value})
+	}
+	if len(batch.shared.scope) == 0 {
+		// No attributes found - just basic scope
+		batch.scopeAttributes.add(TraceScopeAttribute{span.SpanID, span.ID, scopeName, scopeVersion, "", scopeURL, batch.shared.scopeDropped, AttributeValue{}})
 	}
 
-	copy(kiTraceRecord.scopeAttribute, scopeAttribute)
-
-	//Insert event attributes
-	var eventAttribute []EventAttribute
-	eventAttributes := make(map[string]ValueTypePair)
 	spanEvents := spanRecord.Events()
-
 	for i := 0; i < spanEvents.Len(); i++ {
 		event := spanEvents.At(i)
-		event.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Event attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-				e.logger.Debug("invalid event attribute value", zap.String("Error", err.Error()))
-			} else {
-				eventAttributes[k] = v
-				ea := newEventAttributeValue(span.SpanID, event.Name(), k, v)
-				eventAttribute = append(eventAttribute, *ea)
-			}
-			return true
-		})
+		var eventDroppedAttributesCount int
+		batch.attributes, eventDroppedAttributesCount, _ = batch.converter.convert(batch.attributes[:0], event.Attributes(), "event", nil)
+		eventDroppedAttributesCount += int(event.DroppedAttributesCount())
+		for _, attribute := range batch.attributes {
+			batch.eventAttributes.add(EventAttribute{span.SpanID, span.ID, i, event.Name(), attribute.key, eventDroppedAttributesCount, attribute.value})
+		}
+		if len(batch.attributes) == 0 {
+			// No attributes found - just the event name and dropped count
+			batch.eventAttributes.add(EventAttribute{span.SpanID, span.ID, i, event.Name(), "", eventDroppedAttributesCount, AttributeValue{}})
+		}
 	}
 
-	copy(kiTraceRecord.eventAttribute, eventAttribute)
-
-	//Insert link attributes
-	var linkAttribute []LinkAttribute
-	linkAttributes := make(map[string]ValueTypePair)
 	spanLinks := spanRecord.Links()
-
 	for i := 0; i < spanLinks.Len(); i++ {
 		link := spanLinks.At(i)
-		link.Attributes().Range(func(k string, v pcommon.Value) bool {
-			if k == "" {
-				e.logger.Debug("Event attribute key is empty")
-			} else if v, err := AttributeValueToKineticaFieldValue(v); err != nil {
-				e.logger.Debug("invalid event attribute value", zap.String("Error", err.Error()))
-			} else {
-				linkAttributes[k] = v
-				la := newLinkAttributeValue(span.SpanID, span.TraceID, span.SpanID, k, v)
-				linkAttribute = append(linkAttribute, *la)
-			}
-			return true
-		})
-	}
-
-	copy(kiTraceRecord.linkAttribute, linkAttribute)
-
-	return kiTraceRecord, multierr.Combine(errs...)
-}
-
-// newLinkAttributeValue
-//
-//	@param linkID
-//	@param traceID
-//	@param spanID
-//	@param key
-//	@param vtPair
-//	@return *LinkAttribute
-func newLinkAttributeValue(linkID string, traceID, spanID, key string, vtPair ValueTypePair) *LinkAttribute {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-
-	if err != nil {
-		la := NewLinkAttribute(linkID, traceID, spanID, key, *av)
-		return la
-	}
-
-	return nil
-}
-
-// newEventAttributeValue
-//
-//	@param eventID
-//	@param eventName
-//	@param key
-//	@param vtPair
-//	@return *EventAttribute
-func newEventAttributeValue(eventID string, eventName string, key string, vtPair ValueTypePair) *EventAttribute {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-
-	if err != nil {
-		sa := NewEventAttribute(eventID, eventName, key, *av)
-		return sa
-	}
-
-	return nil
-}
-
-// newSpanAttributeValue
-//
-//	@param spanID
-//	@param key
-//	@param vtPair
-//	@return *SpanAttribute
-func newSpanAttributeValue(spanID string, key string, vtPair ValueTypePair) *SpanAttribute {
-	var av *AttributeValue
-	var err error
-
-	av, err = getAttributeValue(vtPair)
-
-	if err != nil {
-		sa := NewSpanAttribute(spanID, key, *av)
-		return sa
+		var linkDroppedAttributesCount int
+		batch.attributes, linkDroppedAttributesCount, _ = batch.converter.convert(batch.attributes[:0], link.Attributes(), "link", nil)
+		linkDroppedAttributesCount += int(link.DroppedAttributesCount())
+		linkTraceID := link.TraceID()
+		linkSpanID := link.SpanID()
+		linkTraceHex := hex.EncodeToString(linkTraceID[:])
+		linkSpanHex := hex.EncodeToString(linkSpanID[:])
+		for _, attribute := range batch.attributes {
+			batch.linkAttributes.add(LinkAttribute{span.SpanID, span.ID, i, linkTraceHex, linkSpanHex, attribute.key, linkDroppedAttributesCount, attribute.value})
+		}
+		if len(batch.attributes) == 0 {
+			// No attributes found - just the linked span and dropped count
+			batch.linkAttributes.add(LinkAttribute{span.SpanID, span.ID, i, linkTraceHex, linkSpanHex, "", linkDroppedAttributesCount, AttributeValue{}})
+		}
 	}
-
 	return nil
 }
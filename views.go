@@ -0,0 +1,78 @@
+package kineticaotelexporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SQL statements to create the optional views
+const (
+	CreateExemplarTraceSpanView string = `CREATE OR REPLACE VIEW %s AS
+%s`
+
+	// selects the exemplars of one metric type together with the span they were recorded in
+	selectExemplarTraceSpan string = `SELECT
+		'%s' AS metric_type,
+		m.metric_name,
+		e.datapoint_id,
+		e.exemplar_id,
+		e.time_unix,
+		e.%s AS exemplar_value,
+		e.trace_id,
+		e.span_id,
+		s.name AS span_name,
+		s.span_kind,
+		s.start_time_unix_nano,
+		s.end_time_unix_nano,
+		s.status_code
+	FROM %s e
+	INNER JOIN %s m ON m.%s = e.%s
+	LEFT JOIN %s s ON s.trace_id = e.trace_id AND s.span_id = e.span_id
+	WHERE e.trace_id IS NOT NULL`
+)
+
+// exemplarView describes the exemplar table of one metric type
+type exemplarView struct {
+	metricType    string
+	exemplarTable string
+	metricTable   string
+	idColumn      string
+	valueColumn   string
+}
+
+var exemplarViews = []exemplarView{
+	{"gauge", GaugeDatapointExemplarTable, GaugeTable, "gauge_id", "gauge_value"},
+	{"sum", SumDatapointExemplarTable, SumTable, "sum_id", "sum_value"},
+	{"histogram", HistogramDatapointExemplarTable, HistogramTable, "histogram_id", "histogram_value"},
+	{"exponential_histogram", ExpHistogramDatapointExemplarTable, ExpHistogramTable, "histogram_id", "histogram_value"},
+}
+
+// exemplarTraceSpanViewSQL - the statement creating the view that joins the exemplars of every metric type to trace_span
+//
+//	@param schema
+//	@param traceSchema
+//	@param tableName
+//	@return string
+func exemplarTraceSpanViewSQL(schema string, traceSchema string, tableName func(string) string) string {
+	selects := make([]string, 0, len(exemplarViews))
+	for _, view := range exemplarViews {
+		selects = append(selects, fmt.Sprintf(selectExemplarTraceSpan,
+			view.metricType,
+			view.valueColumn,
+			qualifiedName(schema, tableName(view.exemplarTable)),
+			qualifiedName(schema, tableName(view.metricTable)), view.idColumn, view.idColumn,
+			qualifiedName(traceSchema, tableName(TraceSpanTable))))
+	}
+	return fmt.Sprintf(CreateExemplarTraceSpanView, qualifiedName(schema, tableName(ExemplarTraceSpanView)), strings.Join(selects, "\nUNION ALL\n"))
+}
+
+// createExemplarTraceSpanView
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@return error
+func (kiwriter *KiWriter) createExemplarTraceSpanView(ctx context.Context) error {
+	_, err := kiwriter.Db.ExecuteSqlRaw(ctx, exemplarTraceSpanViewSQL(kiwriter.cfg.Schema, kiwriter.cfg.signalSchema(SignalTraces), kiwriter.tableName), 0, 0, "", nil)
+	return err
+}
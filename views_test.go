@@ -0,0 +1,82 @@
+package kineticaotelexporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExemplarTraceSpanViewSQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      string
+		traceSchema string
+		tableName   func(string) string
+		want        []string
+	}{
+		{"default schema", "otel", "otel", func(table string) string { return table }, []string{
+			`CREATE OR REPLACE VIEW "otel"."metric_exemplar_trace_span" AS`,
+			`FROM "otel"."metric_gauge_datapoint_exemplar" e`,
+			`INNER JOIN "otel"."metric_gauge" m ON m.gauge_id = e.gauge_id`,
+			`LEFT JOIN "otel"."trace_span" s ON s.trace_id = e.trace_id AND s.span_id = e.span_id`,
+		}},
+		{"traces in their own schema", "metrics", "traces", func(table string) string { return table }, []string{
+			`CREATE OR REPLACE VIEW "metrics"."metric_exemplar_trace_span" AS`,
+			`FROM "metrics"."metric_sum_datapoint_exemplar" e`,
+			`LEFT JOIN "traces"."trace_span" s`,
+		}},
+		{"without a schema", "", "", func(table string) string { return table }, []string{
+			`CREATE OR REPLACE VIEW "metric_exemplar_trace_span" AS`,
+			`FROM "metric_histogram_datapoint_exemplar" e`,
+			`LEFT JOIN "trace_span" s`,
+		}},
+		{"physical table names", "otel", "otel", func(table string) string { return "v2_" + table }, []string{
+			`CREATE OR REPLACE VIEW "otel"."v2_metric_exemplar_trace_span" AS`,
+			`FROM "otel"."v2_metric_exp_histogram_datapoint_exemplar" e`,
+			`LEFT JOIN "otel"."v2_trace_span" s`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exemplarTraceSpanViewSQL(tt.schema, tt.traceSchema, tt.tableName)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("the view statement lacks %q:\n%s", want, got)
+				}
+			}
+			if selects := strings.Count(got, "UNION ALL") + 1; selects != len(exemplarViews) {
+				t.Errorf("the view selects %d metric types, want %d", selects, len(exemplarViews))
+			}
+		})
+	}
+}
+
+// TestExemplarViewsReferenceExistingColumns checks the columns the view selects and
+// joins on against the records of the tables
+func TestExemplarViewsReferenceExistingColumns(t *testing.T) {
+	columns := func(table string) map[string]bool {
+		names := make(map[string]bool)
+		for _, column := range tableColumns(tableRecords[table]) {
+			names[column.name] = true
+		}
+		return names
+	}
+	spanColumns := columns(TraceSpanTable)
+	for _, column := range []string{"trace_id", "span_id", "name", "span_kind", "start_time_unix_nano", "end_time_unix_nano", "status_code"} {
+		if !spanColumns[column] {
+			t.Errorf("%s has no column %s", TraceSpanTable, column)
+		}
+	}
+	for _, view := range exemplarViews {
+		exemplarColumns, metricColumns := columns(view.exemplarTable), columns(view.metricTable)
+		for _, column := range []string{view.idColumn, view.valueColumn, "datapoint_id", "exemplar_id", "time_unix", "trace_id", "span_id"} {
+			if !exemplarColumns[column] {
+				t.Errorf("%s has no column %s", view.exemplarTable, column)
+			}
+		}
+		for _, column := range []string{view.idColumn, "metric_name"} {
+			if !metricColumns[column] {
+				t.Errorf("%s has no column %s", view.metricTable, column)
+			}
+		}
+	}
+}
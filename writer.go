@@ -6,9 +6,8 @@ import (
 	"sync"
 
 	"github.com/am-kinetica/gpudb-api-go/gpudb"
-	"github.com/google/uuid"
+	"github.com/hamba/avro"
 	orderedmap "github.com/wk8/go-ordered-map"
-	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
@@ -134,15 +133,17 @@ func (attributevalue *AttributeValue) SetBytesValue(BytesValue []byte) *Attribut
 
 // Log
 type Log struct {
-	LogID                string `mapstructure:"log_id" avro:"log_id"`
-	TraceID              string `mapstructure:"trace_id" avro:"trace_id"`
-	SpanID               string `mapstructure:"span_id" avro:"span_id"`
-	TimeUnixNano         int64  `mapstructure:"time_unix_nano" avro:"time_unix_nano"`
-	ObservedTimeUnixNano int64  `mapstructure:"observed_time_unix_nano" avro:"observed_time_unix_nano"`
-	SeverityID           int8   `mapstructure:"severity_id" avro:"severity_id"`
-	SeverityText         string `mapstructure:"severity_text" avro:"severity_text"`
-	Body                 string `mapstructure:"body" avro:"body"`
-	Flags                int    `mapstructure:"flags" avro:"flags"`
+	LogID                  string  `mapstructure:"log_id" avro:"log_id"`
+	TraceID                *string `mapstructure:"trace_id" avro:"trace_id"`
+	SpanID                 *string `mapstructure:"span_id" avro:"span_id"`
+	TimeUnixNano           int64   `mapstructure:"time_unix_nano" avro:"time_unix_nano"`
+	ObservedTimeUnixNano   int64   `mapstructure:"observed_time_unix_nano" avro:"observed_time_unix_nano"`
+	SeverityID             int8    `mapstructure:"severity_id" avro:"severity_id"`
+	SeverityText           string  `mapstructure:"severity_text" avro:"severity_text"`
+	SeverityLevel          *string `mapstructure:"severity_level" avro:"severity_level"`
+	Body                   string  `mapstructure:"body" avro:"body"`
+	Flags                  int     `mapstructure:"flags" avro:"flags"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 // NewLog Constructor for Logs
@@ -156,10 +157,12 @@ type Log struct {
 //	@param ObservedTimeUnixNano
 //	@param SeverityID
 //	@param SeverityText
+//	@param SeverityLevel
 //	@param Body
 //	@param Flags
+//	@param DroppedAttributesCount
 //	@return *Logs
-func NewLog(LogID string, TraceID string, SpanID string, TimeUnixNano int64, ObservedTimeUnixNano int64, SeverityID int8, SeverityText string, Body string, Flags int) *Log {
+func NewLog(LogID string, TraceID *string, SpanID *string, TimeUnixNano int64, ObservedTimeUnixNano int64, SeverityID int8, SeverityText string, SeverityLevel *string, Body string, Flags int, DroppedAttributesCount int) *Log {
 	o := new(Log)
 
 	o.LogID = LogID
@@ -169,8 +172,10 @@ func NewLog(LogID string, TraceID string, SpanID string, TimeUnixNano int64, Obs
 	o.ObservedTimeUnixNano = ObservedTimeUnixNano
 	o.SeverityID = SeverityID
 	o.SeverityText = SeverityText
+	o.SeverityLevel = SeverityLevel
 	o.Body = Body
 	o.Flags = Flags
+	o.DroppedAttributesCount = DroppedAttributesCount
 
 	return o
 }
@@ -186,16 +191,16 @@ func (logs *Log) GetLogID() string {
 // GetTraceID
 //
 //	@receiver logs
-//	@return string
-func (logs *Log) GetTraceID() string {
+//	@return *string
+func (logs *Log) GetTraceID() *string {
 	return logs.TraceID
 }
 
 // GetSpanID
 //
 //	@receiver logs
-//	@return string
-func (logs *Log) GetSpanID() string {
+//	@return *string
+func (logs *Log) GetSpanID() *string {
 	return logs.SpanID
 }
 
@@ -231,6 +236,14 @@ func (logs *Log) GetSeverityText() string {
 	return logs.SeverityText
 }
 
+// GetSeverityLevel
+//
+//	@receiver logs
+//	@return *string
+func (logs *Log) GetSeverityLevel() *string {
+	return logs.SeverityLevel
+}
+
 // GetBody
 //
 //	@receiver logs
@@ -247,6 +260,14 @@ func (logs *Log) GetFlags() int {
 	return logs.Flags
 }
 
+// GetDroppedAttributesCount
+//
+//	@receiver logs
+//	@return int
+func (logs *Log) GetDroppedAttributesCount() int {
+	return logs.DroppedAttributesCount
+}
+
 // SetLogID
 //
 //	@receiver logs
@@ -262,7 +283,7 @@ func (logs *Log) SetLogID(LogID string) *Log {
 //	@receiver logs
 //	@param TraceID
 //	@return *Logs
-func (logs *Log) SetTraceID(TraceID string) *Log {
+func (logs *Log) SetTraceID(TraceID *string) *Log {
 	logs.TraceID = TraceID
 	return logs
 }
@@ -272,7 +293,7 @@ func (logs *Log) SetTraceID(TraceID string) *Log {
 //	@receiver logs
 //	@param SpanID
 //	@return *Logs
-func (logs *Log) SetSpanID(SpanID string) *Log {
+func (logs *Log) SetSpanID(SpanID *string) *Log {
 	logs.SpanID = SpanID
 	return logs
 }
@@ -317,6 +338,16 @@ func (logs *Log) SetSeverityText(SeverityText string) *Log {
 	return logs
 }
 
+// SetSeverityLevel
+//
+//	@receiver logs
+//	@param SeverityLevel
+//	@return *Logs
+func (logs *Log) SetSeverityLevel(SeverityLevel *string) *Log {
+	logs.SeverityLevel = SeverityLevel
+	return logs
+}
+
 // SetBody
 //
 //	@receiver logs
@@ -337,6 +368,16 @@ func (logs *Log) SetFlags(Flags int) *Log {
 	return logs
 }
 
+// SetDroppedAttributesCount
+//
+//	@receiver logs
+//	@param DroppedAttributesCount
+//	@return *Logs
+func (logs *Log) SetDroppedAttributesCount(DroppedAttributesCount int) *Log {
+	logs.DroppedAttributesCount = DroppedAttributesCount
+	return logs
+}
+
 // LogAttribute
 type LogAttribute struct {
 	LogID          string `avro:"log_id"`
@@ -361,21 +402,27 @@ func NewLogAttribute(logID string, key string, attributes AttributeValue) *LogAt
 
 // ResourceAttribute
 type ResourceAttribute struct {
-	LogID          string `avro:"log_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	LogID                  string `avro:"log_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // NewResourceAttribute Constructor for LogsResourceAttribute
 //
 //	@param resourceID
 //	@param key
+//	@param schemaURL
+//	@param droppedAttributesCount
 //	@param attributes
 //	@return *LogsResourceAttribute
-func NewResourceAttribute(logID string, key string, attributes AttributeValue) *ResourceAttribute {
+func NewResourceAttribute(logID string, key string, schemaURL string, droppedAttributesCount int, attributes AttributeValue) *ResourceAttribute {
 	o := new(ResourceAttribute)
 	o.LogID = logID
 	o.Key = key
+	o.SchemaURL = schemaURL
+	o.DroppedAttributesCount = droppedAttributesCount
 	o.AttributeValue = attributes
 	return o
 }
@@ -384,11 +431,13 @@ func NewResourceAttribute(logID string, key string, attributes AttributeValue) *
 
 // ScopeAttribute
 type ScopeAttribute struct {
-	LogID          string `avro:"log_id"`
-	ScopeName      string `avro:"scope_name"`
-	ScopeVersion   string `avro:"scope_version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	LogID                  string `avro:"log_id"`
+	ScopeName              string `avro:"scope_name"`
+	ScopeVersion           string `avro:"scope_version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // NewScopeAttribute Constructor for LogsScopeAttribute
@@ -397,14 +446,18 @@ type ScopeAttribute struct {
 //	@param key
 //	@param scopeName
 //	@param scopeVersion
+//	@param schemaURL
+//	@param droppedAttributesCount
 //	@param attributes
 //	@return *LogsScopeAttribute
-func NewScopeAttribute(logID string, key string, scopeName string, scopeVersion string, attributes AttributeValue) *ScopeAttribute {
+func NewScopeAttribute(logID string, key string, scopeName string, scopeVersion string, schemaURL string, droppedAttributesCount int, attributes AttributeValue) *ScopeAttribute {
 	o := new(ScopeAttribute)
-	o.LogID = uuid.New().String()
+	o.LogID = logID
 	o.Key = key
 	o.ScopeName = scopeName
 	o.ScopeVersion = scopeVersion
+	o.SchemaURL = schemaURL
+	o.DroppedAttributesCount = droppedAttributesCount
 	o.AttributeValue = attributes
 	return o
 }
@@ -413,17 +466,28 @@ func NewScopeAttribute(logID string, key string, scopeName string, scopeVersion
 
 // KiWriter
 type KiWriter struct {
-	Db      gpudb.Gpudb
+	Db      *gpudb.Gpudb
 	Options gpudb.GpudbOptions
 	cfg     Config
 	logger  *zap.Logger
+
+	telemetry     *exporterTelemetry
+	recordSchemas *sync.Map
+	spill         *spillBuffer
+	deadLetter    *deadLetterQueue
+	retention     *retentionJob
+	routes        *routeCache
+	destination   string
+	tablePrefix   string
+	rest          *restClient
+	bulk          *bulkLoader
 }
 
 // GetDb
 //
 //	@receiver kiwriter
-//	@return gpudb.Gpudb
-func (kiwriter *KiWriter) GetDb() gpudb.Gpudb {
+//	@return *gpudb.Gpudb
+func (kiwriter *KiWriter) GetDb() *gpudb.Gpudb {
 	return kiwriter.Db
 }
 
@@ -448,7 +512,7 @@ func (kiwriter *KiWriter) GetCfg() Config {
 //	@receiver kiwriter
 //	@param Db
 //	@return *kiwriter
-func (kiwriter *KiWriter) SetDb(Db gpudb.Gpudb) *KiWriter {
+func (kiwriter *KiWriter) SetDb(Db *gpudb.Gpudb) *KiWriter {
 	kiwriter.Db = Db
 	return kiwriter
 }
@@ -483,18 +547,70 @@ func init() {
 	config := cfg.(*Config)
 	options := gpudb.GpudbOptions{Username: config.Username, Password: config.Password, ByPassSslCertCheck: config.BypassSslCertCheck}
 	gpudbInst := gpudb.NewWithOptions(ctx, config.Host, &options)
-	Writer = &KiWriter{*gpudbInst, options, *config, nil}
+	Writer = &KiWriter{gpudbInst, options, *config, zap.NewNop(), nil, &sync.Map{}, nil, nil, nil, nil, "", "", newRestClient(*config), nil}
 }
 
 // NewKiWriter
 //
 //	@param ctx
 //	@param cfg
+//	@param logger
+//	@param telemetry
 //	@return *KiWriter
-func NewKiWriter(ctx context.Context, cfg Config, logger *zap.Logger) *KiWriter {
+func NewKiWriter(ctx context.Context, cfg Config, logger *zap.Logger, telemetry *exporterTelemetry) *KiWriter {
 	options := gpudb.GpudbOptions{Username: cfg.Username, Password: cfg.Password, ByPassSslCertCheck: cfg.BypassSslCertCheck}
 	gpudbInst := gpudb.NewWithOptions(ctx, cfg.Host, &options)
-	return &KiWriter{*gpudbInst, options, cfg, logger}
+	return &KiWriter{gpudbInst, options, cfg, logger, telemetry, &sync.Map{}, nil, nil, nil, nil, "", "", newRestClient(cfg), nil}
+}
+
+// start - starts the dead letter queue, the replay of spilled batches and the retention job
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@return error
+func (kiwriter *KiWriter) start(ctx context.Context) error {
+	if kiwriter.deadLetter != nil {
+		if err := kiwriter.deadLetter.start(ctx); err != nil {
+			return err
+		}
+	}
+	if kiwriter.spill != nil {
+		if err := kiwriter.spill.start(ctx); err != nil {
+			return err
+		}
+	}
+	if kiwriter.retention != nil {
+		if err := kiwriter.retention.start(ctx); err != nil {
+			return err
+		}
+	}
+	if kiwriter.bulk != nil {
+		return kiwriter.bulk.start(ctx)
+	}
+	return nil
+}
+
+// shutdown - loads the open bulk files, stops the retention job, the replay of spilled
+// batches and the dead letter queue
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@return error
+func (kiwriter *KiWriter) shutdown(ctx context.Context) error {
+	var errs error
+	if kiwriter.bulk != nil {
+		errs = multierr.Append(errs, kiwriter.bulk.shutdown(ctx))
+	}
+	if kiwriter.retention != nil {
+		errs = multierr.Append(errs, kiwriter.retention.shutdown(ctx))
+	}
+	if kiwriter.spill != nil {
+		errs = multierr.Append(errs, kiwriter.spill.shutdown(ctx))
+	}
+	if kiwriter.deadLetter != nil {
+		errs = multierr.Append(errs, kiwriter.deadLetter.shutdown(ctx))
+	}
+	return errs
 }
 
 // GetGpuDbInst
@@ -517,20 +633,20 @@ func GetGpuDbInst(cfg *Config) *gpudb.Gpudb {
 
 // Span
 type Span struct {
-	ID                     string `mapstructure:"id" avro:"id" `
-	TraceID                string `mapstructure:"trace_id" avro:"trace_id"`
-	SpanID                 string `mapstructure:"span_id" avro:"span_id"`
-	ParentSpanID           string `mapstructure:"parent_span_id" avro:"parent_span_id"`
-	TraceState             string `mapstructure:"trace_state" avro:"trace_state"`
-	Name                   string `mapstructure:"name" avro:"name"`
-	SpanKind               int8   `mapstructure:"span_kind" avro:"span_kind"`
-	StartTimeUnixNano      int64  `mapstructure:"start_time_unix_nano" avro:"start_time_unix_nano"`
-	EndTimeUnixNano        int64  `mapstructure:"end_time_unix_nano" avro:"end_time_unix_nano"`
-	DroppedAttributesCount int    `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
-	DroppedEventsCount     int    `mapstructure:"dropped_events_count" avro:"dropped_events_count"`
-	DroppedLinksCount      int    `mapstructure:"dropped_links_count" avro:"dropped_links_count"`
-	Message                string `mapstructure:"message" avro:"message"`
-	StatusCode             int8   `mapstructure:"status_code" avro:"status_code"`
+	ID                     string  `mapstructure:"id" avro:"id" `
+	TraceID                string  `mapstructure:"trace_id" avro:"trace_id"`
+	SpanID                 string  `mapstructure:"span_id" avro:"span_id"`
+	ParentSpanID           *string `mapstructure:"parent_span_id" avro:"parent_span_id"`
+	TraceState             string  `mapstructure:"trace_state" avro:"trace_state"`
+	Name                   string  `mapstructure:"name" avro:"name"`
+	SpanKind               int8    `mapstructure:"span_kind" avro:"span_kind"`
+	StartTimeUnixNano      int64   `mapstructure:"start_time_unix_nano" avro:"start_time_unix_nano"`
+	EndTimeUnixNano        int64   `mapstructure:"end_time_unix_nano" avro:"end_time_unix_nano"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
+	DroppedEventsCount     int     `mapstructure:"dropped_events_count" avro:"dropped_events_count"`
+	DroppedLinksCount      int     `mapstructure:"dropped_links_count" avro:"dropped_links_count"`
+	Message                string  `mapstructure:"message" avro:"message"`
+	StatusCode             int8    `mapstructure:"status_code" avro:"status_code"`
 }
 
 // NewSpan Constructor for Span
@@ -553,9 +669,9 @@ type Span struct {
 //	@param message
 //	@param statusCode
 //	@return *Span
-func NewSpan(traceID string, spanID string, parentSpanID string, traceState string, name string, spanKind int8, startTimeUnixNano int64, endTimeUnixNano int64, droppedAttributeCount int, droppedEventCount int, droppedLinkCount int, message string, statusCode int8) *Span {
+func NewSpan(traceID string, spanID string, parentSpanID *string, traceState string, name string, spanKind int8, startTimeUnixNano int64, endTimeUnixNano int64, droppedAttributeCount int, droppedEventCount int, droppedLinkCount int, message string, statusCode int8) *Span {
 	o := new(Span)
-	o.ID = uuid.New().String()
+	o.ID = spanRecordID(traceID, spanID)
 	o.TraceID = traceID
 	o.SpanID = spanID
 	o.ParentSpanID = parentSpanID
@@ -599,8 +715,8 @@ func (span *Span) GetSpanID() string {
 // GetParentSpanID
 //
 //	@receiver span
-//	@return string
-func (span *Span) GetParentSpanID() string {
+//	@return *string
+func (span *Span) GetParentSpanID() *string {
 	return span.ParentSpanID
 }
 
@@ -719,7 +835,7 @@ func (span *Span) SetSpanID(spanID string) *Span {
 //	@receiver span
 //	@param parentSpanID
 //	@return *Span
-func (span *Span) SetParentSpanID(parentSpanID string) *Span {
+func (span *Span) SetParentSpanID(parentSpanID *string) *Span {
 	span.ParentSpanID = parentSpanID
 	return span
 }
@@ -827,6 +943,7 @@ func (span *Span) SetStatusCode(statusCode int8) *Span {
 // SpanAttribute
 type SpanAttribute struct {
 	SpanID         string `avro:"span_id"`
+	SpanRecordID   string `avro:"span_record_id"`
 	Key            string `avro:"key"`
 	AttributeValue `mapstructure:",squash"`
 }
@@ -834,53 +951,45 @@ type SpanAttribute struct {
 // NewSpanAttribute Constructor for SpanAttribute
 //
 //	@param spanID
+//	@param spanRecordID
 //	@param key
 //	@param atributeValue
 //	@return *SpanAttribute
-func NewSpanAttribute(spanID string, key string, attributeValue AttributeValue) *SpanAttribute {
+func NewSpanAttribute(spanID string, spanRecordID string, key string, attributeValue AttributeValue) *SpanAttribute {
 	o := new(SpanAttribute)
 	o.SpanID = spanID
+	o.SpanRecordID = spanRecordID
 	o.Key = key
 	o.AttributeValue = attributeValue
 	return o
 }
 
-// insertSpanAttribute //
-//
-//	@receiver spanAttribute
-//	@return uuid.UUID
-//	@return error
-func (spanAttribute *SpanAttribute) insertSpanAttribute() (string, error) {
-	if spanAttribute.SpanID == "" {
-		spanAttribute.SpanID = uuid.New().String()
-	}
-
-	statement := fmt.Sprintf(InsertTraceSpanAttribute, Writer.cfg.Schema, spanAttribute.SpanID, spanAttribute.Key, spanAttribute.GetStringValue(), spanAttribute.BoolValue, spanAttribute.GetIntValue(), spanAttribute.GetDoubleValue(), spanAttribute.GetBytesValue())
-	gpudb := Writer.Db
-	_, err := gpudb.ExecuteSqlRaw(context.TODO(), statement, 0, 0, "", nil)
-	if err != nil {
-		return "", err
-	}
-	return spanAttribute.SpanID, nil
-}
-
 // TraceResourceAttribute
 type TraceResourceAttribute struct {
-	SpanID         string `avro:"span_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:"",squash`
+	SpanID                 string `avro:"span_id"`
+	SpanRecordID           string `avro:"span_record_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // NewTraceResourceAttribute Constructor for TraceResourceAttribute
 //
 //	@param SpanID
+//	@param spanRecordID
 //	@param key
+//	@param schemaURL
+//	@param droppedAttributesCount
 //	@param attributes
 //	@return *TraceResourceAttribute
-func NewTraceResourceAttribute(SpanID string, key string, attributes AttributeValue) *TraceResourceAttribute {
+func NewTraceResourceAttribute(SpanID string, spanRecordID string, key string, schemaURL string, droppedAttributesCount int, attributes AttributeValue) *TraceResourceAttribute {
 	o := new(TraceResourceAttribute)
 	o.SpanID = SpanID
+	o.SpanRecordID = spanRecordID
 	o.Key = key
+	o.SchemaURL = schemaURL
+	o.DroppedAttributesCount = droppedAttributesCount
 	o.AttributeValue = attributes
 	return o
 }
@@ -889,26 +998,36 @@ func NewTraceResourceAttribute(SpanID string, key string, attributes AttributeVa
 
 // TraceScopeAttribute
 type TraceScopeAttribute struct {
-	SpanID         string `avro:"span_id"`
-	ScopeName      string `avro:"scope_name"`
-	ScopeVersion   string `avro:"scope_version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	SpanID                 string `avro:"span_id"`
+	SpanRecordID           string `avro:"span_record_id"`
+	ScopeName              string `avro:"scope_name"`
+	ScopeVersion           string `avro:"scope_version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // NewtraceScopeAttribute Constructor for TraceScopeAttribute
 //
+//	@param SpanID
+//	@param spanRecordID
 //	@param key
 //	@param scopeName
 //	@param scopeVersion
+//	@param schemaURL
+//	@param droppedAttributesCount
 //	@param attributes
 //	@return *TraceScopeAttribute
-func NewtraceScopeAttribute(SpanID string, key string, scopeName string, scopeVersion string, attributes AttributeValue) *TraceScopeAttribute {
+func NewtraceScopeAttribute(SpanID string, spanRecordID string, key string, scopeName string, scopeVersion string, schemaURL string, droppedAttributesCount int, attributes AttributeValue) *TraceScopeAttribute {
 	o := new(TraceScopeAttribute)
 	o.SpanID = SpanID
+	o.SpanRecordID = spanRecordID
 	o.Key = key
 	o.ScopeName = scopeName
 	o.ScopeVersion = scopeVersion
+	o.SchemaURL = schemaURL
+	o.DroppedAttributesCount = droppedAttributesCount
 	o.AttributeValue = attributes
 	return o
 }
@@ -917,23 +1036,33 @@ func NewtraceScopeAttribute(SpanID string, key string, scopeName string, scopeVe
 
 // EventAttribute
 type EventAttribute struct {
-	SpanID         string `avro:"span_id"`
-	EventName      string `avro:"event_name"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	SpanID                 string `avro:"span_id"`
+	SpanRecordID           string `avro:"span_record_id"`
+	EventIndex             int    `avro:"event_index"`
+	EventName              string `avro:"event_name"`
+	Key                    string `avro:"key"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // NewEventAttribute Constructor for TraceEventAttribute
 //
-//	@param key
+//	@param spanID
+//	@param spanRecordID
+//	@param eventIndex
 //	@param eventName
+//	@param key
+//	@param droppedAttributesCount
 //	@param attributes
 //	@return *TraceEventAttribute
-func NewEventAttribute(spanID string, eventName string, key string, attributes AttributeValue) *EventAttribute {
+func NewEventAttribute(spanID string, spanRecordID string, eventIndex int, eventName string, key string, droppedAttributesCount int, attributes AttributeValue) *EventAttribute {
 	o := new(EventAttribute)
 	o.SpanID = spanID
+	o.SpanRecordID = spanRecordID
+	o.EventIndex = eventIndex
 	o.Key = key
 	o.EventName = eventName
+	o.DroppedAttributesCount = droppedAttributesCount
 	o.AttributeValue = attributes
 	return o
 }
@@ -942,27 +1071,36 @@ func NewEventAttribute(spanID string, eventName string, key string, attributes A
 
 // LinkAttribute
 type LinkAttribute struct {
-	LinkSpanID     string `avro:"link_span_id"`
-	TraceID        string `avro:"trace_id"`
-	SpanID         string `avro:"span_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	LinkSpanID             string `avro:"link_span_id"`
+	SpanRecordID           string `avro:"span_record_id"`
+	LinkIndex              int    `avro:"link_index"`
+	TraceID                string `avro:"trace_id"`
+	SpanID                 string `avro:"span_id"`
+	Key                    string `avro:"key"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // NewLinkAttribute Constructor for LinkAttribute
 //
-//	@param linkID
+//	@param linkSpanID
+//	@param spanRecordID
+//	@param linkIndex
 //	@param key
 //	@param traceID
 //	@param spanID
+//	@param droppedAttributesCount
 //	@param attributes
 //	@return *LinkAttribute
-func NewLinkAttribute(linkSpanID string, key string, traceID string, spanID string, attributes AttributeValue) *LinkAttribute {
+func NewLinkAttribute(linkSpanID string, spanRecordID string, linkIndex int, key string, traceID string, spanID string, droppedAttributesCount int, attributes AttributeValue) *LinkAttribute {
 	o := new(LinkAttribute)
 	o.LinkSpanID = linkSpanID
+	o.SpanRecordID = spanRecordID
+	o.LinkIndex = linkIndex
 	o.Key = key
 	o.TraceID = traceID
 	o.SpanID = spanID
+	o.DroppedAttributesCount = droppedAttributesCount
 	o.AttributeValue = attributes
 	return o
 }
@@ -979,16 +1117,19 @@ type Gauge struct {
 	MetricName  string `avro:"metric_name"`
 	Description string `avro:"metric_description"`
 	Unit        string `avro:"metric_unit"`
+	Job         string `avro:"job"`
+	Instance    string `avro:"instance"`
 }
 
 // GaugeDatapoint
 type GaugeDatapoint struct {
-	GaugeID       string  `avro:"gauge_id"`
-	ID            string  `avro:"id"`
-	StartTimeUnix int64   `mapstructure:"start_time_unix" avro:"start_time_unix"`
-	TimeUnix      int64   `mapstructure:"time_unix" avro:"time_unix"`
-	GaugeValue    float64 `mapstructure:"gauge_value" avro:"gauge_value"`
-	Flags         int     `mapstructure:"flags" avro:"flags"`
+	GaugeID                string  `avro:"gauge_id"`
+	ID                     string  `avro:"id"`
+	StartTimeUnix          int64   `mapstructure:"start_time_unix" avro:"start_time_unix"`
+	TimeUnix               int64   `mapstructure:"time_unix" avro:"time_unix"`
+	GaugeValue             float64 `mapstructure:"gauge_value" avro:"gauge_value"`
+	Flags                  int     `mapstructure:"flags" avro:"flags"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 // GaugeDatapointAttribute
@@ -1001,13 +1142,14 @@ type GaugeDatapointAttribute struct {
 
 // GaugeDatapointExemplar
 type GaugeDatapointExemplar struct {
-	GaugeID     string  `avro:"gauge_id"`
-	DatapointID string  `avro:"datapoint_id"`
-	ExemplarID  string  `avro:"exemplar_id"`
-	TimeUnix    int64   `mapstructure:"time_unix" avro:"time_unix"`
-	GaugeValue  float64 `mapstructure:"gauge_value" avro:"gauge_value"`
-	TraceID     string  `mapstructure:"trace_id" avro:"trace_id"`
-	SpanID      string  `mapstructure:"span_id" avro:"span_id"`
+	GaugeID                string  `avro:"gauge_id"`
+	DatapointID            string  `avro:"datapoint_id"`
+	ExemplarID             string  `avro:"exemplar_id"`
+	TimeUnix               int64   `mapstructure:"time_unix" avro:"time_unix"`
+	GaugeValue             float64 `mapstructure:"gauge_value" avro:"gauge_value"`
+	TraceID                *string `mapstructure:"trace_id" avro:"trace_id"`
+	SpanID                 *string `mapstructure:"span_id" avro:"span_id"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 // GaugeDataPointExemplarAttribute
@@ -1021,18 +1163,22 @@ type GaugeDataPointExemplarAttribute struct {
 
 // GaugeResourceAttribute
 type GaugeResourceAttribute struct {
-	GaugeID        string `avro:"gauge_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	GaugeID                string `avro:"gauge_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // GaugeScopeAttribute
 type GaugeScopeAttribute struct {
-	GaugeID        string `avro:"gauge_id"`
-	ScopeName      string `avro:"name"`
-	ScopeVersion   string `avro:"version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	GaugeID                string `avro:"gauge_id"`
+	ScopeName              string `avro:"name"`
+	ScopeVersion           string `avro:"version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // END Gauge
@@ -1047,16 +1193,19 @@ type Sum struct {
 	Unit                   string `avro:"metric_unit"`
 	AggregationTemporality int8   `avro:"aggregation_temporality"`
 	IsMonotonic            int8   `avro:"is_monotonic"`
+	Job                    string `avro:"job"`
+	Instance               string `avro:"instance"`
 }
 
 // SumDatapoint
 type SumDatapoint struct {
-	SumID         string  `avro:"sum_id"`
-	ID            string  `avro:"id"`
-	StartTimeUnix int64   `mapstructure:"start_time_unix" avro:"start_time_unix"`
-	TimeUnix      int64   `mapstructure:"time_unix" avro:"time_unix"`
-	SumValue      float64 `mapstructure:"sum_value" avro:"sum_value"`
-	Flags         int     `mapstructure:"flags" avro:"flags"`
+	SumID                  string  `avro:"sum_id"`
+	ID                     string  `avro:"id"`
+	StartTimeUnix          int64   `mapstructure:"start_time_unix" avro:"start_time_unix"`
+	TimeUnix               int64   `mapstructure:"time_unix" avro:"time_unix"`
+	SumValue               float64 `mapstructure:"sum_value" avro:"sum_value"`
+	Flags                  int     `mapstructure:"flags" avro:"flags"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 // SumDataPointAttribute
@@ -1069,13 +1218,14 @@ type SumDataPointAttribute struct {
 
 // SumDatapointExemplar
 type SumDatapointExemplar struct {
-	SumID       string  `avro:"sum_id"`
-	DatapointID string  `avro:"datapoint_id"`
-	ExemplarID  string  `avro:"exemplar_id"`
-	TimeUnix    int64   `mapstructure:"time_unix" avro:"time_unix"`
-	SumValue    float64 `mapstructure:"sum_value" avro:"sum_value"`
-	TraceID     string  `mapstructure:"trace_id" avro:"trace_id"`
-	SpanID      string  `mapstructure:"span_id" avro:"span_id"`
+	SumID                  string  `avro:"sum_id"`
+	DatapointID            string  `avro:"datapoint_id"`
+	ExemplarID             string  `avro:"exemplar_id"`
+	TimeUnix               int64   `mapstructure:"time_unix" avro:"time_unix"`
+	SumValue               float64 `mapstructure:"sum_value" avro:"sum_value"`
+	TraceID                *string `mapstructure:"trace_id" avro:"trace_id"`
+	SpanID                 *string `mapstructure:"span_id" avro:"span_id"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 type SumDataPointExemplarAttribute struct {
@@ -1088,18 +1238,22 @@ type SumDataPointExemplarAttribute struct {
 
 // SumResourceAttribute
 type SumResourceAttribute struct {
-	SumID          string `avro:"sum_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	SumID                  string `avro:"sum_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // SumScopeAttribute
 type SumScopeAttribute struct {
-	SumID          string `avro:"sum_id"`
-	ScopeName      string `avro:"name"`
-	ScopeVersion   string `avro:"version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	SumID                  string `avro:"sum_id"`
+	ScopeName              string `avro:"name"`
+	ScopeVersion           string `avro:"version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // END Sum
@@ -1113,19 +1267,22 @@ type Histogram struct {
 	Description            string `avro:"metric_description"`
 	Unit                   string `avro:"metric_unit"`
 	AggregationTemporality int8   `avro:"aggregation_temporality"`
+	Job                    string `avro:"job"`
+	Instance               string `avro:"instance"`
 }
 
 // HistogramDatapoint
 type HistogramDatapoint struct {
-	HistogramID   string  `avro:"histogram_id"`
-	ID            string  `avro:"id"`
-	StartTimeUnix int64   `avro:"start_time_unix"`
-	TimeUnix      int64   `avro:"time_unix"`
-	Count         int64   `avro:"count"`
-	Sum           float64 `avro:"data_sum"`
-	Min           float64 `avro:"data_min"`
-	Max           float64 `avro:"data_max"`
-	Flags         int     `avro:"flags"`
+	HistogramID            string  `avro:"histogram_id"`
+	ID                     string  `avro:"id"`
+	StartTimeUnix          int64   `avro:"start_time_unix"`
+	TimeUnix               int64   `avro:"time_unix"`
+	Count                  int64   `avro:"count"`
+	Sum                    float64 `avro:"data_sum"`
+	Min                    float64 `avro:"data_min"`
+	Max                    float64 `avro:"data_max"`
+	Flags                  int     `avro:"flags"`
+	DroppedAttributesCount int     `avro:"dropped_attributes_count"`
 }
 
 // HistogramDataPointAttribute
@@ -1152,13 +1309,14 @@ type HistogramDatapointExplicitBound struct {
 
 // HistogramDatapointExemplar
 type HistogramDatapointExemplar struct {
-	HistogramID    string  `avro:"histogram_id"`
-	DatapointID    string  `avro:"datapoint_id"`
-	ExemplarID     string  `avro:"exemplar_id"`
-	TimeUnix       int64   `avro:"time_unix"`
-	HistogramValue float64 `avro:"histogram_value"`
-	TraceID        string  `mapstructure:"trace_id" avro:"trace_id"`
-	SpanID         string  `mapstructure:"span_id" avro:"span_id"`
+	HistogramID            string  `avro:"histogram_id"`
+	DatapointID            string  `avro:"datapoint_id"`
+	ExemplarID             string  `avro:"exemplar_id"`
+	TimeUnix               int64   `avro:"time_unix"`
+	HistogramValue         float64 `avro:"histogram_value"`
+	TraceID                *string `mapstructure:"trace_id" avro:"trace_id"`
+	SpanID                 *string `mapstructure:"span_id" avro:"span_id"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 // HistogramDataPointExemplarAttribute
@@ -1172,18 +1330,22 @@ type HistogramDataPointExemplarAttribute struct {
 
 // HistogramResourceAttribute
 type HistogramResourceAttribute struct {
-	HistogramID    string `avro:"histogram_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	HistogramID            string `avro:"histogram_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // HistogramScopeAttribute
 type HistogramScopeAttribute struct {
-	HistogramID    string `avro:"histogram_id"`
-	ScopeName      string `avro:"name"`
-	ScopeVersion   string `avro:"version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	HistogramID            string `avro:"histogram_id"`
+	ScopeName              string `avro:"name"`
+	ScopeVersion           string `avro:"version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // End Histogram
@@ -1197,22 +1359,25 @@ type ExponentialHistogram struct {
 	Description            string `avro:"metric_description"`
 	Unit                   string `avro:"metric_unit"`
 	AggregationTemporality int8   `avro:"aggregation_temporality"`
+	Job                    string `avro:"job"`
+	Instance               string `avro:"instance"`
 }
 
 type ExponentialHistogramDatapoint struct {
-	HistogramID           string  `avro:"histogram_id"`
-	ID                    string  `avro:"id"`
-	StartTimeUnix         int64   `avro:"start_time_unix"`
-	TimeUnix              int64   `avro:"time_unix"`
-	Count                 int64   `avro:"count"`
-	Sum                   float64 `avro:"data_sum"`
-	Min                   float64 `avro:"data_min"`
-	Max                   float64 `avro:"data_max"`
-	Flags                 int     `avro:"flags"`
-	Scale                 int     `avro:"scale"`
-	ZeroCount             int64   `avro:"zero_count"`
-	BucketsPositiveOffset int     `avro:"buckets_positive_offset"`
-	BucketsNegativeOffset int     `avro:"buckets_negative_offset"`
+	HistogramID            string  `avro:"histogram_id"`
+	ID                     string  `avro:"id"`
+	StartTimeUnix          int64   `avro:"start_time_unix"`
+	TimeUnix               int64   `avro:"time_unix"`
+	Count                  int64   `avro:"count"`
+	Sum                    float64 `avro:"data_sum"`
+	Min                    float64 `avro:"data_min"`
+	Max                    float64 `avro:"data_max"`
+	Flags                  int     `avro:"flags"`
+	Scale                  int     `avro:"scale"`
+	ZeroCount              int64   `avro:"zero_count"`
+	BucketsPositiveOffset  int     `avro:"buckets_positive_offset"`
+	BucketsNegativeOffset  int     `avro:"buckets_negative_offset"`
+	DroppedAttributesCount int     `avro:"dropped_attributes_count"`
 }
 
 type ExponentialHistogramDataPointAttribute struct {
@@ -1226,7 +1391,7 @@ type ExponentialHistogramBucketNegativeCount struct {
 	HistogramID string `avro:"histogram_id"`
 	DatapointID string `avro:"datapoint_id"`
 	CountID     string `avro:"count_id"`
-	Count       uint64 `avro:"count"`
+	Count       int64  `avro:"count"`
 }
 
 type ExponentialHistogramBucketPositiveCount struct {
@@ -1237,13 +1402,14 @@ type ExponentialHistogramBucketPositiveCount struct {
 }
 
 type ExponentialHistogramDatapointExemplar struct {
-	HistogramID    string  `avro:"histogram_id"`
-	DatapointID    string  `avro:"datapoint_id"`
-	ExemplarID     string  `avro:"exemplar_id"`
-	TimeUnix       int64   `avro:"time_unix"`
-	HistogramValue float64 `avro:"histogram_value"`
-	TraceID        string  `mapstructure:"trace_id" avro:"trace_id"`
-	SpanID         string  `mapstructure:"span_id" avro:"span_id"`
+	HistogramID            string  `avro:"histogram_id"`
+	DatapointID            string  `avro:"datapoint_id"`
+	ExemplarID             string  `avro:"exemplar_id"`
+	TimeUnix               int64   `avro:"time_unix"`
+	HistogramValue         float64 `avro:"histogram_value"`
+	TraceID                *string `mapstructure:"trace_id" avro:"trace_id"`
+	SpanID                 *string `mapstructure:"span_id" avro:"span_id"`
+	DroppedAttributesCount int     `mapstructure:"dropped_attributes_count" avro:"dropped_attributes_count"`
 }
 
 // HistogramDataPointExemplarAttribute
@@ -1257,18 +1423,22 @@ type ExponentialHistogramDataPointExemplarAttribute struct {
 
 // HistogramResourceAttribute
 type ExponentialHistogramResourceAttribute struct {
-	HistogramID    string `avro:"histogram_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	HistogramID            string `avro:"histogram_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // HistogramScopeAttribute
 type ExponentialHistogramScopeAttribute struct {
-	HistogramID    string `avro:"histogram_id"`
-	ScopeName      string `avro:"name"`
-	ScopeVersion   string `avro:"version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	HistogramID            string `avro:"histogram_id"`
+	ScopeName              string `avro:"name"`
+	ScopeVersion           string `avro:"version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // END Exponential Histogram
@@ -1280,17 +1450,23 @@ type Summary struct {
 	MetricName  string `avro:"metric_name"`
 	Description string `avro:"metric_description"`
 	Unit        string `avro:"metric_unit"`
+	Job         string `avro:"job"`
+	Instance    string `avro:"instance"`
 }
 
 // SummaryDatapoint
 type SummaryDatapoint struct {
-	SummaryID     string  `avro:"summary_id"`
-	ID            string  `avro:"id"`
-	StartTimeUnix int64   `avro:"start_time_unix"`
-	TimeUnix      int64   `avro:"time_unix"`
-	Count         int64   `avro:"count"`
-	Sum           float64 `avro:"data_sum"`
-	Flags         int     `avro:"flags"`
+	SummaryID              string  `avro:"summary_id"`
+	ID                     string  `avro:"id"`
+	StartTimeUnix          int64   `avro:"start_time_unix"`
+	TimeUnix               int64   `avro:"time_unix"`
+	Count                  int64   `avro:"count"`
+	Sum                    float64 `avro:"data_sum"`
+	Flags                  int     `avro:"flags"`
+	DroppedAttributesCount int     `avro:"dropped_attributes_count"`
+
+	// Quantiles holds the values of the configured quantile columns keyed by column name
+	Quantiles map[string]float64 `avro:"-"`
 }
 
 // SummaryDataPointAttribute
@@ -1311,485 +1487,202 @@ type SummaryDatapointQuantileValues struct {
 
 // SummaryResourceAttribute
 type SummaryResourceAttribute struct {
-	SummaryID      string `avro:"summary_id"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	SummaryID              string `avro:"summary_id"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // SummaryScopeAttribute
 type SummaryScopeAttribute struct {
-	SummaryID      string `avro:"summary_id"`
-	ScopeName      string `avro:"name"`
-	ScopeVersion   string `avro:"version"`
-	Key            string `avro:"key"`
-	AttributeValue `mapstructure:",squash"`
+	SummaryID              string `avro:"summary_id"`
+	ScopeName              string `avro:"name"`
+	ScopeVersion           string `avro:"version"`
+	Key                    string `avro:"key"`
+	SchemaURL              string `avro:"schema_url"`
+	DroppedAttributesCount int    `avro:"dropped_attributes_count"`
+	AttributeValue         `mapstructure:",squash"`
 }
 
 // END Summary
 
 // END Metrics Handling
 
-// persistLogRecord
-//
-//	@receiver kiwriter
-//	@param logRecords
-//	@return error
-func (kiwriter *KiWriter) persistLogRecord(logRecords []kineticaLogRecord) error {
-
-	var errs []error
-	var logs []any
-	var logAttribs []interface{}
-	var resourceAttribs []interface{}
-	var scopeAttribs []interface{}
-
-	for _, logrecord := range logRecords {
-
-		// For each chunk of 10K records persist eveything
-		logs = append(logs, *logrecord.log)
-		logAttribs = append(logAttribs, logrecord.logAttribute)
-		resourceAttribs = append(resourceAttribs, logrecord.resourceAttribute)
-		scopeAttribs = append(scopeAttribs, logrecord.scopeAttribute)
-	}
-
-	err := kiwriter.doChunkedInsert(context.TODO(), LogTable, logs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), LogAttributeTable, logAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), LogResourceAttributeTable, resourceAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), LogScopeAttributeTable, scopeAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	return multierr.Combine(errs...)
-}
-
-// persistTraceRecord
+// writeRows - writes the rows of the tables of a push. The tables are inserted parent
+// first when configured, otherwise concurrently.
 //
 //	@receiver kiwriter
-//	@param traceRecords
+//	@param ctx
+//	@param tables
 //	@return error
-func (kiwriter *KiWriter) persistTraceRecord(traceRecords []kineticaTraceRecord) error {
-	var errs []error
-	var spans []any
-	var spanAttribs []interface{}
-	var spanResourceAttribs []interface{}
-	var spanScopeAttribs []interface{}
-	var spanEventAttribs []interface{}
-	var spanLinkAttribs []interface{}
-
-	for _, tracerecord := range traceRecords {
-
-		spans = append(spans, *tracerecord.span)
-
-		for _, spa := range tracerecord.spanAttribute {
-			spanAttribs = append(spanAttribs, spa)
-		}
-
-		for _, ra := range tracerecord.resourceAttribute {
-			spanResourceAttribs = append(spanResourceAttribs, ra)
-		}
-
-		for _, sa := range tracerecord.scopeAttribute {
-			spanScopeAttribs = append(spanScopeAttribs, sa)
-		}
-
-		for _, ea := range tracerecord.eventAttribute {
-			spanEventAttribs = append(spanEventAttribs, ea)
-		}
-
-		for _, la := range tracerecord.linkAttribute {
-			spanLinkAttribs = append(spanLinkAttribs, la)
+func (kiwriter *KiWriter) writeRows(ctx context.Context, tables []rowBuffer) error {
+	if kiwriter.parentFirst() {
+		tableDataMap := orderedmap.New()
+		for _, rows := range tables {
+			tableDataMap.Set(rows.tableName(), rows.records(0, rows.count()))
 		}
+		return kiwriter.writeOrdered(ctx, tableDataMap)
 	}
 
-	err := kiwriter.doChunkedInsert(context.TODO(), TraceSpanTable, spans)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), TraceSpanAttributeTable, spanAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), TraceResourceAttributeTable, spanResourceAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), TraceScopeAttributeTable, spanScopeAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), TraceEventAttributeTable, spanEventAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	err = kiwriter.doChunkedInsert(context.TODO(), TraceLinkAttributeTable, spanLinkAttribs)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	return multierr.Combine(errs...)
-}
-
-// writeMetric
-//
-//	@receiver kiwriter
-//	@param metricType
-//	@param tableDataMap
-//	@return error
-func (kiwriter *KiWriter) writeMetric(metricType string, tableDataMap *orderedmap.OrderedMap) error {
-
-	kiwriter.logger.Debug("Writing metric", zap.String("Type", metricType))
-
-	var errs []error
-	errsChan := make(chan error, tableDataMap.Len())
-
+	errsChan := make(chan error, len(tables))
 	wg := &sync.WaitGroup{}
-	for pair := tableDataMap.Oldest(); pair != nil; pair = pair.Next() {
-		tableName := pair.Key.(string)
-		data := pair.Value.([]any)
-
+	for _, rows := range tables {
+		if rows.count() == 0 {
+			continue
+		}
 		wg.Add(1)
-
-		go func(tableName string, data []any, wg *sync.WaitGroup) {
-			err := kiwriter.doChunkedInsert(context.TODO(), tableName, data)
-			if err != nil {
+		go func(rows rowBuffer) {
+			defer wg.Done()
+			if err := kiwriter.insertRows(ctx, rows); err != nil {
 				errsChan <- err
 			}
-			wg.Done()
-		}(tableName, data, wg)
-
+		}(rows)
 	}
 	wg.Wait()
-
 	close(errsChan)
 
-	var insErrs error
+	var errs error
 	for err := range errsChan {
-		insErrs = multierr.Append(insErrs, err)
+		errs = multierr.Append(errs, err)
 	}
-	errs = append(errs, insErrs)
-	return multierr.Combine(errs...)
+	return errs
 }
 
-func (kiwriter *KiWriter) persistGaugeRecord(gaugeRecords []kineticaGaugeRecord) error {
-	kiwriter.logger.Debug("In persistGaugeRecord ...")
-
-	var errs []error
-	var gauges []any
-	var resourceAttributes []any
-	var scopeAttributes []any
-	var datapoints []any
-	var datapointAttributes []any
-	var exemplars []any
-	var exemplarAttributes []any
-
-	for _, gaugerecord := range gaugeRecords {
-
-		gauges = append(gauges, *gaugerecord.gauge)
-
-		for _, gr := range gaugerecord.resourceAttribute {
-			resourceAttributes = append(resourceAttributes, gr)
-		}
-
-		for _, sa := range gaugerecord.scopeAttribute {
-			scopeAttributes = append(scopeAttributes, sa)
-		}
-
-		for _, dp := range gaugerecord.datapoint {
-			datapoints = append(datapoints, dp)
-		}
-
-		for _, dpattr := range gaugerecord.datapointAttribute {
-			datapointAttributes = append(datapointAttributes, dpattr)
+// flushFullRows - sends the tables holding a full chunk, max_records rows or
+// max_request_bytes of estimated size, while a push is still being converted, so a push
+// keeps about a chunk of rows per table in memory whatever its size.
+// It is called between records. With parent-first ordering every table is flushed, so the
+// rows of a record are written with their parent row.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tables
+//	@return error
+func (kiwriter *KiWriter) flushFullRows(ctx context.Context, tables []rowBuffer) error {
+	if kiwriter.parentFirst() {
+		for _, rows := range tables {
+			if kiwriter.fullChunk(rows) {
+				err := kiwriter.writeRows(ctx, tables)
+				resetRows(tables)
+				return err
+			}
 		}
+		return nil
+	}
 
-		for _, ge := range gaugerecord.exemplars {
-			exemplars = append(exemplars, ge)
+	var errs error
+	for _, rows := range tables {
+		if !kiwriter.fullChunk(rows) {
+			continue
 		}
 
-		for _, geattr := range gaugerecord.exemplarAttribute {
-			exemplarAttributes = append(exemplarAttributes, geattr)
+		// the last chunk is kept to be filled by the next records unless it is full itself
+		chunks := kiwriter.rowChunks(rows)
+		last := chunks[len(chunks)-1]
+		if last.to-last.from < kiwriter.maxChunkRecords() && last.bytes < kiwriter.cfg.Chunking.MaxRequestBytes {
+			chunks = chunks[:len(chunks)-1]
 		}
-
+		errs = multierr.Append(errs, kiwriter.insertRowChunks(ctx, rows, chunks))
+		rows.discard(chunks[len(chunks)-1].to)
 	}
-
-	tableDataMap := orderedmap.New()
-
-	tableDataMap.Set(GaugeTable, gauges)
-	tableDataMap.Set(GaugeDatapointTable, datapoints)
-	tableDataMap.Set(GaugeDatapointAttributeTable, datapointAttributes)
-	tableDataMap.Set(GaugeResourceAttributeTable, resourceAttributes)
-	tableDataMap.Set(GaugeScopeAttributeTable, scopeAttributes)
-	tableDataMap.Set(GaugeDatapointExemplarTable, exemplars)
-	tableDataMap.Set(GaugeDatapointExemplarAttributeTable, exemplarAttributes)
-
-	errs = append(errs, kiwriter.writeMetric(pmetric.MetricTypeGauge.String(), tableDataMap))
-
-	return multierr.Combine(errs...)
+	return errs
 }
 
-func (kiwriter *KiWriter) persistSumRecord(sumRecords []kineticaSumRecord) error {
-	kiwriter.logger.Debug("In persistSumRecord ...")
-
-	var errs []error
-
-	var sums []any
-	var resourceAttributes []any
-	var scopeAttributes []any
-	var datapoints []any
-	var datapointAttributes []any
-	var exemplars []any
-	var exemplarAttributes []any
-
-	for _, sumrecord := range sumRecords {
-
-		sums = append(sums, *sumrecord.sum)
-
-		for _, sr := range sumrecord.sumResourceAttribute {
-			resourceAttributes = append(resourceAttributes, sr)
-		}
-
-		for _, sa := range sumrecord.sumScopeAttribute {
-			scopeAttributes = append(scopeAttributes, sa)
-		}
-
-		for _, dp := range sumrecord.datapoint {
-			datapoints = append(datapoints, dp)
-		}
-
-		for _, dpattr := range sumrecord.datapointAttribute {
-			datapointAttributes = append(datapointAttributes, dpattr)
-		}
-
-		for _, se := range sumrecord.exemplars {
-			exemplars = append(exemplars, se)
-		}
-
-		for _, seattr := range sumrecord.exemplarAttribute {
-			exemplarAttributes = append(exemplarAttributes, seattr)
-		}
-
+// maxChunkRecords - the maximum number of rows of an insert request. A chunk of the sql
+// insert mode is a single statement, so a failed chunk has committed none of its rows.
+//
+//	@receiver kiwriter
+//	@return int
+func (kiwriter *KiWriter) maxChunkRecords() int {
+	if kiwriter.cfg.InsertMode == InsertModeSQL && kiwriter.cfg.SQLRowsPerStatement < kiwriter.cfg.Chunking.MaxRecords {
+		return kiwriter.cfg.SQLRowsPerStatement
 	}
-
-	tableDataMap := orderedmap.New()
-
-	tableDataMap.Set(SumTable, sums)
-	tableDataMap.Set(SumDatapointTable, datapoints)
-	tableDataMap.Set(SumDatapointAttributeTable, datapointAttributes)
-	tableDataMap.Set(SumResourceAttributeTable, resourceAttributes)
-	tableDataMap.Set(SumScopeAttributeTable, scopeAttributes)
-	tableDataMap.Set(SumDatapointExemplarTable, exemplars)
-	tableDataMap.Set(SumDataPointExemplarAttributeTable, exemplarAttributes)
-
-	errs = append(errs, kiwriter.writeMetric(pmetric.MetricTypeSum.String(), tableDataMap))
-
-	return multierr.Combine(errs...)
+	return kiwriter.cfg.Chunking.MaxRecords
 }
 
-func (kiwriter *KiWriter) persistHistogramRecord(histogramRecords []kineticaHistogramRecord) error {
-	kiwriter.logger.Debug("In persistHistogramRecord ...")
-
-	var errs []error
-
-	var histograms []any
-	var resourceAttributes []any
-	var scopeAttributes []any
-	var datapoints []any
-	var datapointAttributes []any
-	var bucketCounts []any
-	var explicitBounds []any
-	var exemplars []any
-	var exemplarAttributes []any
-
-	for _, histogramrecord := range histogramRecords {
-
-		histograms = append(histograms, *histogramrecord.histogram)
-
-		for _, ra := range histogramrecord.histogramResourceAttribute {
-			resourceAttributes = append(resourceAttributes, ra)
-		}
-
-		for _, sa := range histogramrecord.histogramScopeAttribute {
-			scopeAttributes = append(scopeAttributes, sa)
-		}
-
-		for _, dp := range histogramrecord.histogramDatapoint {
-			datapoints = append(datapoints, dp)
-		}
-
-		for _, dpattr := range histogramrecord.histogramDatapointAtribute {
-			datapointAttributes = append(datapointAttributes, dpattr)
-		}
-
-		for _, bc := range histogramrecord.histogramBucketCount {
-			bucketCounts = append(bucketCounts, bc)
-		}
-
-		for _, eb := range histogramrecord.histogramExplicitBound {
-			explicitBounds = append(explicitBounds, eb)
-		}
+// fullChunk - whether the rows of a table fill an insert request
+//
+//	@receiver kiwriter
+//	@param rows
+//	@return bool
+func (kiwriter *KiWriter) fullChunk(rows rowBuffer) bool {
+	return rows.count() >= kiwriter.maxChunkRecords() || rows.bytes() >= kiwriter.cfg.Chunking.MaxRequestBytes
+}
 
-		for _, ex := range histogramrecord.exemplars {
-			exemplars = append(exemplars, ex)
-		}
+// rowChunks - the chunks the rows of a table are inserted in
+//
+//	@receiver kiwriter
+//	@param rows
+//	@return []chunkRange
+func (kiwriter *KiWriter) rowChunks(rows rowBuffer) []chunkRange {
+	return chunkRanges(rows.count(), rows.size, kiwriter.maxChunkRecords(), kiwriter.cfg.Chunking.MaxRequestBytes)
+}
 
-		for _, exattr := range histogramrecord.exemplarAttribute {
-			exemplarAttributes = append(exemplarAttributes, exattr)
-		}
+// insertRows - Write each chunk of the rows of a table in a separate goroutine
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param rows
+//	@return error
+func (kiwriter *KiWriter) insertRows(ctx context.Context, rows rowBuffer) error {
+	if rows.count() == 0 {
+		return nil
 	}
-
-	tableDataMap := orderedmap.New()
-
-	tableDataMap.Set(HistogramTable, histograms)
-	tableDataMap.Set(HistogramDatapointTable, datapoints)
-	tableDataMap.Set(HistogramDatapointAttributeTable, datapointAttributes)
-	tableDataMap.Set(HistogramBucketCountsTable, bucketCounts)
-	tableDataMap.Set(HistogramExplicitBoundsTable, explicitBounds)
-	tableDataMap.Set(HistogramResourceAttributeTable, resourceAttributes)
-	tableDataMap.Set(HistogramScopeAttributeTable, scopeAttributes)
-	tableDataMap.Set(HistogramDatapointExemplarTable, exemplars)
-	tableDataMap.Set(HistogramDataPointExemplarAttributeTable, exemplarAttributes)
-
-	errs = append(errs, kiwriter.writeMetric(pmetric.MetricTypeHistogram.String(), tableDataMap))
-
-	return multierr.Combine(errs...)
+	return kiwriter.insertRowChunks(ctx, rows, kiwriter.rowChunks(rows))
 }
 
-func (kiwriter *KiWriter) persistExponentialHistogramRecord(exponentialHistogramRecords []kineticaExponentialHistogramRecord) error {
-	kiwriter.logger.Debug("In persistExponentialHistogramRecord ...")
-
-	var errs []error
-
-	var histograms []any
-	var resourceAttributes []any
-	var scopeAttributes []any
-	var datapoints []any
-	var datapointAttributes []any
-	var positiveBucketCounts []any
-	var negativeBucketCounts []any
-	var exemplars []any
-	var exemplarAttributes []any
-
-	for _, histogramrecord := range exponentialHistogramRecords {
-
-		histograms = append(histograms, *histogramrecord.histogram)
-
-		for _, ra := range histogramrecord.histogramResourceAttribute {
-			resourceAttributes = append(resourceAttributes, ra)
-		}
-
-		for _, sa := range histogramrecord.histogramScopeAttribute {
-			scopeAttributes = append(scopeAttributes, sa)
-		}
-
-		for _, dp := range histogramrecord.histogramDatapoint {
-			datapoints = append(datapoints, dp)
-		}
-
-		for _, dpattr := range histogramrecord.histogramDatapointAttribute {
-			datapointAttributes = append(datapointAttributes, dpattr)
-		}
-
-		for _, posbc := range histogramrecord.histogramBucketPositiveCount {
-			positiveBucketCounts = append(positiveBucketCounts, posbc)
-		}
-
-		for _, negbc := range histogramrecord.histogramBucketNegativeCount {
-			negativeBucketCounts = append(negativeBucketCounts, negbc)
-		}
-
-		for _, ex := range histogramrecord.exemplars {
-			exemplars = append(exemplars, ex)
-		}
+// insertRowChunks - Write the given chunks of the rows of a table, each in a separate
+// goroutine. The rows are only boxed as records when a chunk fails, to be spilled or
+// dead-lettered.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param rows
+//	@param chunks
+//	@return error
+func (kiwriter *KiWriter) insertRowChunks(ctx context.Context, rows rowBuffer, chunks []chunkRange) error {
+	tableName := rows.tableName()
+	kiwriter.logger.Debug("Writing to - ", zap.String("Table", kiwriter.finalTableName(tableName)), zap.Int("Record count", chunks[len(chunks)-1].to-chunks[0].from))
 
-		for _, exattr := range histogramrecord.exemplarAttribute {
-			exemplarAttributes = append(exemplarAttributes, exattr)
-		}
+	errs := make([]error, len(chunks))
+	wg := &sync.WaitGroup{}
+	for i, chunk := range chunks {
+		kiwriter.telemetry.recordChunk(ctx, tableName, chunk.to-chunk.from, chunk.bytes)
+		wg.Add(1)
+		go func(err *error, from int, to int) {
+			defer wg.Done()
+			insert := func(from int, to int) error {
+				return kiwriter.insertRowChunk(ctx, rows, from, to)
+			}
+			for _, failed := range kiwriter.bisectFailedRows(tableName, from, to, insert(from, to), insert) {
+				*err = multierr.Append(*err, kiwriter.failedRowsOutcome(ctx, tableName, failed, rows.records(failed.from, failed.to)).err)
+			}
+		}(&errs[i], chunk.from, chunk.to)
 	}
-
-	tableDataMap := orderedmap.New()
-
-	tableDataMap.Set(ExpHistogramTable, histograms)
-	tableDataMap.Set(ExpHistogramDatapointTable, datapoints)
-	tableDataMap.Set(ExpHistogramDatapointAttributeTable, datapointAttributes)
-	tableDataMap.Set(ExpHistogramPositiveBucketCountsTable, positiveBucketCounts)
-	tableDataMap.Set(ExpHistogramNegativeBucketCountsTable, negativeBucketCounts)
-	tableDataMap.Set(ExpHistogramResourceAttributeTable, resourceAttributes)
-	tableDataMap.Set(ExpHistogramScopeAttributeTable, scopeAttributes)
-	tableDataMap.Set(ExpHistogramDatapointExemplarTable, exemplars)
-	tableDataMap.Set(ExpHistogramDataPointExemplarAttributeTable, exemplarAttributes)
-
-	errs = append(errs, kiwriter.writeMetric(pmetric.MetricTypeExponentialHistogram.String(), tableDataMap))
-
+	wg.Wait()
 	return multierr.Combine(errs...)
 }
 
-func (kiwriter *KiWriter) persistSummaryRecord(summaryRecords []kineticaSummaryRecord) error {
-	kiwriter.logger.Debug("In persistSummaryRecord ...")
-
-	var errs []error
-
-	var summaries []any
-	var resourceAttributes []any
-	var scopeAttributes []any
-	var datapoints []any
-	var datapointAttributes []any
-	var datapointQuantiles []any
-
-	for _, summaryrecord := range summaryRecords {
-
-		summaries = append(summaries, *summaryrecord.summary)
-
-		for _, ra := range summaryrecord.summaryResourceAttribute {
-			resourceAttributes = append(resourceAttributes, ra)
-		}
-
-		for _, sa := range summaryrecord.summaryScopeAttribute {
-			scopeAttributes = append(scopeAttributes, sa)
-		}
-
-		for _, dp := range summaryrecord.summaryDatapoint {
-			datapoints = append(datapoints, dp)
-		}
-
-		for _, dpattr := range summaryrecord.summaryDatapointAttribute {
-			datapointAttributes = append(datapointAttributes, dpattr)
-		}
-
-		for _, dpq := range summaryrecord.summaryDatapointQuantileValues {
-			datapointQuantiles = append(datapointQuantiles, dpq)
-		}
+// insertRowChunk - inserts the rows [from, to) of a table, recording the insert telemetry.
+// The rows are Avro encoded straight into the request unless another insert path is
+// configured.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param rows
+//	@param from
+//	@param to
+//	@return error
+func (kiwriter *KiWriter) insertRowChunk(ctx context.Context, rows rowBuffer, from int, to int) error {
+	if kiwriter.bulk != nil || kiwriter.cfg.InsertMode == InsertModeSQL || kiwriter.cfg.Encoding == EncodingJSON {
+		return kiwriter.insertChunk(ctx, rows.tableName(), rows.records(from, to))
 	}
 
-	tableDataMap := orderedmap.New()
-
-	tableDataMap.Set(SummaryTable, summaries)
-	tableDataMap.Set(SummaryDatapointTable, datapoints)
-	tableDataMap.Set(SummaryDatapointAttributeTable, datapointAttributes)
-	tableDataMap.Set(SummaryDatapointQuantileValueTable, datapointQuantiles)
-	tableDataMap.Set(SummaryResourceAttributeTable, resourceAttributes)
-	tableDataMap.Set(SummaryScopeAttributeTable, scopeAttributes)
-
-	errs = append(errs, kiwriter.writeMetric(pmetric.MetricTypeSummary.String(), tableDataMap))
-
-	return multierr.Combine(errs...)
-
+	done := kiwriter.telemetry.startInsert(ctx, rows.tableName(), to-from)
+	size, err := kiwriter.insertRecordsAvro(ctx, rows, from, to)
+	done(size, err)
+	return err
 }
 
 // doChunkedInsert - Write each chunk in a separate goroutine
@@ -1822,37 +1715,168 @@ func (kiwriter *KiWriter) persistSummaryRecord(summaryRecords []kineticaSummaryR
 // }
 
 func (kiwriter *KiWriter) doChunkedInsert(ctx context.Context, tableName string, records []any) error {
-
-	// Build the final table name with the schema prepended
-	var finalTable string
-	if len(kiwriter.cfg.Schema) != 0 {
-		finalTable = fmt.Sprintf("%s.%s", kiwriter.cfg.Schema, tableName)
-	} else {
-		finalTable = tableName
+	var errs error
+	for _, result := range kiwriter.insertChunks(ctx, tableName, records) {
+		errs = multierr.Append(errs, result.err)
 	}
+	return errs
+}
 
-	kiwriter.logger.Debug("Writing to - ", zap.String("Table", finalTable), zap.Int("Record count", len(records)))
+// chunkResult - the outcome of inserting one chunk of records
+type chunkResult struct {
+	records []any
+	// the records were not inserted, they may have been stored as dead letters
+	failed bool
+	// the records were written to the spill directory to be replayed later
+	spilled bool
+	err     error
+}
+
+// insertChunks - Write each chunk in a separate goroutine, spilling the chunks that fail
+// with a retryable error and dead-lettering the rows Kinetica rejects
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param records
+//	@return []chunkResult
+func (kiwriter *KiWriter) insertChunks(ctx context.Context, tableName string, records []any) []chunkResult {
+	if len(records) == 0 {
+		return nil
+	}
 
-	recordChunks := ChunkBySize(records, ChunkSize)
+	kiwriter.logger.Debug("Writing to - ", zap.String("Table", kiwriter.finalTableName(tableName)), zap.Int("Record count", len(records)))
 
-	errsChan := make(chan error, len(recordChunks))
+	chunks := chunkRanges(len(records), func(i int) int {
+		return estimateEncodedSize(records[i])
+	}, kiwriter.maxChunkRecords(), kiwriter.cfg.Chunking.MaxRequestBytes)
+	chunkResults := make([][]chunkResult, len(chunks))
 
 	wg := &sync.WaitGroup{}
 
-	for _, recordChunk := range recordChunks {
+	for i, chunk := range chunks {
+		recordChunk := records[chunk.from:chunk.to:chunk.to]
+		kiwriter.telemetry.recordChunk(ctx, tableName, len(recordChunk), chunk.bytes)
 		wg.Add(1)
-		go func(data []any, wg *sync.WaitGroup) {
-			_, err := kiwriter.Db.InsertRecordsRaw(context.TODO(), finalTable, data)
-			errsChan <- err
-
-			wg.Done()
-		}(recordChunk, wg)
+		go func(results *[]chunkResult, data []any, wg *sync.WaitGroup) {
+			defer wg.Done()
+			insert := func(from int, to int) error {
+				return kiwriter.insertChunk(ctx, tableName, data[from:to:to])
+			}
+			failed := kiwriter.bisectFailedRows(tableName, 0, len(data), insert(0, len(data)), insert)
+			if len(failed) == 0 {
+				*results = []chunkResult{{records: data}}
+				return
+			}
+			// the rows of a bisected chunk that are not part of the results were inserted
+			for _, rows := range failed {
+				*results = append(*results, kiwriter.failedRowsOutcome(ctx, tableName, rows, data[rows.from:rows.to:rows.to]))
+			}
+		}(&chunkResults[i], recordChunk, wg)
 	}
 	wg.Wait()
-	close(errsChan)
-	var errs error
-	for err := range errsChan {
-		errs = multierr.Append(errs, err)
+
+	results := make([]chunkResult, 0, len(chunks))
+	for _, result := range chunkResults {
+		results = append(results, result...)
 	}
-	return errs
+	return results
+}
+
+// chunkOutcome - the outcome of a chunk insert, a chunk failing with a retryable error is
+// spilled when a spill directory is configured and a chunk Kinetica rejects because of
+// its records is dead-lettered. Any other error fails the push so it is retried.
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param data
+//	@param err
+//	@return chunkResult
+func (kiwriter *KiWriter) chunkOutcome(ctx context.Context, tableName string, data []any, err error) chunkResult {
+	result := chunkResult{records: data}
+	if err != nil && kiwriter.spill != nil && isRetryableInsertError(err) {
+		if spillErr := kiwriter.spill.write(kiwriter.cfg.Schema, kiwriter.tablePrefix, tableName, data); spillErr != nil {
+			result.failed = true
+			err = multierr.Append(err, spillErr)
+		} else {
+			kiwriter.logger.Warn("Kinetica unreachable, spilled chunk to disk", zap.String("Table", tableName), zap.Int("Record count", len(data)), zap.Error(err))
+			result.spilled = true
+			err = nil
+		}
+	} else if err != nil {
+		result.failed = true
+		if isRecordInsertError(err) {
+			err = kiwriter.deadLetterChunk(ctx, tableName, data, err)
+		}
+	}
+	result.err = err
+	return result
+}
+
+// insertChunk - inserts one chunk of records into a table, recording the insert telemetry
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param tableName
+//	@param data
+//	@return error
+func (kiwriter *KiWriter) insertChunk(ctx context.Context, tableName string, data []any) error {
+	done := kiwriter.telemetry.startInsert(ctx, tableName, len(data))
+	switch {
+	case kiwriter.bulk != nil && !kiwriter.cfg.isRollupTable(tableName):
+		// a bulk load cannot replace the rows of a rollup bucket
+		size, err := kiwriter.bulk.add(ctx, kiwriter, tableName, data)
+		done(size, err)
+		return err
+	case kiwriter.cfg.InsertMode == InsertModeSQL:
+		size, err := kiwriter.insertChunkSQL(ctx, tableName, data)
+		done(size, err)
+		return err
+	case kiwriter.cfg.Encoding == EncodingJSON:
+		size, err := kiwriter.insertChunkJSON(ctx, tableName, data)
+		done(size, err)
+		return err
+	}
+	size, err := kiwriter.insertRecordsAvro(ctx, &tableRows[any]{table: tableName, rows: data, record: boxedRecord}, 0, len(data))
+	done(size, err)
+	return err
+}
+
+// finalTableName - the physical table name with the configured schema prepended
+//
+//	@receiver kiwriter
+//	@param tableName
+//	@return string
+func (kiwriter *KiWriter) finalTableName(tableName string) string {
+	if len(kiwriter.cfg.Schema) != 0 {
+		return fmt.Sprintf("%s.%s", kiwriter.cfg.Schema, kiwriter.tableName(tableName))
+	}
+	return kiwriter.tableName(tableName)
+}
+
+// recordSchema - the Avro type schema of a table, looked up once per table
+//
+//	@receiver kiwriter
+//	@param ctx
+//	@param finalTable
+//	@return avro.Schema
+//	@return error
+func (kiwriter *KiWriter) recordSchema(ctx context.Context, finalTable string) (avro.Schema, error) {
+	if cached, ok := kiwriter.recordSchemas.Load(finalTable); ok {
+		return cached.(avro.Schema), nil
+	}
+	showTableResult, err := kiwriter.Db.ShowTableRaw(ctx, finalTable)
+	if err != nil {
+		return nil, err
+	}
+	if len(showTableResult.TypeSchemas) == 0 {
+		return nil, fmt.Errorf("no type schema for table %s", finalTable)
+	}
+	recordSchema, err := avro.Parse(showTableResult.TypeSchemas[0])
+	if err != nil {
+		return nil, err
+	}
+	kiwriter.recordSchemas.Store(finalTable, recordSchema)
+	return recordSchema, nil
 }